@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -41,7 +42,7 @@ const (
 
 // LokiStackSizeType declares the type for loki cluster scale outs.
 //
-// +kubebuilder:validation:Enum="1x.extra-small";"1x.small";"1x.medium"
+// +kubebuilder:validation:Enum="1x.extra-small";"1x.small";"1x.medium";"1x.large";"2x.medium"
 type LokiStackSizeType string
 
 const (
@@ -68,6 +69,21 @@ const (
 	//
 	// FIXME: Add clear description of ingestion/query performance expectations.
 	SizeOneXMedium LokiStackSizeType = "1x.medium"
+
+	// SizeOneXLarge defines the size of a single Loki deployment
+	// with large resources/limits requirements and HA support for all
+	// Loki components. This size is dedicated for setup **with** the
+	// requirement for an ingestion volume exceeding the 1x.medium size.
+	//
+	// FIXME: Add clear description of ingestion/query performance expectations.
+	SizeOneXLarge LokiStackSizeType = "1x.large"
+
+	// SizeTwoXMedium defines the size of a single Loki deployment
+	// with the resources/limits requirements of the 1x.medium size and
+	// twice its replicas for all Loki components but the compactor.
+	//
+	// FIXME: Add clear description of ingestion/query performance expectations.
+	SizeTwoXMedium LokiStackSizeType = "2x.medium"
 )
 
 // SubjectKind is a kind of LokiStack Gateway RBAC subject.
@@ -267,6 +283,86 @@ type LokiComponentSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the component pods.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints defines how the component pods are spread
+	// across the cluster topology. Ingesters, queriers, distributors and the
+	// gateway are spread across nodes and zones by default.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Resources defines the compute resource requests and limits of the
+	// component. Each request and limit overrides the default of the size.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:resourceRequirements",displayName="Resource Requirements"
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// StorageSize defines the size of the persistent volume claim of the
+	// component. It overrides the default of the size and applies only to
+	// components with persistent storage, i.e. compactor, ingester, index
+	// gateway and ruler.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Size"
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+
+	// Autoscaling defines the horizontal pod autoscaling of the component.
+	// It applies only to the distributor, querier, query frontend and gateway
+	// components. The replicas of an autoscaled component are owned by the
+	// HorizontalPodAutoscaler.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Autoscaling"
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+}
+
+// AutoscalingSpec defines the horizontal pod autoscaling of a component.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit of replicas the autoscaler can scale down to.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Min Replicas"
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas the autoscaler can scale up to.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Max Replicas"
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization
+	// relative to the requested CPU. Defaults to 80 if no target is set.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Target CPU Utilization Percentage"
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization
+	// relative to the requested memory.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Target Memory Utilization Percentage"
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
 }
 
 // LokiTemplateSpec defines the template of all requirements to configure
@@ -321,6 +417,13 @@ type LokiTemplateSpec struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Index Gateway pods"
 	IndexGateway *LokiComponentSpec `json:"indexGateway,omitempty"`
+
+	// Ruler defines the ruler component spec.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ruler pods"
+	Ruler *LokiComponentSpec `json:"ruler,omitempty"`
 }
 
 // ObjectStorageSecretSpec is a secret reference containing name only, no namespace.
@@ -333,15 +436,266 @@ type ObjectStorageSecretSpec struct {
 	Name string `json:"name"`
 }
 
+// ObjectStorageSecretType defines the type of storage which can be used with the Loki cluster.
+//
+// +kubebuilder:validation:Enum=azure;gcs;s3;swift;filesystem
+type ObjectStorageSecretType string
+
+const (
+	// ObjectStorageSecretAzure when using Azure for Loki storage
+	ObjectStorageSecretAzure ObjectStorageSecretType = "azure"
+
+	// ObjectStorageSecretGCS when using GCS for Loki storage
+	ObjectStorageSecretGCS ObjectStorageSecretType = "gcs"
+
+	// ObjectStorageSecretS3 when using S3 for Loki storage
+	ObjectStorageSecretS3 ObjectStorageSecretType = "s3"
+
+	// ObjectStorageSecretSwift when using Swift for Loki storage
+	ObjectStorageSecretSwift ObjectStorageSecretType = "swift"
+
+	// ObjectStorageSecretFilesystem when using a shared persistent volume
+	// instead of an object storage for Loki storage, e.g. for development
+	ObjectStorageSecretFilesystem ObjectStorageSecretType = "filesystem"
+)
+
 // ObjectStorageSpec defines the requirements to access the object
 // storage bucket to persist logs by the ingester component.
 type ObjectStorageSpec struct {
+	// Type of object storage that should be used. The contents
+	// of the secret are expected to match the chosen type.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=s3
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:azure","urn:alm:descriptor:com.tectonic.ui:select:gcs","urn:alm:descriptor:com.tectonic.ui:select:s3","urn:alm:descriptor:com.tectonic.ui:select:swift","urn:alm:descriptor:com.tectonic.ui:select:filesystem"},displayName="Object Storage Type"
+	Type ObjectStorageSecretType `json:"type,omitempty"`
+
 	// Secret for object storage authentication.
 	// Name of a secret in the same namespace as the cluster logging operator.
+	// It is required for all types but filesystem.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Secret ObjectStorageSecretSpec `json:"secret,omitempty"`
+
+	// Prefix for the keys of the objects stored by the LokiStack in the
+	// buckets of the secret. Several LokiStacks can share the same buckets
+	// with different prefixes. Changing the prefix makes the logs stored
+	// with the previous one unavailable.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength:=128
+	// +kubebuilder:validation:Pattern:="^[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)*$"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Object Storage Prefix"
+	Prefix string `json:"prefix,omitempty"`
+
+	// TLS configuration for verifying the object storage endpoint.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TLS Config"
+	TLS *ObjectStorageTLSSpec `json:"tls,omitempty"`
+
+	// SSE defines the server-side encryption of the objects
+	// stored by the LokiStack. Applies only to the s3 type.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Server-Side Encryption"
+	SSE *ObjectStorageSSESpec `json:"sse,omitempty"`
+
+	// AddressingStyle of the buckets, i.e. the bucket name in the request
+	// path or as part of the endpoint host name. Applies only to the s3 type.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=path
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:path","urn:alm:descriptor:com.tectonic.ui:select:virtualHosted"},displayName="Addressing Style"
+	AddressingStyle ObjectStorageAddressingStyle `json:"addressingStyle,omitempty"`
+
+	// Insecure connects to the object storage endpoint via HTTP
+	// instead of HTTPS. Applies only to the s3 type.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch",displayName="Insecure"
+	Insecure bool `json:"insecure,omitempty"`
+
+	// Filesystem defines the persistent volume claim shared by all
+	// components for the filesystem type.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Filesystem Storage"
+	Filesystem *ObjectStorageFilesystemSpec `json:"filesystem,omitempty"`
+
+	// Schemas for reading and writing logs ordered by effective date.
+	// Schemas in effect must not be changed to keep the data written
+	// with them readable. Schema upgrades are added with a future
+	// effective date instead.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems:=1
+	// +kubebuilder:default:={{version:v11,effectiveDate:"2020-10-01"}}
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Object Storage Schemas"
+	Schemas []ObjectStorageSchema `json:"schemas,omitempty"`
+}
+
+// ObjectStorageTLSSpec defines the CA bundle to verify
+// the object storage endpoint certificate with.
+type ObjectStorageTLSSpec struct {
+	// CA is the name of a ConfigMap in the same namespace as the
+	// LokiStack containing the CA bundle in PEM format.
 	//
 	// +required
 	// +kubebuilder:validation:Required
-	Secret ObjectStorageSecretSpec `json:"secret"`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:ConfigMap",displayName="CA ConfigMap Name"
+	CA string `json:"caName"`
+
+	// CAKey is the key of the CA bundle in the ConfigMap.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=service-ca.crt
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="CA ConfigMap Key"
+	CAKey string `json:"caKey,omitempty"`
+}
+
+// ObjectStorageSSEType defines the type of server-side encryption.
+//
+// +kubebuilder:validation:Enum=SSE-KMS;SSE-S3
+type ObjectStorageSSEType string
+
+const (
+	// ObjectStorageSSEKMS encrypts the objects with a key managed by the AWS KMS.
+	ObjectStorageSSEKMS ObjectStorageSSEType = "SSE-KMS"
+
+	// ObjectStorageSSES3 encrypts the objects with keys managed by S3.
+	ObjectStorageSSES3 ObjectStorageSSEType = "SSE-S3"
+)
+
+// ObjectStorageSSESpec defines the server-side encryption of the stored objects.
+type ObjectStorageSSESpec struct {
+	// Type of the server-side encryption.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:SSE-KMS","urn:alm:descriptor:com.tectonic.ui:select:SSE-S3"},displayName="Type"
+	Type ObjectStorageSSEType `json:"type"`
+
+	// KMSKeyID is the ID of the KMS key encrypting the objects.
+	// It is required for SSE-KMS and must be empty for SSE-S3.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="KMS Key ID"
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// ObjectStorageAddressingStyle defines how buckets are addressed.
+//
+// +kubebuilder:validation:Enum=path;virtualHosted
+type ObjectStorageAddressingStyle string
+
+const (
+	// ObjectStorageAddressingPath addresses the bucket in the request path.
+	ObjectStorageAddressingPath ObjectStorageAddressingStyle = "path"
+
+	// ObjectStorageAddressingVirtualHosted addresses the bucket as
+	// part of the endpoint host name.
+	ObjectStorageAddressingVirtualHosted ObjectStorageAddressingStyle = "virtualHosted"
+)
+
+// ObjectStorageFilesystemAccessMode defines the access mode of the shared
+// persistent volume claim.
+//
+// +kubebuilder:validation:Enum=ReadWriteMany;ReadWriteOnce
+type ObjectStorageFilesystemAccessMode string
+
+const (
+	// ObjectStorageFilesystemReadWriteMany mounts the shared persistent volume
+	// claim on any node.
+	ObjectStorageFilesystemReadWriteMany ObjectStorageFilesystemAccessMode = "ReadWriteMany"
+
+	// ObjectStorageFilesystemReadWriteOnce mounts the shared persistent volume
+	// claim on a single node, i.e. for single-node clusters.
+	ObjectStorageFilesystemReadWriteOnce ObjectStorageFilesystemAccessMode = "ReadWriteOnce"
+)
+
+// ObjectStorageFilesystemSpec defines the persistent volume claim storing
+// the chunks and index of all components instead of an object storage.
+type ObjectStorageFilesystemSpec struct {
+	// AccessMode of the shared persistent volume claim. ReadWriteOnce
+	// requires all components to run on the same node and thus applies
+	// only to single-node clusters. The access mode cannot be changed
+	// once the claim is created.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=ReadWriteMany
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:ReadWriteMany","urn:alm:descriptor:com.tectonic.ui:select:ReadWriteOnce"},displayName="Access Mode"
+	AccessMode ObjectStorageFilesystemAccessMode `json:"accessMode,omitempty"`
+
+	// StorageClassName of the shared persistent volume claim. The storage
+	// class must support the access mode. The default storage class is
+	// used if omitted.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:StorageClass",displayName="Storage Class Name"
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// StorageSize defines the size of the shared persistent volume claim.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Size"
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+}
+
+// ObjectStorageSchemaVersion defines the storage schema version which will be
+// used with the Loki cluster.
+//
+// +kubebuilder:validation:Enum=v11;v12;v13
+type ObjectStorageSchemaVersion string
+
+const (
+	// ObjectStorageSchemaV11 when using v11 for the storage schema with the boltdb-shipper index store
+	ObjectStorageSchemaV11 ObjectStorageSchemaVersion = "v11"
+
+	// ObjectStorageSchemaV12 when using v12 for the storage schema with the boltdb-shipper index store
+	ObjectStorageSchemaV12 ObjectStorageSchemaVersion = "v12"
+
+	// ObjectStorageSchemaV13 when using v13 for the storage schema with the tsdb index store
+	ObjectStorageSchemaV13 ObjectStorageSchemaVersion = "v13"
+)
+
+// StorageSchemaEffectiveDate defines the date in UTC from which a storage
+// schema is used, in the format YYYY-MM-DD.
+//
+// +kubebuilder:validation:Pattern:="^([0-9]{4,})([-]([0-9]{2})){2}$"
+type StorageSchemaEffectiveDate string
+
+// ObjectStorageSchema defines the requirements needed to configure a new
+// storage schema.
+type ObjectStorageSchema struct {
+	// Version for writing and reading logs.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:v11","urn:alm:descriptor:com.tectonic.ui:select:v12","urn:alm:descriptor:com.tectonic.ui:select:v13"},displayName="Version"
+	Version ObjectStorageSchemaVersion `json:"version"`
+
+	// EffectiveDate is the date in UTC that the schema will be applied on.
+	// New schemas must have an effective date after the current date in UTC.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Effective Date"
+	EffectiveDate StorageSchemaEffectiveDate `json:"effectiveDate"`
 }
 
 // QueryLimitSpec defines the limits applies at the query path.
@@ -431,6 +785,52 @@ type IngestionLimitSpec struct {
 	MaxLineSize int32 `json:"maxLineSize,omitempty"`
 }
 
+// RetentionStreamSpec defines a log stream with a separate retention period.
+type RetentionStreamSpec struct {
+	// Period defines the log retention period for the selected log stream.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Period"
+	Period PrometheusDuration `json:"period"`
+
+	// Priority defines the priority of this selector compared to other
+	// retention rules matching the same log stream. Highest priority wins.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Priority"
+	Priority int32 `json:"priority,omitempty"`
+
+	// Selector contains the LogQL stream selector used to define the log stream.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Selector"
+	Selector string `json:"selector"`
+}
+
+// RetentionLimitSpec defines the retention applied to log streams.
+type RetentionLimitSpec struct {
+	// Period defines the log retention period. It must be a multiple
+	// of the index period of the object storage schema (24h).
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Period"
+	Period PrometheusDuration `json:"period"`
+
+	// Streams defines the log streams with a retention period
+	// different from the period above.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Streams"
+	Streams []*RetentionStreamSpec `json:"streams,omitempty"`
+}
+
 // LimitsTemplateSpec defines the limits  applied at ingestion or query path.
 type LimitsTemplateSpec struct {
 	// IngestionLimits defines the limits applied on ingested log streams.
@@ -444,6 +844,12 @@ type LimitsTemplateSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	QueryLimits *QueryLimitSpec `json:"queries,omitempty"`
+
+	// Retention defines the retention period applied to log streams.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Retention *RetentionLimitSpec `json:"retention,omitempty"`
 }
 
 // LimitsSpec defines the spec for limits applied at ingestion or query
@@ -465,6 +871,193 @@ type LimitsSpec struct {
 	Tenants map[string]LimitsTemplateSpec `json:"tenants,omitempty"`
 }
 
+// RulesSpec defines the spec for the ruler component.
+type RulesSpec struct {
+	// Enabled defines a flag to enable/disable the ruler component.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:booleanSwitch",displayName="Enable"
+	Enabled bool `json:"enabled"`
+
+	// Selector defines the labels an AlertingRule or RecordingRule must have
+	// to be loaded by the ruler. If unspecified, all rules are selected.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Selector"
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// NamespaceSelector defines the labels a namespace must have for its rules
+	// to be loaded by the ruler. If unspecified, only the namespace of the
+	// LokiStack object is used.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Selector"
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AlertManagerEndpoints defines the list of Alertmanager URLs the ruler
+	// sends firing alerts to.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Alertmanager Endpoints"
+	AlertManagerEndpoints []string `json:"alertmanagerEndpoints,omitempty"`
+}
+
+// PersistentVolumeClaimRetentionPolicyType defines what happens to the
+// persistent volume claims of the Loki components.
+//
+// +kubebuilder:validation:Enum=Retain;Delete
+type PersistentVolumeClaimRetentionPolicyType string
+
+const (
+	// PersistentVolumeClaimRetentionPolicyRetain keeps the persistent volume claims.
+	PersistentVolumeClaimRetentionPolicyRetain PersistentVolumeClaimRetentionPolicyType = "Retain"
+
+	// PersistentVolumeClaimRetentionPolicyDelete deletes the persistent volume claims.
+	PersistentVolumeClaimRetentionPolicyDelete PersistentVolumeClaimRetentionPolicyType = "Delete"
+)
+
+// PersistentVolumeClaimRetentionPolicySpec defines the lifecycle of the
+// persistent volume claims created for the Loki components.
+type PersistentVolumeClaimRetentionPolicySpec struct {
+	// WhenDeleted defines what happens to the persistent volume claims
+	// when the LokiStack is deleted.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=Retain
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Retain","urn:alm:descriptor:com.tectonic.ui:select:Delete"},displayName="When Deleted"
+	WhenDeleted PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+
+	// WhenScaled defines what happens to the persistent volume claims
+	// of the ingesters removed by a scale-down.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=Retain
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:Retain","urn:alm:descriptor:com.tectonic.ui:select:Delete"},displayName="When Scaled"
+	WhenScaled PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
+}
+
+// ZoneSpec defines the spec to place ingesters into a single failure domain.
+type ZoneSpec struct {
+	// TopologyKey is the node label key identifying the zone.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=topology.kubernetes.io/zone
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Topology Key"
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Value is the node label value of the topology key identifying the zone.
+	// It is used as zone name in the ingester ring and as suffix of the
+	// ingester statefulset name.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern:="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	// +kubebuilder:validation:MaxLength:=32
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Value"
+	Value string `json:"value"`
+}
+
+// ReplicationSpec defines the spec for zone-aware log stream replication.
+type ReplicationSpec struct {
+	// Zones defines the failure domains to spread the ingesters across.
+	// Each zone runs its own ingester statefulset and the ingester ring
+	// replicates log streams across zones.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Zones"
+	Zones []ZoneSpec `json:"zones,omitempty"`
+}
+
+// CachingModeType defines the type of caching for chunks, index queries and query results.
+//
+// +kubebuilder:validation:Enum=InMemory;Managed;External
+type CachingModeType string
+
+const (
+	// CachingModeInMemory uses per process in-memory caches.
+	CachingModeInMemory CachingModeType = "InMemory"
+
+	// CachingModeManaged deploys memcached instances managed by the operator.
+	CachingModeManaged CachingModeType = "Managed"
+
+	// CachingModeExternal uses externally provided cache endpoints.
+	CachingModeExternal CachingModeType = "External"
+)
+
+// CacheBackendType defines the type of an external cache.
+//
+// +kubebuilder:validation:Enum=memcached;redis
+type CacheBackendType string
+
+const (
+	// CacheBackendMemcached is a memcached cache.
+	CacheBackendMemcached CacheBackendType = "memcached"
+
+	// CacheBackendRedis is a redis cache.
+	CacheBackendRedis CacheBackendType = "redis"
+)
+
+// ExternalCacheSpec defines the spec to connect to an external cache.
+type ExternalCacheSpec struct {
+	// Type of the external cache.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=memcached
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:memcached","urn:alm:descriptor:com.tectonic.ui:select:redis"},displayName="Type"
+	Type CacheBackendType `json:"type,omitempty"`
+
+	// Endpoint of the external cache. For memcached a comma-separated list of
+	// host:port addresses or DNS service discovery addresses, e.g.
+	// dnssrvnoa+_memcached._tcp.memcached.cache.svc. For redis a single
+	// host:port address.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Endpoint"
+	Endpoint string `json:"endpoint"`
+}
+
+// CachingSpec defines the caches for chunks, index queries and query results.
+type CachingSpec struct {
+	// Mode defines the type of caching. Default is per process in-memory caches.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=InMemory
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:InMemory","urn:alm:descriptor:com.tectonic.ui:select:Managed","urn:alm:descriptor:com.tectonic.ui:select:External"},displayName="Mode"
+	Mode CachingModeType `json:"mode,omitempty"`
+
+	// Chunks defines the external cache for chunks. Applies only to the External mode.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Chunks Cache"
+	Chunks *ExternalCacheSpec `json:"chunks,omitempty"`
+
+	// IndexQueries defines the external cache for index queries. Applies only to the External mode.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Index Queries Cache"
+	IndexQueries *ExternalCacheSpec `json:"indexQueries,omitempty"`
+
+	// Results defines the external cache for query results. Applies only to the External mode.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Results Cache"
+	Results *ExternalCacheSpec `json:"results,omitempty"`
+}
+
 // LokiStackSpec defines the desired state of LokiStack
 type LokiStackSpec struct {
 
@@ -481,9 +1074,18 @@ type LokiStackSpec struct {
 	//
 	// +required
 	// +kubebuilder:validation:Required
-	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:1x.extra-small","urn:alm:descriptor:com.tectonic.ui:select:1x.small","urn:alm:descriptor:com.tectonic.ui:select:1x.medium"},displayName="LokiStack Size"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:1x.extra-small","urn:alm:descriptor:com.tectonic.ui:select:1x.small","urn:alm:descriptor:com.tectonic.ui:select:1x.medium","urn:alm:descriptor:com.tectonic.ui:select:1x.large","urn:alm:descriptor:com.tectonic.ui:select:2x.medium"},displayName="LokiStack Size"
 	Size LokiStackSizeType `json:"size"`
 
+	// SizeProfile references a custom size profile by name defined by the
+	// cluster administrator in the size profiles ConfigMap of the operator.
+	// The profile replaces the default configuration of the size.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Size Profile"
+	SizeProfile string `json:"sizeProfile,omitempty"`
+
 	// Storage defines the spec for the object storage endpoint to store logs.
 	//
 	// +required
@@ -498,6 +1100,15 @@ type LokiStackSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:io.kubernetes:StorageClass",displayName="Storage Class Name"
 	StorageClassName string `json:"storageClassName"`
 
+	// PersistentVolumeClaimRetentionPolicy defines the lifecycle of the
+	// persistent volume claims of the Loki components. Default is to
+	// retain them when the LokiStack is deleted.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Persistent Volume Claim Retention Policy"
+	PersistentVolumeClaimRetentionPolicy *PersistentVolumeClaimRetentionPolicySpec `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
 	// ReplicationFactor defines the policy for log stream replication.
 	//
 	// +required
@@ -506,6 +1117,13 @@ type LokiStackSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Replication Factor"
 	ReplicationFactor int32 `json:"replicationFactor"`
 
+	// Replication defines the zone-aware replication of the ingesters.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Replication"
+	Replication *ReplicationSpec `json:"replication,omitempty"`
+
 	// Limits defines the limits to be applied to log stream processing.
 	//
 	// +optional
@@ -513,6 +1131,20 @@ type LokiStackSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Rate Limiting"
 	Limits *LimitsSpec `json:"limits,omitempty"`
 
+	// Caching defines the caches for chunks, index queries and query results.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Caching"
+	Caching *CachingSpec `json:"caching,omitempty"`
+
+	// Rules defines the spec for the ruler component
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Rules"
+	Rules *RulesSpec `json:"rules,omitempty"`
+
 	// Template defines the resource/limits/tolerations/nodeselectors per component
 	//
 	// +optional
@@ -561,9 +1193,36 @@ const (
 	ReasonMissingObjectStorageSecret LokiStackConditionReason = "MissingObjectStorageSecret"
 	// ReasonInvalidObjectStorageSecret when the format of the secret is invalid.
 	ReasonInvalidObjectStorageSecret LokiStackConditionReason = "InvalidObjectStorageSecret"
+	// ReasonUnreachableObjectStorage when the object storage endpoint cannot be reached.
+	ReasonUnreachableObjectStorage LokiStackConditionReason = "UnreachableObjectStorage"
+	// ReasonObjectStorageAccessDenied when the credentials of the object storage
+	// secret are not permitted to access a bucket.
+	ReasonObjectStorageAccessDenied LokiStackConditionReason = "ObjectStorageAccessDenied"
+	// ReasonMissingObjectStorageBucket when a bucket of the object storage secret does not exist.
+	ReasonMissingObjectStorageBucket LokiStackConditionReason = "MissingObjectStorageBucket"
+	// ReasonConflictingObjectStorage when another LokiStack stores to
+	// the same bucket with the same prefix.
+	ReasonConflictingObjectStorage LokiStackConditionReason = "ConflictingObjectStorage"
+	// ReasonMissingObjectStorageCAConfigMap when the referenced CA bundle ConfigMap
+	// or its key is missing.
+	ReasonMissingObjectStorageCAConfigMap LokiStackConditionReason = "MissingObjectStorageCAConfigMap"
 	// ReasonInvalidReplicationConfiguration when the configurated replication factor is not valid
 	// with the select cluster size.
 	ReasonInvalidReplicationConfiguration LokiStackConditionReason = "InvalidReplicationConfiguration"
+	// ReasonMissingSizeProfile when the referenced size profile is not defined
+	// in the size profiles ConfigMap of the operator.
+	ReasonMissingSizeProfile LokiStackConditionReason = "MissingSizeProfile"
+	// ReasonInvalidSizeProfile when the referenced size profile is invalid.
+	ReasonInvalidSizeProfile LokiStackConditionReason = "InvalidSizeProfile"
+	// ReasonInvalidResourceRequirements when the resource requests of a component
+	// exceed its limits after applying the resource overrides.
+	ReasonInvalidResourceRequirements LokiStackConditionReason = "InvalidResourceRequirements"
+	// ReasonUnsupportedStorageChange when the volume claim templates of a statefulset
+	// changed in a way that cannot be applied to the existing persistent volume claims.
+	ReasonUnsupportedStorageChange LokiStackConditionReason = "UnsupportedStorageChange"
+	// ReasonScalingDownIngesters when the ingesters removed by a scale-down are
+	// flushed and leave the ring before the ingester statefulset is scaled down.
+	ReasonScalingDownIngesters LokiStackConditionReason = "ScalingDownIngesters"
 	// ReasonMissingGatewayTenantSecret when the required tenant secret
 	// for authentication is missing.
 	ReasonMissingGatewayTenantSecret LokiStackConditionReason = "MissingGatewayTenantSecret"
@@ -573,6 +1232,15 @@ const (
 	ReasonInvalidTenantsConfiguration LokiStackConditionReason = "InvalidTenantsConfiguration"
 	// ReasonMissingGatewayOpenShiftBaseDomain when the reconciler cannot lookup the OpenShift DNS base domain.
 	ReasonMissingGatewayOpenShiftBaseDomain LokiStackConditionReason = "MissingGatewayOpenShiftBaseDomain"
+	// ReasonInvalidRulesConfiguration when one of the selected alerting or recording
+	// rules is invalid.
+	ReasonInvalidRulesConfiguration LokiStackConditionReason = "InvalidRulesConfiguration"
+	// ReasonInvalidRetentionConfiguration when the global or per tenant
+	// retention configuration is invalid.
+	ReasonInvalidRetentionConfiguration LokiStackConditionReason = "InvalidRetentionConfiguration"
+	// ReasonInvalidObjectStorageSchema when the storage schemas are not ordered
+	// or change a schema already in effect.
+	ReasonInvalidObjectStorageSchema LokiStackConditionReason = "InvalidObjectStorageSchema"
 )
 
 // PodStatusMap defines the type for mapping pod status to pod name.
@@ -616,12 +1284,51 @@ type LokiStackComponentStatus struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Query Frontend",order=4
 	QueryFrontend PodStatusMap `json:"queryFrontend,omitempty"`
 
+	// IndexGateway is a map to the per pod status of the index gateway statefulset
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Index Gateway",order=7
+	IndexGateway PodStatusMap `json:"indexGateway,omitempty"`
+
 	// Gateway is a map to the per pod status of the lokistack gateway deployment.
 	//
 	// +optional
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Gateway",order=5
 	Gateway PodStatusMap `json:"gateway,omitempty"`
+
+	// Ruler is a map to the per pod status of the lokistack ruler statefulset.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Ruler",order=6
+	Ruler PodStatusMap `json:"ruler,omitempty"`
+}
+
+// ZoneStatus defines the readiness of the ingesters in a single zone.
+type ZoneStatus struct {
+	// Name of the zone.
+	Name string `json:"name"`
+
+	// Replicas is the number of desired ingester replicas in the zone.
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of ready ingester replicas in the zone.
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Ready is true when all ingester replicas in the zone are ready.
+	Ready bool `json:"ready"`
+}
+
+// LokiStackStorageStatus defines the observed state of
+// the storage configuration.
+type LokiStackStorageStatus struct {
+	// Schemas is the list of storage schemas last applied to the LokiStack.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Schemas []ObjectStorageSchema `json:"schemas,omitempty"`
 }
 
 // LokiStackStatus defines the observed state of LokiStack
@@ -639,6 +1346,29 @@ type LokiStackStatus struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation of the LokiStack
+	// spec observed and reconciled by the operator.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Observed Generation"
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Zones provides the per zone ingester readiness when zone-aware
+	// replication is enabled.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Zones"
+	Zones []ZoneStatus `json:"zones,omitempty"`
+
+	// Storage provides the storage configuration last applied to the LokiStack.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Storage"
+	Storage LokiStackStorageStatus `json:"storage,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -647,7 +1377,7 @@ type LokiStackStatus struct {
 
 // LokiStack is the Schema for the lokistacks API
 //
-// +operator-sdk:csv:customresourcedefinitions:displayName="LokiStack",resources={{Deployment,v1},{StatefulSet,v1},{ConfigMap,v1},{Ingress,v1},{Service,v1},{ServiceAccount,v1},{PersistentVolumeClaims,v1},{Route,v1},{ServiceMonitor,v1}}
+// +operator-sdk:csv:customresourcedefinitions:displayName="LokiStack",resources={{Deployment,v1},{HorizontalPodAutoscaler,v2beta2},{StatefulSet,v1},{PodDisruptionBudget,v1},{ConfigMap,v1},{Ingress,v1},{Service,v1},{ServiceAccount,v1},{PersistentVolumeClaims,v1},{Route,v1},{ServiceMonitor,v1}}
 type LokiStack struct {
 	Spec              LokiStackSpec   `json:"spec,omitempty"`
 	Status            LokiStackStatus `json:"status,omitempty"`
@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"os"
 	"time"
 
 	"github.com/ViaQ/loki-operator/controllers/internal/management/state"
@@ -29,20 +31,31 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 )
 
+// ingesterScaleDownPollInterval is the interval to poll the ingester ring
+// while the ingesters removed by a scale-down are draining.
+const ingesterScaleDownPollInterval = 10 * time.Second
+
 var (
 	createOrUpdateOnlyPred = builder.WithPredicates(predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
@@ -51,7 +64,9 @@ var (
 			// updated on spec changes. On the other hand RevisionVersion
 			// changes also on status changes. We want to omit reconciliation
 			// for status updates for now.
-			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+			// Deletion is handled as well to clean up by the finalizer.
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero()
 		},
 		CreateFunc:  func(e event.CreateEvent) bool { return true },
 		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
@@ -60,9 +75,10 @@ var (
 	updateOrDeleteOnlyPred = builder.WithPredicates(predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			switch e.ObjectOld.(type) {
-			case *appsv1.Deployment:
-			case *appsv1.StatefulSet:
-				return true
+			case *appsv1.Deployment, *appsv1.StatefulSet:
+				// Revert spec changes of the workloads. Status changes are
+				// handled by the LokiStackStatusReconciler.
+				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
 			}
 			return false
 		},
@@ -79,23 +95,31 @@ var (
 // LokiStackReconciler reconciles a LokiStack object
 type LokiStackReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
-	Flags  manifests.FeatureFlags
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Flags    manifests.FeatureFlags
 }
 
 // +kubebuilder:rbac:groups=loki.openshift.io,resources=lokistacks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=loki.openshift.io,resources=lokistacks/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=loki.openshift.io,resources=lokistacks/finalizers,verbs=update
+// +kubebuilder:rbac:groups=loki.openshift.io,resources=alertingrules;recordingrules,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods;nodes;services;endpoints;configmaps;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;clusterroles,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
-// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=dnses,verbs=get;list;watch
-// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -106,6 +130,17 @@ type LokiStackReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *LokiStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	deleted, err := handlers.DeleteLokiStack(ctx, req, r.Client)
+	if err != nil {
+		return ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: time.Second,
+		}, err
+	}
+	if deleted {
+		return ctrl.Result{}, nil
+	}
+
 	ok, err := state.IsManaged(ctx, req, r.Client)
 	if err != nil {
 		return ctrl.Result{
@@ -119,7 +154,28 @@ func (r *LokiStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	err = handlers.CreateOrUpdateLokiStack(ctx, req, r.Client, r.Scheme, r.Flags)
+	err = handlers.CreateOrUpdateLokiStack(ctx, req, r.Client, r.Scheme, r.Recorder, r.Flags)
+	if errors.Is(err, handlers.ErrIngesterScaleDownInProgress) {
+		// Report the draining ingesters and poll until they left the ring.
+		if err = status.Refresh(ctx, r.Client, req); err != nil {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: time.Second,
+			}, err
+		}
+		return ctrl.Result{RequeueAfter: ingesterScaleDownPollInterval}, nil
+	}
+	if errors.Is(err, handlers.ErrObjectStorageUnavailable) {
+		// Report the applied components but keep the Degraded condition
+		// and probe again once the interval passed.
+		if err = status.RefreshComponents(ctx, r.Client, req); err != nil {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: time.Second,
+			}, err
+		}
+		return ctrl.Result{RequeueAfter: handlers.ObjectStorageProbeInterval}, nil
+	}
 	if err != nil {
 		return ctrl.Result{
 			Requeue:      true,
@@ -140,6 +196,10 @@ func (r *LokiStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LokiStackReconciler) SetupWithManager(mgr manager.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &lokiv1beta1.LokiStack{}, secretsIndexField, secretsIndexFunc); err != nil {
+		return err
+	}
+
 	b := ctrl.NewControllerManagedBy(mgr)
 	return r.buildController(k8s.NewCtrlBuilder(b))
 }
@@ -152,8 +212,15 @@ func (r *LokiStackReconciler) buildController(bld k8s.Builder) error {
 		Owns(&corev1.Service{}, updateOrDeleteOnlyPred).
 		Owns(&appsv1.Deployment{}, updateOrDeleteOnlyPred).
 		Owns(&appsv1.StatefulSet{}, updateOrDeleteOnlyPred).
+		Owns(&policyv1.PodDisruptionBudget{}, updateOrDeleteOnlyPred).
+		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}, updateOrDeleteOnlyPred).
 		Owns(&rbacv1.ClusterRole{}, updateOrDeleteOnlyPred).
-		Owns(&rbacv1.ClusterRoleBinding{}, updateOrDeleteOnlyPred)
+		Owns(&rbacv1.ClusterRoleBinding{}, updateOrDeleteOnlyPred).
+		Watches(&source.Kind{Type: &lokiv1beta1.AlertingRule{}}, r.enqueueRulesEnabledLokiStacks()).
+		Watches(&source.Kind{Type: &lokiv1beta1.RecordingRule{}}, r.enqueueRulesEnabledLokiStacks()).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.lokiStacksForSecret)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.lokiStacksForSizeProfiles)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.lokiStacksForStorageCA))
 
 	if r.Flags.EnableGatewayRoute {
 		bld = bld.Owns(&routev1.Route{}, updateOrDeleteOnlyPred)
@@ -163,3 +230,153 @@ func (r *LokiStackReconciler) buildController(bld k8s.Builder) error {
 
 	return bld.Complete(r)
 }
+
+// enqueueRulesEnabledLokiStacks returns an event handler that enqueues
+// all LokiStack custom resources with the ruler component enabled.
+// Rules can be selected across namespaces, thus any rule change might
+// affect any of them.
+func (r *LokiStackReconciler) enqueueRulesEnabledLokiStacks() handler.EventHandler {
+	ctx := context.TODO()
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		var stacks lokiv1beta1.LokiStackList
+		if err := r.Client.List(ctx, &stacks); err != nil {
+			r.Log.Error(err, "failed to list lokistacks for rules change", "name", obj.GetName(), "namespace", obj.GetNamespace())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, stack := range stacks.Items {
+			if stack.Spec.Rules == nil || !stack.Spec.Rules.Enabled {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      stack.Name,
+					Namespace: stack.Namespace,
+				},
+			})
+		}
+
+		return requests
+	})
+}
+
+// lokiStacksForSecret maps a secret to reconcile requests for all
+// LokiStack custom resources in the same namespace referencing it either
+// as object storage secret or as tenant OIDC secret.
+func (r *LokiStackReconciler) lokiStacksForSecret(obj client.Object) []reconcile.Request {
+	var stacks lokiv1beta1.LokiStackList
+	opts := []client.ListOption{
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{secretsIndexField: obj.GetName()},
+	}
+	if err := r.Client.List(context.TODO(), &stacks, opts...); err != nil {
+		r.Log.Error(err, "failed to list lokistacks for secret change", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(stacks.Items))
+	for _, stack := range stacks.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      stack.Name,
+				Namespace: stack.Namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// lokiStacksForSizeProfiles maps the operator's size profiles config map to
+// reconcile requests for all LokiStack custom resources referencing a size
+// profile. Any other config map is ignored.
+func (r *LokiStackReconciler) lokiStacksForSizeProfiles(obj client.Object) []reconcile.Request {
+	if obj.GetName() != manifests.SizeProfilesConfigMapName || obj.GetNamespace() != os.Getenv(manifests.EnvOperatorNamespace) {
+		return nil
+	}
+
+	var stacks lokiv1beta1.LokiStackList
+	if err := r.Client.List(context.TODO(), &stacks); err != nil {
+		r.Log.Error(err, "failed to list lokistacks for size profiles change", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, stack := range stacks.Items {
+		if stack.Spec.SizeProfile == "" {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      stack.Name,
+				Namespace: stack.Namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// lokiStacksForStorageCA maps a config map to reconcile requests for all
+// LokiStack custom resources in the same namespace referencing it as the
+// CA bundle of the object storage.
+func (r *LokiStackReconciler) lokiStacksForStorageCA(obj client.Object) []reconcile.Request {
+	var stacks lokiv1beta1.LokiStackList
+	if err := r.Client.List(context.TODO(), &stacks, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list lokistacks for object storage CA change", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, stack := range stacks.Items {
+		if tls := stack.Spec.Storage.TLS; tls == nil || tls.CA != obj.GetName() {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      stack.Name,
+				Namespace: stack.Namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// secretsIndexField is the field index of LokiStack custom resources
+// by the names of the secrets they reference.
+const secretsIndexField = ".spec.secrets"
+
+// secretsIndexFunc returns the names of all secrets referenced by a LokiStack.
+func secretsIndexFunc(obj client.Object) []string {
+	stack, ok := obj.(*lokiv1beta1.LokiStack)
+	if !ok {
+		return nil
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	add := func(name string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	add(stack.Spec.Storage.Secret.Name)
+
+	if stack.Spec.Tenants != nil {
+		for _, a := range stack.Spec.Tenants.Authentication {
+			if a.OIDC != nil && a.OIDC.Secret != nil {
+				add(a.OIDC.Secret.Name)
+			}
+		}
+	}
+
+	return names
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"flag"
 	"io/ioutil"
 	"os"
@@ -14,14 +15,20 @@ import (
 	"github.com/stretchr/testify/require"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var scheme = runtime.NewScheme()
@@ -57,6 +64,7 @@ func TestLokiStackController_RegistersCustomResourceForCreateOrUpdate(t *testing
 
 	b.ForReturns(b)
 	b.OwnsReturns(b)
+	b.WatchesReturns(b)
 
 	err := c.buildController(b)
 	require.NoError(t, err)
@@ -107,18 +115,28 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 			pred:  updateOrDeleteOnlyPred,
 		},
 		{
-			obj:   &rbacv1.ClusterRole{},
+			obj:   &policyv1.PodDisruptionBudget{},
 			index: 5,
 			pred:  updateOrDeleteOnlyPred,
 		},
 		{
-			obj:   &rbacv1.ClusterRoleBinding{},
+			obj:   &autoscalingv2beta2.HorizontalPodAutoscaler{},
 			index: 6,
 			pred:  updateOrDeleteOnlyPred,
 		},
 		{
-			obj:   &networkingv1.Ingress{},
+			obj:   &rbacv1.ClusterRole{},
 			index: 7,
+			pred:  updateOrDeleteOnlyPred,
+		},
+		{
+			obj:   &rbacv1.ClusterRoleBinding{},
+			index: 8,
+			pred:  updateOrDeleteOnlyPred,
+		},
+		{
+			obj:   &networkingv1.Ingress{},
+			index: 9,
 			flags: manifests.FeatureFlags{
 				EnableGatewayRoute: false,
 			},
@@ -126,7 +144,7 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		},
 		{
 			obj:   &routev1.Route{},
-			index: 7,
+			index: 9,
 			flags: manifests.FeatureFlags{
 				EnableGatewayRoute: true,
 			},
@@ -137,13 +155,14 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		b := &k8sfakes.FakeBuilder{}
 		b.ForReturns(b)
 		b.OwnsReturns(b)
+		b.WatchesReturns(b)
 
 		c := &LokiStackReconciler{Client: k, Scheme: scheme, Flags: tst.flags}
 		err := c.buildController(b)
 		require.NoError(t, err)
 
 		// Require Owns-Calls for all owned resources
-		require.Equal(t, 8, b.OwnsCallCount())
+		require.Equal(t, 10, b.OwnsCallCount())
 
 		// Require Owns-call options to have delete predicate only
 		obj, opts := b.OwnsArgsForCall(tst.index)
@@ -151,3 +170,225 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		require.Equal(t, tst.pred, opts[0])
 	}
 }
+
+func TestLokiStackController_RegisterWatchedResources(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	b := &k8sfakes.FakeBuilder{}
+	b.ForReturns(b)
+	b.OwnsReturns(b)
+	b.WatchesReturns(b)
+
+	c := &LokiStackReconciler{Client: k, Scheme: scheme}
+	err := c.buildController(b)
+	require.NoError(t, err)
+
+	// Require Watches-Calls for all watched resources
+	require.Equal(t, 5, b.WatchesCallCount())
+
+	src, _, _ := b.WatchesArgsForCall(0)
+	require.Equal(t, &source.Kind{Type: &lokiv1beta1.AlertingRule{}}, src)
+
+	src, _, _ = b.WatchesArgsForCall(1)
+	require.Equal(t, &source.Kind{Type: &lokiv1beta1.RecordingRule{}}, src)
+
+	src, _, _ = b.WatchesArgsForCall(2)
+	require.Equal(t, &source.Kind{Type: &corev1.Secret{}}, src)
+
+	src, _, _ = b.WatchesArgsForCall(3)
+	require.Equal(t, &source.Kind{Type: &corev1.ConfigMap{}}, src)
+}
+
+func TestSecretsIndexFunc_ReturnsStorageAndTenantSecrets(t *testing.T) {
+	stack := &lokiv1beta1.LokiStack{
+		Spec: lokiv1beta1.LokiStackSpec{
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: "storage-secret",
+				},
+			},
+			Tenants: &lokiv1beta1.TenantsSpec{
+				Mode: lokiv1beta1.Dynamic,
+				Authentication: []lokiv1beta1.AuthenticationSpec{
+					{
+						TenantName: "tenant-a",
+						OIDC: &lokiv1beta1.OIDCSpec{
+							Secret: &lokiv1beta1.TenantSecretSpec{Name: "tenant-secret"},
+						},
+					},
+					{
+						TenantName: "tenant-b",
+						OIDC: &lokiv1beta1.OIDCSpec{
+							Secret: &lokiv1beta1.TenantSecretSpec{Name: "tenant-secret"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, []string{"storage-secret", "tenant-secret"}, secretsIndexFunc(stack))
+	require.Nil(t, secretsIndexFunc(&corev1.Secret{}))
+}
+
+func TestLokiStacksForSecret_ListsStacksByIndex(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "storage-secret",
+			Namespace: "some-ns",
+		},
+	}
+
+	stacks := lokiv1beta1.LokiStackList{
+		Items: []lokiv1beta1.LokiStack{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+			},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		lo := &client.ListOptions{}
+		lo.ApplyOptions(opts)
+
+		require.Equal(t, "some-ns", lo.Namespace)
+		name, ok := lo.FieldSelector.RequiresExactMatch(secretsIndexField)
+		require.True(t, ok)
+		require.Equal(t, "storage-secret", name)
+		k.SetClientObjectList(list, &stacks)
+		return nil
+	}
+
+	c := &LokiStackReconciler{Client: k, Scheme: scheme}
+	requests := c.lokiStacksForSecret(secret)
+
+	require.Equal(t, []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      "my-stack",
+				Namespace: "some-ns",
+			},
+		},
+	}, requests)
+}
+
+func TestLokiStacksForSizeProfiles_ListsStacksReferencingProfiles(t *testing.T) {
+	require.NoError(t, os.Setenv(manifests.EnvOperatorNamespace, "operator-ns"))
+	defer os.Unsetenv(manifests.EnvOperatorNamespace)
+
+	k := &k8sfakes.FakeClient{}
+	stacks := lokiv1beta1.LokiStackList{
+		Items: []lokiv1beta1.LokiStack{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+				Spec: lokiv1beta1.LokiStackSpec{
+					SizeProfile: "custom",
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-stack",
+					Namespace: "some-ns",
+				},
+			},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		k.SetClientObjectList(list, &stacks)
+		return nil
+	}
+
+	c := &LokiStackReconciler{Client: k, Scheme: scheme}
+
+	other := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      manifests.SizeProfilesConfigMapName,
+			Namespace: "some-ns",
+		},
+	}
+	require.Empty(t, c.lokiStacksForSizeProfiles(other))
+	require.Zero(t, k.ListCallCount())
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      manifests.SizeProfilesConfigMapName,
+			Namespace: "operator-ns",
+		},
+	}
+	require.Equal(t, []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      "my-stack",
+				Namespace: "some-ns",
+			},
+		},
+	}, c.lokiStacksForSizeProfiles(cm))
+}
+
+func TestLokiStacksForStorageCA_ListsStacksReferencingConfigMap(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	stacks := lokiv1beta1.LokiStackList{
+		Items: []lokiv1beta1.LokiStack{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+				Spec: lokiv1beta1.LokiStackSpec{
+					Storage: lokiv1beta1.ObjectStorageSpec{
+						TLS: &lokiv1beta1.ObjectStorageTLSSpec{CA: "storage-ca"},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-stack",
+					Namespace: "some-ns",
+				},
+				Spec: lokiv1beta1.LokiStackSpec{
+					Storage: lokiv1beta1.ObjectStorageSpec{
+						TLS: &lokiv1beta1.ObjectStorageTLSSpec{CA: "other-ca"},
+					},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "no-tls-stack",
+					Namespace: "some-ns",
+				},
+			},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		k.SetClientObjectList(list, &stacks)
+		return nil
+	}
+
+	c := &LokiStackReconciler{Client: k, Scheme: scheme}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "storage-ca",
+			Namespace: "some-ns",
+		},
+	}
+	require.Equal(t, []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      "my-stack",
+				Namespace: "some-ns",
+			},
+		},
+	}, c.lokiStacksForStorageCA(cm))
+
+	_, _, opts := k.ListArgsForCall(0)
+	require.Equal(t, []client.ListOption{client.InNamespace("some-ns")}, opts)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ViaQ/loki-operator/controllers/internal/management/state"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/status"
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+)
+
+var (
+	ignoreAllPred = builder.WithPredicates(predicate.Funcs{
+		UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	})
+	workloadStatusChangedPred = builder.WithPredicates(predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// Refresh the components health on workload status changes only,
+			// spec changes are reconciled by the LokiStack controller.
+			return workloadStatusChanged(e.ObjectOld, e.ObjectNew)
+		},
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	})
+)
+
+// LokiStackStatusReconciler refreshes the status of a LokiStack object
+// on status changes of its workloads without reconciling the whole stack.
+type LokiStackStatusReconciler struct {
+	client.Client
+	Log logr.Logger
+}
+
+// Reconcile refreshes the components status of a managed LokiStack and the
+// conditions derived from the workload health unless the stack is degraded.
+func (r *LokiStackStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var stack lokiv1beta1.LokiStack
+	if err := r.Get(ctx, req.NamespacedName, &stack); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !stack.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	ok, err := state.IsManaged(ctx, req, r.Client)
+	if err != nil || !ok {
+		return ctrl.Result{}, err
+	}
+
+	if err := status.RefreshComponents(ctx, r.Client, req); err != nil {
+		return ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: time.Second,
+		}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LokiStackStatusReconciler) SetupWithManager(mgr manager.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr)
+	return r.buildController(k8s.NewCtrlBuilder(b))
+}
+
+func (r *LokiStackStatusReconciler) buildController(bld k8s.Builder) error {
+	return bld.
+		Named("lokistack-status").
+		For(&lokiv1beta1.LokiStack{}, ignoreAllPred).
+		Owns(&appsv1.Deployment{}, workloadStatusChangedPred).
+		Owns(&appsv1.StatefulSet{}, workloadStatusChangedPred).
+		Complete(r)
+}
+
+// workloadStatusChanged returns true if the replica counts or the
+// observed generation of a deployment or statefulset changed.
+func workloadStatusChanged(oldObj, newObj client.Object) bool {
+	switch o := oldObj.(type) {
+	case *appsv1.Deployment:
+		n, ok := newObj.(*appsv1.Deployment)
+		if !ok {
+			return false
+		}
+		return o.Status.ObservedGeneration != n.Status.ObservedGeneration ||
+			o.Status.Replicas != n.Status.Replicas ||
+			o.Status.ReadyReplicas != n.Status.ReadyReplicas ||
+			o.Status.AvailableReplicas != n.Status.AvailableReplicas ||
+			o.Status.UpdatedReplicas != n.Status.UpdatedReplicas
+	case *appsv1.StatefulSet:
+		n, ok := newObj.(*appsv1.StatefulSet)
+		if !ok {
+			return false
+		}
+		return o.Status.ObservedGeneration != n.Status.ObservedGeneration ||
+			o.Status.Replicas != n.Status.Replicas ||
+			o.Status.ReadyReplicas != n.Status.ReadyReplicas ||
+			o.Status.CurrentReplicas != n.Status.CurrentReplicas ||
+			o.Status.UpdatedReplicas != n.Status.UpdatedReplicas
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+)
+
+func TestLokiStackStatusController_RegistersWorkloadsForStatusChanges(t *testing.T) {
+	b := &k8sfakes.FakeBuilder{}
+	k := &k8sfakes.FakeClient{}
+	c := &LokiStackStatusReconciler{Client: k}
+
+	b.NamedReturns(b)
+	b.ForReturns(b)
+	b.OwnsReturns(b)
+
+	err := c.buildController(b)
+	require.NoError(t, err)
+
+	require.Equal(t, "lokistack-status", b.NamedArgsForCall(0))
+
+	obj, opts := b.ForArgsForCall(0)
+	require.Equal(t, &lokiv1beta1.LokiStack{}, obj)
+	require.Equal(t, ignoreAllPred, opts[0])
+
+	require.Equal(t, 2, b.OwnsCallCount())
+	for i, want := range []client.Object{&appsv1.Deployment{}, &appsv1.StatefulSet{}} {
+		obj, opts := b.OwnsArgsForCall(i)
+		require.Equal(t, want, obj)
+		require.Equal(t, workloadStatusChangedPred, opts[0])
+	}
+}
+
+func TestWorkloadStatusChanged(t *testing.T) {
+	table := []struct {
+		name string
+		old  client.Object
+		new  client.Object
+		want bool
+	}{
+		{
+			name: "deployment ready replicas changed",
+			old:  &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+			new:  &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 2}},
+			want: true,
+		},
+		{
+			name: "deployment metadata changed",
+			old:  &appsv1.Deployment{Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+			new: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{ResourceVersion: "2"},
+				Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+			},
+		},
+		{
+			name: "statefulset observed generation changed",
+			old:  &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ObservedGeneration: 1}},
+			new:  &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ObservedGeneration: 2}},
+			want: true,
+		},
+		{
+			name: "statefulset status unchanged",
+			old:  &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 3}},
+			new:  &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{ReadyReplicas: 3}},
+		},
+		{
+			name: "other kinds",
+			old:  &corev1.ConfigMap{},
+			new:  &corev1.ConfigMap{},
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, workloadStatusChanged(tc.old, tc.new))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package ingesters
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/ViaQ/loki-operator/internal/manifests/openshift"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const requestTimeout = 5 * time.Second
+
+// Client calls the HTTP API of the Loki ingesters and the ingester ring.
+type Client interface {
+	// FlushShutdown flushes all in-memory chunks of the ingester
+	// listening on addr and makes it leave the ring.
+	FlushShutdown(ctx context.Context, addr string) error
+	// RingStates returns the state of each ingester in the ring by ID
+	// as served by the ring status endpoint listening on addr.
+	RingStates(ctx context.Context, addr string) (map[string]string, error)
+}
+
+type httpClient struct {
+	scheme string
+	client *http.Client
+}
+
+// NewClient returns a Client calling the Loki HTTP endpoints. With TLS
+// enabled the server certificates are verified using the system roots
+// and the PEM encoded certificates of the caBundle, e.g. the service CA
+// signing the certificates of the Loki components.
+func NewClient(tlsEnabled bool, caBundle []byte) (Client, error) {
+	c := &httpClient{
+		scheme: "http",
+		client: &http.Client{Timeout: requestTimeout},
+	}
+
+	if tlsEnabled {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if len(caBundle) > 0 && !pool.AppendCertsFromPEM(caBundle) {
+			return nil, kverrors.New("invalid CA bundle without PEM certificates")
+		}
+
+		c.scheme = "https"
+		c.client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	}
+
+	return c, nil
+}
+
+// ServiceCABundle returns the service CA injected by the cert-signing service
+// into the CA bundle configmap mounted by the gateway of the stack. It returns
+// no CA bundle if the configmap does not exist (yet).
+func ServiceCABundle(ctx context.Context, k k8s.Client, stack client.ObjectKey) ([]byte, error) {
+	var cm corev1.ConfigMap
+	key := client.ObjectKey{Name: openshift.ServiceCABundleName(manifests.GatewayName(stack.Name)), Namespace: stack.Namespace}
+	if err := k.Get(ctx, key, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, kverrors.Wrap(err, "failed to lookup service CA bundle", "name", key)
+	}
+
+	return []byte(cm.Data[openshift.ServiceCABundleKey]), nil
+}
+
+func (c *httpClient) FlushShutdown(ctx context.Context, addr string) error {
+	u := fmt.Sprintf("%s://%s/ingester/flush_shutdown", c.scheme, addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to create flush request", "url", u)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to flush ingester", "url", u)
+	}
+	defer drain(res.Body)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return kverrors.New("failed to flush ingester", "url", u, "status", res.StatusCode)
+	}
+
+	return nil
+}
+
+// ringResponse is the JSON representation of the ring status page.
+type ringResponse struct {
+	Shards []struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	} `json:"shards"`
+}
+
+func (c *httpClient) RingStates(ctx context.Context, addr string) (map[string]string, error) {
+	u := fmt.Sprintf("%s://%s/ring", c.scheme, addr)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create ring request", "url", u)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to fetch ring status", "url", u)
+	}
+	defer drain(res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		return nil, kverrors.New("failed to fetch ring status", "url", u, "status", res.StatusCode)
+	}
+
+	var ring ringResponse
+	if err := json.NewDecoder(res.Body).Decode(&ring); err != nil {
+		return nil, kverrors.Wrap(err, "failed to decode ring status", "url", u)
+	}
+
+	states := make(map[string]string, len(ring.Shards))
+	for _, s := range ring.Shards {
+		states[s.ID] = s.State
+	}
+
+	return states, nil
+}
+
+func drain(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, body)
+	_ = body.Close()
+}
@@ -0,0 +1,99 @@
+package ingesters
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushShutdown(t *testing.T) {
+	var method, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(false, nil)
+	require.NoError(t, err)
+
+	err = c.FlushShutdown(context.TODO(), strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, method)
+	require.Equal(t, "/ingester/flush_shutdown", path)
+}
+
+func TestFlushShutdown_WhenServerFails_ReturnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(false, nil)
+	require.NoError(t, err)
+
+	err = c.FlushShutdown(context.TODO(), strings.TrimPrefix(srv.URL, "http://"))
+	require.Error(t, err)
+}
+
+func TestRingStates(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/ring", r.URL.Path)
+		require.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		_, _ = w.Write([]byte(`{"shards":[
+			{"id":"loki-ingester-my-stack-0","state":"ACTIVE","tokens":[1,2]},
+			{"id":"loki-ingester-my-stack-1","state":"LEAVING","tokens":[3,4]}
+		]}`))
+	}))
+	defer srv.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	c, err := NewClient(true, ca)
+	require.NoError(t, err)
+
+	states, err := c.RingStates(context.TODO(), strings.TrimPrefix(srv.URL, "https://"))
+	require.NoError(t, err)
+
+	want := map[string]string{
+		"loki-ingester-my-stack-0": "ACTIVE",
+		"loki-ingester-my-stack-1": "LEAVING",
+	}
+	require.Equal(t, want, states)
+}
+
+func TestRingStates_WhenInvalidResponse_ReturnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html>ring</html>`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(false, nil)
+	require.NoError(t, err)
+
+	_, err = c.RingStates(context.TODO(), strings.TrimPrefix(srv.URL, "http://"))
+	require.Error(t, err)
+}
+
+func TestRingStates_WhenUnknownCertificateAuthority_ReturnError(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"shards":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(true, nil)
+	require.NoError(t, err)
+
+	_, err = c.RingStates(context.TODO(), strings.TrimPrefix(srv.URL, "https://"))
+	require.Error(t, err)
+}
+
+func TestNewClient_WhenInvalidCABundle_ReturnError(t *testing.T) {
+	_, err := NewClient(true, []byte("not a certificate"))
+	require.Error(t, err)
+}
@@ -0,0 +1,296 @@
+package ingesters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ringStateActive is the ring state of an ingester owning tokens.
+	ringStateActive = "ACTIVE"
+	// ringStateLeaving is the ring state of an ingester flushing its chunks.
+	ringStateLeaving = "LEAVING"
+
+	// drainingValue marks a removed ingester as flushed next.
+	drainingValue = "true"
+	// drainedValue marks a removed ingester as flushed successfully. The
+	// ingester is not flushed again when restarted by its container runtime.
+	drainedValue = "drained"
+)
+
+// PrepareScaleDown drains the ingesters removed by scaling down any of the
+// desired ingester statefulsets before the statefulset is scaled down:
+// The removed ingesters are flushed one at a time, highest ordinal first,
+// and their pods are annotated as draining until the flush succeeds.
+// Meanwhile the replicas of the desired statefulset are kept above the
+// highest removed ingester not drained yet, i.e. each ingester is removed
+// as soon as it is drained. It returns true if any ingester is still draining.
+func PrepareScaleDown(ctx context.Context, ll logr.Logger, k k8s.Client, c Client, stack client.ObjectKey, objs []client.Object) (bool, error) {
+	var draining bool
+
+	ringAddr := manifests.RingHTTPAddress(stack.Name, stack.Namespace)
+	for _, sts := range ingesterStatefulSets(stack.Name, objs) {
+		d, err := prepareStatefulSet(ctx, ll, k, c, stack.Namespace, ringAddr, sts)
+		if err != nil {
+			return false, err
+		}
+		draining = draining || d
+	}
+
+	return draining, nil
+}
+
+func prepareStatefulSet(ctx context.Context, ll logr.Logger, k k8s.Client, c Client, namespace, ringAddr string, desired *appsv1.StatefulSet) (bool, error) {
+	var current appsv1.StatefulSet
+	key := client.ObjectKey{Name: desired.Name, Namespace: namespace}
+	if err := k.Get(ctx, key, &current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, kverrors.Wrap(err, "failed to lookup ingester statefulset", "name", key)
+	}
+
+	if current.Spec.Replicas == nil || desired.Spec.Replicas == nil {
+		return false, nil
+	}
+	currentReplicas, desiredReplicas := *current.Spec.Replicas, *desired.Spec.Replicas
+
+	pods, err := listPods(ctx, k, namespace, desired)
+	if err != nil {
+		return false, err
+	}
+
+	if desiredReplicas >= currentReplicas {
+		// Release ingesters still marked as draining by a scale-down reverted in the meantime.
+		for _, pod := range pods {
+			if _, ok := pod.Annotations[manifests.AnnotationIngesterDraining]; !ok {
+				continue
+			}
+
+			delete(pod.Annotations, manifests.AnnotationIngesterDraining)
+			if err := k.Update(ctx, pod); err != nil {
+				return false, kverrors.Wrap(err, "failed to release draining ingester", "name", pod.Name)
+			}
+		}
+		return false, nil
+	}
+
+	l := ll.WithValues("statefulset", desired.Name, "replicas", currentReplicas, "desired_replicas", desiredReplicas)
+
+	// The removed ingesters not drained yet, highest ordinal first.
+	var removed []int32
+	for ordinal := currentReplicas - 1; ordinal >= desiredReplicas; ordinal-- {
+		name := fmt.Sprintf("%s-%d", desired.Name, ordinal)
+		if pod, ok := pods[name]; ok && pod.Annotations[manifests.AnnotationIngesterDraining] == drainedValue {
+			continue
+		}
+		removed = append(removed, ordinal)
+	}
+
+	if len(removed) == 0 {
+		l.Info("All removed ingesters drained, scaling down")
+		return false, nil
+	}
+
+	states, err := c.RingStates(ctx, ringAddr)
+	if err != nil {
+		// Never scale down blindly, the removed ingesters might hold unflushed chunks.
+		l.Error(err, "failed to lookup ingester ring, postponing scale-down")
+		replicas := removed[0] + 1
+		desired.Spec.Replicas = &replicas
+		return true, nil
+	}
+
+	var (
+		remaining   []int32
+		leaving     bool
+		next        *corev1.Pod
+		nextOrdinal int32
+	)
+	for _, ordinal := range removed {
+		name := fmt.Sprintf("%s-%d", desired.Name, ordinal)
+
+		state, ok := states[name]
+		if !ok {
+			continue
+		}
+
+		pod, ok := pods[name]
+		if !ok {
+			// The statefulset controller recreates the pod, flush it afterwards.
+			remaining = append(remaining, ordinal)
+			continue
+		}
+
+		if _, ok := pod.Annotations[manifests.AnnotationIngesterDraining]; !ok {
+			if err := annotate(ctx, k, pod, drainingValue); err != nil {
+				return false, kverrors.Wrap(err, "failed to mark ingester as draining", "name", pod.Name)
+			}
+		}
+
+		switch state {
+		case ringStateLeaving:
+			leaving = true
+		case ringStateActive:
+			if next == nil {
+				next, nextOrdinal = pod, ordinal
+			}
+		}
+		remaining = append(remaining, ordinal)
+	}
+
+	// Flush a single ingester at a time to keep the write path available.
+	if !leaving && next != nil && next.Status.PodIP != "" {
+		l.Info("Flushing ingester before scale-down", "pod", next.Name)
+		if err := c.FlushShutdown(ctx, manifests.HTTPAddress(next.Status.PodIP)); err != nil {
+			return false, kverrors.Wrap(err, "failed to flush ingester", "name", next.Name)
+		}
+
+		if err := annotate(ctx, k, next, drainedValue); err != nil {
+			return false, kverrors.Wrap(err, "failed to mark ingester as drained", "name", next.Name)
+		}
+
+		for i, ordinal := range remaining {
+			if ordinal == nextOrdinal {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+
+	if len(remaining) == 0 {
+		l.Info("All removed ingesters drained or left the ring, scaling down")
+		return false, nil
+	}
+
+	// Scale down to the highest removed ingester not drained yet, so that the
+	// drained ingesters above it are removed right away instead of accepting
+	// writes again after restarting.
+	replicas := remaining[0] + 1
+	desired.Spec.Replicas = &replicas
+	return true, nil
+}
+
+// RetiredStatefulSets returns the ingester statefulsets controlled by the stack
+// that are not part of the desired objects anymore, e.g. the statefulset of a
+// single ring after enabling zone-aware replication. They are returned scaled
+// down to zero replicas until all pods are gone, so that their ingesters are
+// drained by PrepareScaleDown instead of being pruned with unflushed chunks.
+func RetiredStatefulSets(ctx context.Context, k k8s.Client, stack *lokiv1beta1.LokiStack, objs []client.Object) ([]client.Object, error) {
+	desired := map[string]bool{}
+	for _, sts := range ingesterStatefulSets(stack.Name, objs) {
+		desired[sts.Name] = true
+	}
+
+	var list appsv1.StatefulSetList
+	opts := []client.ListOption{
+		client.InNamespace(stack.Namespace),
+		client.MatchingLabels(manifests.ComponentLabels(manifests.LabelIngesterComponent, stack.Name)),
+	}
+	if err := k.List(ctx, &list, opts...); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list ingester statefulsets", "name", stack.Name)
+	}
+
+	var retired []client.Object
+	for i := range list.Items {
+		sts := &list.Items[i]
+		if desired[sts.Name] || !metav1.IsControlledBy(sts, stack) {
+			continue
+		}
+
+		scaledDown := sts.Spec.Replicas != nil && *sts.Spec.Replicas == 0
+		if scaledDown && sts.Status.Replicas == 0 {
+			// Drained, leave it to pruning.
+			continue
+		}
+
+		sts = sts.DeepCopy()
+		sts.Spec.Replicas = pointer.Int32Ptr(0)
+		retired = append(retired, sts)
+	}
+
+	return retired, nil
+}
+
+// PruneClaims deletes the persistent volume claims of the ingesters
+// removed by scaling down any of the desired ingester statefulsets.
+func PruneClaims(ctx context.Context, ll logr.Logger, k k8s.Client, stack client.ObjectKey, objs []client.Object) error {
+	for _, sts := range ingesterStatefulSets(stack.Name, objs) {
+		if sts.Spec.Replicas == nil {
+			continue
+		}
+
+		for _, vct := range sts.Spec.VolumeClaimTemplates {
+			var pvcs corev1.PersistentVolumeClaimList
+			if err := k.List(ctx, &pvcs, client.InNamespace(stack.Namespace), client.MatchingLabels(vct.Labels)); err != nil {
+				return kverrors.Wrap(err, "failed to list ingester persistent volume claims", "name", sts.Name)
+			}
+
+			prefix := fmt.Sprintf("%s-%s-", vct.Name, sts.Name)
+			for i := range pvcs.Items {
+				pvc := &pvcs.Items[i]
+
+				ordinal, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix))
+				if !strings.HasPrefix(pvc.Name, prefix) || err != nil || int32(ordinal) < *sts.Spec.Replicas {
+					continue
+				}
+
+				if err := k.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+					return kverrors.Wrap(err, "failed to delete ingester persistent volume claim", "name", pvc.Name)
+				}
+				ll.Info("Persistent volume claim of removed ingester has been deleted", "name", pvc.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func annotate(ctx context.Context, k k8s.Client, pod *corev1.Pod, value string) error {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[manifests.AnnotationIngesterDraining] = value
+	return k.Update(ctx, pod)
+}
+
+func listPods(ctx context.Context, k k8s.Client, namespace string, sts *appsv1.StatefulSet) (map[string]*corev1.Pod, error) {
+	var list corev1.PodList
+	if err := k.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list ingester pods", "name", sts.Name)
+	}
+
+	pods := make(map[string]*corev1.Pod, len(list.Items))
+	for i := range list.Items {
+		pods[list.Items[i].Name] = &list.Items[i]
+	}
+	return pods, nil
+}
+
+func ingesterStatefulSets(stackName string, objs []client.Object) []*appsv1.StatefulSet {
+	sel := labels.SelectorFromSet(manifests.ComponentLabels(manifests.LabelIngesterComponent, stackName))
+
+	var sets []*appsv1.StatefulSet
+	for _, obj := range objs {
+		if sts, ok := obj.(*appsv1.StatefulSet); ok && sel.Matches(labels.Set(sts.Labels)) {
+			sets = append(sets, sts)
+		}
+	}
+	return sets
+}
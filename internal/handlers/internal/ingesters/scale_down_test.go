@@ -0,0 +1,319 @@
+package ingesters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ViaQ/logerr/log"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeRingClient struct {
+	states  map[string]string
+	err     error
+	flushed []string
+}
+
+func (f *fakeRingClient) FlushShutdown(_ context.Context, addr string) error {
+	f.flushed = append(f.flushed, addr)
+	return nil
+}
+
+func (f *fakeRingClient) RingStates(_ context.Context, _ string) (map[string]string, error) {
+	return f.states, f.err
+}
+
+var stack = types.NamespacedName{Name: "my-stack", Namespace: "some-ns"}
+
+func ingesterStatefulSet(replicas int32) *appsv1.StatefulSet {
+	l := manifests.ComponentLabels(manifests.LabelIngesterComponent, stack.Name)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      manifests.IngesterName(stack.Name),
+			Namespace: stack.Namespace,
+			Labels:    l,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: l},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "storage", Labels: l}},
+			},
+		},
+	}
+}
+
+func ingesterPod(ordinal string, ip string, annotations map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        manifests.IngesterName(stack.Name) + "-" + ordinal,
+			Namespace:   stack.Namespace,
+			Annotations: annotations,
+		},
+		Status: corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func newFakeClient(current *appsv1.StatefulSet, pods ...corev1.Pod) *k8sfakes.FakeClient {
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if current != nil && name.Name == current.Name && name.Namespace == current.Namespace {
+			k.SetClientObject(object, current)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, name.Name)
+	}
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := l.(*corev1.PodList); ok {
+			k.SetClientObjectList(l, &corev1.PodList{Items: pods})
+		}
+		return nil
+	}
+	return k
+}
+
+func TestPrepareScaleDown_WhenNoStatefulSetExists_DoNothing(t *testing.T) {
+	k := newFakeClient(nil)
+	c := &fakeRingClient{}
+	desired := ingesterStatefulSet(2)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.False(t, draining)
+	require.Equal(t, int32(2), *desired.Spec.Replicas)
+	require.Empty(t, c.flushed)
+}
+
+func TestPrepareScaleDown_WhenRemovedIngesterActive_FlushAndRemoveFlushedIngester(t *testing.T) {
+	k := newFakeClient(ingesterStatefulSet(3),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", nil),
+		ingesterPod("2", "10.0.0.3", nil),
+	)
+	c := &fakeRingClient{
+		states: map[string]string{
+			"loki-ingester-my-stack-0": "ACTIVE",
+			"loki-ingester-my-stack-1": "ACTIVE",
+			"loki-ingester-my-stack-2": "ACTIVE",
+		},
+	}
+	desired := ingesterStatefulSet(1)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.True(t, draining)
+	// Remove the flushed ingester right away, keep the one not drained yet
+	require.Equal(t, int32(2), *desired.Spec.Replicas)
+
+	// Flush a single ingester, highest ordinal first
+	require.Equal(t, []string{"10.0.0.3:3100"}, c.flushed)
+
+	// Mark both removed ingesters as draining and the flushed one as drained
+	require.Equal(t, 3, k.UpdateCallCount())
+	for i := 0; i < k.UpdateCallCount(); i++ {
+		_, obj, _ := k.UpdateArgsForCall(i)
+		require.Contains(t, obj.GetAnnotations(), manifests.AnnotationIngesterDraining)
+	}
+
+	_, obj, _ := k.UpdateArgsForCall(2)
+	require.Equal(t, "loki-ingester-my-stack-2", obj.GetName())
+	require.Equal(t, "drained", obj.GetAnnotations()[manifests.AnnotationIngesterDraining])
+}
+
+func TestPrepareScaleDown_WhenRemovedIngesterLeaving_WaitWithoutFlush(t *testing.T) {
+	draining := map[string]string{manifests.AnnotationIngesterDraining: "true"}
+	k := newFakeClient(ingesterStatefulSet(3),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", draining),
+		ingesterPod("2", "10.0.0.3", draining),
+	)
+	c := &fakeRingClient{
+		states: map[string]string{
+			"loki-ingester-my-stack-0": "ACTIVE",
+			"loki-ingester-my-stack-1": "ACTIVE",
+			"loki-ingester-my-stack-2": "LEAVING",
+		},
+	}
+	desired := ingesterStatefulSet(1)
+
+	ok, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int32(3), *desired.Spec.Replicas)
+	require.Empty(t, c.flushed)
+	require.Zero(t, k.UpdateCallCount())
+}
+
+func TestPrepareScaleDown_WhenRemovedIngestersDrainedOrLeftRing_ScaleDown(t *testing.T) {
+	k := newFakeClient(ingesterStatefulSet(3),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", map[string]string{manifests.AnnotationIngesterDraining: "drained"}),
+	)
+	c := &fakeRingClient{
+		states: map[string]string{
+			"loki-ingester-my-stack-0": "ACTIVE",
+			// Restarted by the container runtime after the flush
+			"loki-ingester-my-stack-1": "ACTIVE",
+		},
+	}
+	desired := ingesterStatefulSet(1)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.False(t, draining)
+	require.Equal(t, int32(1), *desired.Spec.Replicas)
+	require.Empty(t, c.flushed)
+	require.Zero(t, k.UpdateCallCount())
+}
+
+func TestPrepareScaleDown_WhenRemovedIngestersDrained_ScaleDownWithoutRing(t *testing.T) {
+	drained := map[string]string{manifests.AnnotationIngesterDraining: "drained"}
+	k := newFakeClient(ingesterStatefulSet(3),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", drained),
+		ingesterPod("2", "10.0.0.3", drained),
+	)
+	c := &fakeRingClient{err: apierrors.NewServiceUnavailable("ring")}
+	desired := ingesterStatefulSet(1)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.False(t, draining)
+	require.Equal(t, int32(1), *desired.Spec.Replicas)
+}
+
+func TestPrepareScaleDown_WhenRingUnavailable_KeepReplicas(t *testing.T) {
+	k := newFakeClient(ingesterStatefulSet(3))
+	c := &fakeRingClient{err: apierrors.NewServiceUnavailable("ring")}
+	desired := ingesterStatefulSet(1)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.True(t, draining)
+	require.Equal(t, int32(3), *desired.Spec.Replicas)
+}
+
+func TestPrepareScaleDown_WhenRingUnavailable_RemoveDrainedIngesters(t *testing.T) {
+	k := newFakeClient(ingesterStatefulSet(3),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", map[string]string{manifests.AnnotationIngesterDraining: "true"}),
+		ingesterPod("2", "10.0.0.3", map[string]string{manifests.AnnotationIngesterDraining: "drained"}),
+	)
+	c := &fakeRingClient{err: apierrors.NewServiceUnavailable("ring")}
+	desired := ingesterStatefulSet(1)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.True(t, draining)
+	require.Equal(t, int32(2), *desired.Spec.Replicas)
+}
+
+func TestPrepareScaleDown_WhenScaleDownReverted_ReleaseDrainingIngesters(t *testing.T) {
+	k := newFakeClient(ingesterStatefulSet(2),
+		ingesterPod("0", "10.0.0.1", nil),
+		ingesterPod("1", "10.0.0.2", map[string]string{manifests.AnnotationIngesterDraining: "true"}),
+	)
+	c := &fakeRingClient{}
+	desired := ingesterStatefulSet(2)
+
+	draining, err := PrepareScaleDown(context.TODO(), log.WithName("test"), k, c, stack, []client.Object{desired})
+	require.NoError(t, err)
+	require.False(t, draining)
+	require.Equal(t, 1, k.UpdateCallCount())
+
+	_, obj, _ := k.UpdateArgsForCall(0)
+	require.Equal(t, "loki-ingester-my-stack-1", obj.GetName())
+	require.NotContains(t, obj.GetAnnotations(), manifests.AnnotationIngesterDraining)
+}
+
+func TestPruneClaims_DeleteClaimsOfRemovedIngesters(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		k.SetClientObjectList(l, &corev1.PersistentVolumeClaimList{
+			Items: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "storage-loki-ingester-my-stack-0"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "storage-loki-ingester-my-stack-1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "storage-loki-ingester-my-stack-2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "storage-loki-ingester-my-stack-zone-a-2"}},
+			},
+		})
+		return nil
+	}
+
+	err := PruneClaims(context.TODO(), log.WithName("test"), k, stack, []client.Object{ingesterStatefulSet(2)})
+	require.NoError(t, err)
+	require.Equal(t, 1, k.DeleteCallCount())
+
+	_, obj, _ := k.DeleteArgsForCall(0)
+	require.Equal(t, "storage-loki-ingester-my-stack-2", obj.GetName())
+}
+
+func TestRetiredStatefulSets_ScaleDownUndesiredIngesterStatefulSets(t *testing.T) {
+	owner := &lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stack.Name,
+			Namespace: stack.Namespace,
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+	}
+	ownerRefs := []metav1.OwnerReference{
+		{
+			APIVersion: "loki.openshift.io/v1beta1",
+			Kind:       "LokiStack",
+			Name:       stack.Name,
+			UID:        owner.UID,
+			Controller: pointer.BoolPtr(true),
+		},
+	}
+
+	// Replaced by zone statefulsets, still running
+	single := ingesterStatefulSet(3)
+	single.OwnerReferences = ownerRefs
+	single.Status.Replicas = 3
+
+	// Removed zone, scaled down and drained
+	drained := ingesterStatefulSet(0)
+	drained.Name = manifests.IngesterZoneName(stack.Name, "zone-c")
+	drained.OwnerReferences = ownerRefs
+
+	// Still desired
+	zoneA := ingesterStatefulSet(2)
+	zoneA.Name = manifests.IngesterZoneName(stack.Name, "zone-a")
+	zoneA.OwnerReferences = ownerRefs
+
+	// Not owned by the stack
+	foreign := ingesterStatefulSet(1)
+	foreign.Name = "some-other-ingester"
+
+	k := &k8sfakes.FakeClient{}
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		k.SetClientObjectList(l, &appsv1.StatefulSetList{
+			Items: []appsv1.StatefulSet{*single, *drained, *zoneA, *foreign},
+		})
+		return nil
+	}
+
+	desired := zoneA.DeepCopy()
+	desired.OwnerReferences = nil
+
+	retired, err := RetiredStatefulSets(context.TODO(), k, owner, []client.Object{desired})
+	require.NoError(t, err)
+	require.Len(t, retired, 1)
+
+	sts := retired[0].(*appsv1.StatefulSet)
+	require.Equal(t, single.Name, sts.Name)
+	require.Equal(t, int32(0), *sts.Spec.Replicas)
+	require.Equal(t, int32(3), *single.Spec.Replicas)
+}
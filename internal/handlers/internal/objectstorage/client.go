@@ -0,0 +1,208 @@
+package objectstorage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+)
+
+const (
+	requestTimeout = 5 * time.Second
+
+	defaultS3Region = "us-east-1"
+
+	amzDateLayout  = "20060102T150405Z"
+	amzScopeLayout = "20060102"
+
+	// emptyPayloadHash is the hex encoded SHA256 hash of an empty request body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Options configures the connection to an S3 compatible endpoint.
+type s3Options struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// VirtualHosted addresses the bucket as part of the
+	// host name instead of the request path.
+	VirtualHosted bool
+	// Insecure connects via http to endpoints without scheme.
+	Insecure bool
+	// CABundle is trusted in addition to the system certificates if set.
+	CABundle []byte
+}
+
+// s3Client sends signed requests to an S3 compatible endpoint.
+type s3Client struct {
+	endpoint        *url.URL
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	virtualHosted   bool
+	client          *http.Client
+	now             func() time.Time
+}
+
+// newS3Client returns a client for the endpoint, i.e. a URL or a host
+// name for which https is assumed unless insecure. The s3 scheme used by
+// Loki for AWS endpoints is served via https too.
+func newS3Client(opts s3Options) (*s3Client, error) {
+	endpoint := opts.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https://"
+		if opts.Insecure {
+			scheme = "http://"
+		}
+		endpoint = scheme + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "invalid endpoint", "endpoint", endpoint)
+	}
+	if u.Scheme == "s3" {
+		u.Scheme = "https"
+	}
+	if u.Host == "" {
+		return nil, kverrors.New("invalid endpoint without host", "endpoint", endpoint)
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	c := &s3Client{
+		endpoint:        u,
+		region:          region,
+		accessKeyID:     opts.AccessKeyID,
+		secretAccessKey: opts.SecretAccessKey,
+		virtualHosted:   opts.VirtualHosted,
+		client:          &http.Client{Timeout: requestTimeout},
+		now:             time.Now,
+	}
+
+	if len(opts.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(opts.CABundle) {
+			return nil, kverrors.New("invalid CA bundle without PEM certificates")
+		}
+		c.client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	}
+
+	return c, nil
+}
+
+// HeadBucket checks the existence of the bucket and the permission to access it.
+func (c *s3Client) HeadBucket(ctx context.Context, bucket string) error {
+	u := *c.endpoint
+	if c.virtualHosted {
+		u.Host = bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return &ProbeError{Reason: lokiv1beta1.ReasonUnreachableObjectStorage, Bucket: bucket, Err: err}
+	}
+	c.sign(req)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return &ProbeError{Reason: lokiv1beta1.ReasonUnreachableObjectStorage, Bucket: bucket, Err: err}
+	}
+	defer drain(res.Body)
+
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil
+	case res.StatusCode == http.StatusNotFound:
+		return &ProbeError{Reason: lokiv1beta1.ReasonMissingObjectStorageBucket, Bucket: bucket}
+	case res.StatusCode == http.StatusUnauthorized, res.StatusCode == http.StatusForbidden:
+		return &ProbeError{Reason: lokiv1beta1.ReasonObjectStorageAccessDenied, Bucket: bucket}
+	default:
+		return &ProbeError{
+			Reason: lokiv1beta1.ReasonUnreachableObjectStorage,
+			Bucket: bucket,
+			Err:    kverrors.New("unexpected response status", "status", res.StatusCode),
+		}
+	}
+}
+
+// sign adds the AWS signature version 4 authorization
+// to the request without a body.
+func (c *s3Client) sign(req *http.Request) {
+	now := c.now().UTC()
+	amzDate := now.Format(amzDateLayout)
+	scope := strings.Join([]string{now.Format(amzScopeLayout), c.region, "s3", "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + emptyPayloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + c.secretAccessKey)
+	for _, part := range []string{now.Format(amzScopeLayout), c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func drain(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, body)
+	_ = body.Close()
+}
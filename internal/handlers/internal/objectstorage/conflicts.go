@@ -0,0 +1,119 @@
+package objectstorage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/secrets"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FindConflict returns the namespaced name of a LokiStack in the cluster
+// storing to one of the buckets of the stack with the same prefix or an
+// empty string if there is none. Only LokiStacks created before the stack
+// are considered, so that the conflict is reported to the newer one and
+// the older one keeps running.
+func FindConflict(ctx context.Context, k k8s.Client, stack *lokiv1beta1.LokiStack, storage *manifests.ObjectStorage, s *corev1.Secret) (string, error) {
+	if storage.SharedStore == lokiv1beta1.ObjectStorageSecretFilesystem {
+		return "", nil
+	}
+
+	var stacks lokiv1beta1.LokiStackList
+	if err := k.List(ctx, &stacks); err != nil {
+		return "", kverrors.Wrap(err, "failed to list lokistacks")
+	}
+
+	used := map[string]bool{}
+	for _, l := range locations(storage, s) {
+		used[l] = true
+	}
+
+	for i := range stacks.Items {
+		other := &stacks.Items[i]
+		if !createdBefore(other, stack) || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if other.Spec.Storage.Prefix != stack.Spec.Storage.Prefix || storageType(other) != storage.SharedStore {
+			continue
+		}
+
+		var otherSecret corev1.Secret
+		key := client.ObjectKey{Name: other.Spec.Storage.Secret.Name, Namespace: other.Namespace}
+		if err := k.Get(ctx, key, &otherSecret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", kverrors.Wrap(err, "failed to lookup lokistack storage secret", "name", key)
+		}
+
+		otherStorage, err := secrets.Extract(&otherSecret, other.Spec.Storage.Type)
+		if err != nil {
+			// The other stack is degraded with an invalid secret and stores nothing.
+			continue
+		}
+
+		for _, l := range locations(otherStorage, &otherSecret) {
+			if used[l] {
+				return client.ObjectKeyFromObject(other).String(), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// createdBefore returns true if a was created before b. Stacks created
+// within the same second are ordered by namespace and name.
+func createdBefore(a, b *lokiv1beta1.LokiStack) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+func storageType(stack *lokiv1beta1.LokiStack) lokiv1beta1.ObjectStorageSecretType {
+	if stack.Spec.Storage.Type == "" {
+		return lokiv1beta1.ObjectStorageSecretS3
+	}
+	return stack.Spec.Storage.Type
+}
+
+// locations returns an identifier for each bucket or container
+// of the object storage qualified by its endpoint or account.
+func locations(storage *manifests.ObjectStorage, s *corev1.Secret) []string {
+	var ls []string
+
+	switch storage.SharedStore {
+	case lokiv1beta1.ObjectStorageSecretAzure:
+		if c := storage.Azure; c != nil {
+			ls = append(ls, strings.Join([]string{"azure", c.Env, string(s.Data["account_name"]), c.Container}, "/"))
+		}
+	case lokiv1beta1.ObjectStorageSecretGCS:
+		if c := storage.GCS; c != nil {
+			ls = append(ls, strings.Join([]string{"gcs", c.Bucket}, "/"))
+		}
+	case lokiv1beta1.ObjectStorageSecretSwift:
+		if c := storage.Swift; c != nil {
+			ls = append(ls, strings.Join([]string{"swift", c.AuthURL, c.DomainName, c.ProjectName, c.ProjectID, c.Container}, "/"))
+		}
+	case lokiv1beta1.ObjectStorageSecretS3:
+		if c := storage.S3; c != nil {
+			endpoint := strings.TrimSuffix(strings.ToLower(c.Endpoint), "/")
+			for _, b := range strings.Split(c.Buckets, ",") {
+				ls = append(ls, strings.Join([]string{"s3", endpoint, b}, "/"))
+			}
+		}
+	}
+
+	return ls
+}
@@ -0,0 +1,123 @@
+package objectstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/secrets"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func s3Secret(ns, endpoint, buckets string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "storage",
+			Namespace: ns,
+		},
+		Data: map[string][]byte{
+			"endpoint":          []byte(endpoint),
+			"bucketnames":       []byte(buckets),
+			"access_key_id":     []byte("id"),
+			"access_key_secret": []byte("secret"),
+		},
+	}
+}
+
+func s3Stack(ns, prefix string, created time.Time) lokiv1beta1.LokiStack {
+	return lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "stack",
+			Namespace:         ns,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type:   lokiv1beta1.ObjectStorageSecretS3,
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{Name: "storage"},
+				Prefix: prefix,
+			},
+		},
+	}
+}
+
+func TestFindConflict(t *testing.T) {
+	now := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	table := []struct {
+		name         string
+		other        lokiv1beta1.LokiStack
+		otherSecret  *corev1.Secret
+		wantConflict string
+	}{
+		{
+			name:         "same bucket and prefix",
+			other:        s3Stack("other", "", now.Add(-time.Hour)),
+			otherSecret:  s3Secret("other", "https://minio:9000/", "loki-2,loki"),
+			wantConflict: "other/stack",
+		},
+		{
+			name:        "same bucket and prefix but created later",
+			other:       s3Stack("other", "", now.Add(time.Hour)),
+			otherSecret: s3Secret("other", "https://minio:9000", "loki"),
+		},
+		{
+			name:        "same bucket with another prefix",
+			other:       s3Stack("other", "team-b", now.Add(-time.Hour)),
+			otherSecret: s3Secret("other", "https://minio:9000", "loki"),
+		},
+		{
+			name:        "same bucket name on another endpoint",
+			other:       s3Stack("other", "", now.Add(-time.Hour)),
+			otherSecret: s3Secret("other", "https://s3.amazonaws.com", "loki"),
+		},
+		{
+			name:        "other buckets",
+			other:       s3Stack("other", "", now.Add(-time.Hour)),
+			otherSecret: s3Secret("other", "https://minio:9000", "loki-3"),
+		},
+		{
+			name:  "missing secret",
+			other: s3Stack("other", "", now.Add(-time.Hour)),
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			stack := s3Stack("ns", "", now)
+			s := s3Secret("ns", "https://minio:9000", "loki")
+
+			k := &k8sfakes.FakeClient{}
+			k.ListStub = func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+				k.SetClientObjectList(list, &lokiv1beta1.LokiStackList{
+					Items: []lokiv1beta1.LokiStack{stack, tc.other},
+				})
+				return nil
+			}
+			k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+				if tc.otherSecret != nil && name.Namespace == tc.otherSecret.Namespace {
+					k.SetClientObject(object, tc.otherSecret)
+					return nil
+				}
+				return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+			}
+
+			storage, err := secrets.Extract(s, lokiv1beta1.ObjectStorageSecretS3)
+			require.NoError(t, err)
+
+			conflict, err := FindConflict(context.TODO(), k, &stack, storage, s)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantConflict, conflict)
+		})
+	}
+}
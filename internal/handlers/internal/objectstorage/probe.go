@@ -0,0 +1,137 @@
+package objectstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProbeError is returned for buckets the object storage probe failed
+// to access. The reason tells apart unreachable endpoints, denied
+// access and missing buckets.
+type ProbeError struct {
+	Reason lokiv1beta1.LokiStackConditionReason
+	Bucket string
+	Err    error
+}
+
+func (e *ProbeError) Error() string {
+	var msg string
+	switch e.Reason {
+	case lokiv1beta1.ReasonMissingObjectStorageBucket:
+		msg = fmt.Sprintf("bucket %s does not exist", e.Bucket)
+	case lokiv1beta1.ReasonObjectStorageAccessDenied:
+		msg = fmt.Sprintf("access to bucket %s denied", e.Bucket)
+	default:
+		msg = fmt.Sprintf("endpoint unreachable for bucket %s", e.Bucket)
+	}
+
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *ProbeError) Unwrap() error {
+	return e.Err
+}
+
+// probeResult is the outcome of the last probe of a lokistack
+// object storage with the given configuration fingerprint.
+type probeResult struct {
+	fingerprint string
+	probedAt    time.Time
+	err         error
+}
+
+// Prober checks the connectivity of the object storage configured for
+// a lokistack. The result is kept for the interval to not send requests
+// to the object storage on every reconciliation. Changes of the storage
+// secret, the CA bundle or the connection settings are probed immediately.
+type Prober struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	results map[types.NamespacedName]probeResult
+}
+
+// NewProber returns a Prober probing each lokistack at most once per interval.
+func NewProber(interval time.Duration) *Prober {
+	return &Prober{
+		interval: interval,
+		now:      time.Now,
+		results:  map[types.NamespacedName]probeResult{},
+	}
+}
+
+// Probe sends a request for each configured bucket of the object storage
+// of the stack with the credentials of the storage secret, verifying the
+// endpoint with the CA bundle if set. It returns a ProbeError for the first
+// bucket that cannot be accessed. Only S3 compatible object storage is
+// probed, any other type is assumed to be reachable.
+func (p *Prober) Probe(ctx context.Context, stack *lokiv1beta1.LokiStack, storage *manifests.ObjectStorage, s *corev1.Secret, caBundle []byte) error {
+	key := client.ObjectKeyFromObject(stack)
+	spec := stack.Spec.Storage
+	fingerprint := fmt.Sprintf("%s;%s;%s;%t", storage.SecretSHA1, storage.CABundleSHA1, spec.AddressingStyle, spec.Insecure)
+
+	p.mu.Lock()
+	last, ok := p.results[key]
+	p.mu.Unlock()
+
+	now := p.now()
+	if ok && last.fingerprint == fingerprint && now.Sub(last.probedAt) < p.interval {
+		return last.err
+	}
+
+	err := probe(ctx, spec, storage, s, caBundle)
+
+	p.mu.Lock()
+	p.results[key] = probeResult{fingerprint: fingerprint, probedAt: now, err: err}
+	p.mu.Unlock()
+
+	return err
+}
+
+// Forget drops the last probe result of the lokistack.
+func (p *Prober) Forget(key types.NamespacedName) {
+	p.mu.Lock()
+	delete(p.results, key)
+	p.mu.Unlock()
+}
+
+func probe(ctx context.Context, spec lokiv1beta1.ObjectStorageSpec, storage *manifests.ObjectStorage, s *corev1.Secret, caBundle []byte) error {
+	if storage.SharedStore != lokiv1beta1.ObjectStorageSecretS3 || storage.S3 == nil {
+		return nil
+	}
+
+	c, err := newS3Client(s3Options{
+		Endpoint:        storage.S3.Endpoint,
+		Region:          storage.S3.Region,
+		AccessKeyID:     string(s.Data["access_key_id"]),
+		SecretAccessKey: string(s.Data["access_key_secret"]),
+		VirtualHosted:   spec.AddressingStyle == lokiv1beta1.ObjectStorageAddressingVirtualHosted,
+		Insecure:        spec.Insecure,
+		CABundle:        caBundle,
+	})
+	if err != nil {
+		return &ProbeError{Reason: lokiv1beta1.ReasonUnreachableObjectStorage, Bucket: storage.S3.Buckets, Err: err}
+	}
+
+	for _, bucket := range strings.Split(storage.S3.Buckets, ",") {
+		if err = c.HeadBucket(ctx, bucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
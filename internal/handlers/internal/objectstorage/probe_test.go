@@ -0,0 +1,257 @@
+package objectstorage
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newS3StandIn returns a server answering HEAD bucket requests with the
+// status of the bucket or 404 if unknown. It counts the received requests.
+func newS3StandIn(t *testing.T, buckets map[string]int, count *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+
+		assert.Equal(t, http.MethodHead, r.Method)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key-id/"))
+		assert.NotEmpty(t, r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, emptyPayloadHash, r.Header.Get("X-Amz-Content-Sha256"))
+
+		code, ok := buckets[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			code = http.StatusNotFound
+		}
+		w.WriteHeader(code)
+	}))
+}
+
+func newStack() *lokiv1beta1.LokiStack {
+	return &lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack",
+			Namespace: "ns",
+		},
+	}
+}
+
+func s3Storage(endpoint, buckets string) (*manifests.ObjectStorage, *corev1.Secret) {
+	s := &corev1.Secret{
+		Data: map[string][]byte{
+			"endpoint":          []byte(endpoint),
+			"bucketnames":       []byte(buckets),
+			"access_key_id":     []byte("key-id"),
+			"access_key_secret": []byte("key-secret"),
+		},
+	}
+	storage := &manifests.ObjectStorage{
+		SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+		S3: &manifests.S3StorageConfig{
+			Endpoint: endpoint,
+			Buckets:  buckets,
+		},
+		SecretSHA1: endpoint + buckets,
+	}
+	return storage, s
+}
+
+func TestProbe_S3(t *testing.T) {
+	var count int32
+	srv := newS3StandIn(t, map[string]int{
+		"ok":     http.StatusOK,
+		"denied": http.StatusForbidden,
+		"broken": http.StatusInternalServerError,
+	}, &count)
+	defer srv.Close()
+
+	table := []struct {
+		buckets string
+		reason  lokiv1beta1.LokiStackConditionReason
+	}{
+		{buckets: "ok"},
+		{buckets: "ok,ok"},
+		{buckets: "ok,missing", reason: lokiv1beta1.ReasonMissingObjectStorageBucket},
+		{buckets: "denied", reason: lokiv1beta1.ReasonObjectStorageAccessDenied},
+		{buckets: "broken", reason: lokiv1beta1.ReasonUnreachableObjectStorage},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.buckets, func(t *testing.T) {
+			storage, s := s3Storage(srv.URL, tc.buckets)
+			p := NewProber(time.Minute)
+
+			err := p.Probe(context.TODO(), newStack(), storage, s, nil)
+			if tc.reason == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var pe *ProbeError
+			require.True(t, errors.As(err, &pe))
+			require.Equal(t, tc.reason, pe.Reason)
+		})
+	}
+}
+
+func TestProbe_S3_UnreachableEndpoint(t *testing.T) {
+	var count int32
+	srv := newS3StandIn(t, nil, &count)
+	srv.Close()
+
+	storage, s := s3Storage(srv.URL, "bucket")
+	p := NewProber(time.Minute)
+
+	err := p.Probe(context.TODO(), newStack(), storage, s, nil)
+
+	var pe *ProbeError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, lokiv1beta1.ReasonUnreachableObjectStorage, pe.Reason)
+	require.Equal(t, "bucket", pe.Bucket)
+}
+
+func TestProbe_S3_CABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	storage, s := s3Storage(srv.URL, "bucket")
+
+	// The self-signed server certificate is not trusted by default
+	err := NewProber(time.Minute).Probe(context.TODO(), newStack(), storage, s, nil)
+
+	var pe *ProbeError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, lokiv1beta1.ReasonUnreachableObjectStorage, pe.Reason)
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	err = NewProber(time.Minute).Probe(context.TODO(), newStack(), storage, s, caBundle)
+	require.NoError(t, err)
+}
+
+func TestProbe_S3_Insecure(t *testing.T) {
+	var count int32
+	srv := newS3StandIn(t, map[string]int{"bucket": http.StatusOK}, &count)
+	defer srv.Close()
+
+	storage, s := s3Storage(strings.TrimPrefix(srv.URL, "http://"), "bucket")
+	stack := newStack()
+	stack.Spec.Storage.Insecure = true
+
+	err := NewProber(time.Minute).Probe(context.TODO(), stack, storage, s, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&count))
+}
+
+func TestProbe_SkipsNonS3Storage(t *testing.T) {
+	storage := &manifests.ObjectStorage{
+		SharedStore: lokiv1beta1.ObjectStorageSecretGCS,
+		GCS:         &manifests.GCSStorageConfig{Bucket: "bucket"},
+	}
+	p := NewProber(time.Minute)
+
+	err := p.Probe(context.TODO(), newStack(), storage, &corev1.Secret{}, nil)
+	require.NoError(t, err)
+}
+
+func TestProbe_RateLimited(t *testing.T) {
+	var count int32
+	srv := newS3StandIn(t, nil, &count)
+	defer srv.Close()
+
+	now := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProber(time.Minute)
+	p.now = func() time.Time { return now }
+
+	stack := newStack()
+	storage, s := s3Storage(srv.URL, "missing")
+
+	// First probe sends a request
+	err := p.Probe(context.TODO(), stack, storage, s, nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&count))
+
+	// Probes within the interval return the last result
+	now = now.Add(30 * time.Second)
+	require.Equal(t, err, p.Probe(context.TODO(), stack, storage, s, nil))
+	require.EqualValues(t, 1, atomic.LoadInt32(&count))
+
+	// Secret changes are probed immediately
+	storage.SecretSHA1 = "changed"
+	require.Error(t, p.Probe(context.TODO(), stack, storage, s, nil))
+	require.EqualValues(t, 2, atomic.LoadInt32(&count))
+
+	// Changes of the connection settings are probed immediately
+	stack.Spec.Storage.Insecure = true
+	require.Error(t, p.Probe(context.TODO(), stack, storage, s, nil))
+	require.EqualValues(t, 3, atomic.LoadInt32(&count))
+
+	// Probes after the interval send a request again
+	now = now.Add(time.Minute)
+	require.Error(t, p.Probe(context.TODO(), stack, storage, s, nil))
+	require.EqualValues(t, 4, atomic.LoadInt32(&count))
+
+	// Forgotten lokistacks are probed immediately
+	p.Forget(client.ObjectKeyFromObject(stack))
+	require.Error(t, p.Probe(context.TODO(), stack, storage, s, nil))
+	require.EqualValues(t, 5, atomic.LoadInt32(&count))
+}
+
+func TestS3Client_Sign(t *testing.T) {
+	c, err := newS3Client(s3Options{
+		Endpoint:        "s3://s3.eu-central-1.amazonaws.com",
+		Region:          "eu-central-1",
+		AccessKeyID:     "key-id",
+		SecretAccessKey: "key-secret",
+	})
+	require.NoError(t, err)
+	c.now = func() time.Time { return time.Date(2021, 11, 1, 12, 30, 0, 0, time.UTC) }
+
+	require.Equal(t, "https", c.endpoint.Scheme)
+
+	req, err := http.NewRequest(http.MethodHead, "https://s3.eu-central-1.amazonaws.com/bucket", nil)
+	require.NoError(t, err)
+	c.sign(req)
+
+	require.Equal(t, "20211101T123000Z", req.Header.Get("X-Amz-Date"))
+	require.True(t, strings.HasPrefix(req.Header.Get("Authorization"),
+		"AWS4-HMAC-SHA256 Credential=key-id/20211101/eu-central-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestS3Client_HeadBucket_VirtualHosted(t *testing.T) {
+	c, err := newS3Client(s3Options{
+		Endpoint:      "https://s3.eu-central-1.amazonaws.com",
+		VirtualHosted: true,
+	})
+	require.NoError(t, err)
+
+	var got string
+	c.client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.URL.String()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	require.NoError(t, c.HeadBucket(context.TODO(), "bucket"))
+	require.Equal(t, "https://bucket.s3.eu-central-1.amazonaws.com/", got)
+}
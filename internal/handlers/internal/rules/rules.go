@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// List returns a slice of AlertingRules and a slice of RecordingRules for the given spec or an error.
+// Rules are selected by the rules label selector from:
+// - the LokiStack namespace only, if no namespace selector is given.
+// - all namespaces matching the namespace selector otherwise.
+func List(ctx context.Context, k k8s.Client, stackNs string, rs *lokiv1beta1.RulesSpec) ([]lokiv1beta1.AlertingRule, []lokiv1beta1.RecordingRule, error) {
+	nsl, err := selectRulesNamespaces(ctx, k, stackNs, rs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ar, err := selectAlertingRules(ctx, k, nsl, rs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rr, err := selectRecordingRules(ctx, k, nsl, rs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ar, rr, nil
+}
+
+func selectRulesNamespaces(ctx context.Context, k k8s.Client, stackNs string, rs *lokiv1beta1.RulesSpec) ([]string, error) {
+	if rs.NamespaceSelector == nil {
+		return []string{stackNs}, nil
+	}
+
+	nsSelector, err := metav1.LabelSelectorAsSelector(rs.NamespaceSelector)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create namespace selector for rules")
+	}
+
+	var nsList corev1.NamespaceList
+	err = k.List(ctx, &nsList, &client.MatchingLabelsSelector{Selector: nsSelector})
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to list namespaces for rules")
+	}
+
+	var ns []string
+	for _, item := range nsList.Items {
+		ns = append(ns, item.GetName())
+	}
+
+	return ns, nil
+}
+
+func selectAlertingRules(ctx context.Context, k k8s.Client, namespaces []string, rs *lokiv1beta1.RulesSpec) ([]lokiv1beta1.AlertingRule, error) {
+	sel, err := rulesSelector(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []lokiv1beta1.AlertingRule
+	for _, ns := range namespaces {
+		var list lokiv1beta1.AlertingRuleList
+		err := k.List(ctx, &list, client.InNamespace(ns), &client.MatchingLabelsSelector{Selector: sel})
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to list alerting rules", "namespace", ns)
+		}
+
+		rules = append(rules, list.Items...)
+	}
+
+	return rules, nil
+}
+
+func selectRecordingRules(ctx context.Context, k k8s.Client, namespaces []string, rs *lokiv1beta1.RulesSpec) ([]lokiv1beta1.RecordingRule, error) {
+	sel, err := rulesSelector(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []lokiv1beta1.RecordingRule
+	for _, ns := range namespaces {
+		var list lokiv1beta1.RecordingRuleList
+		err := k.List(ctx, &list, client.InNamespace(ns), &client.MatchingLabelsSelector{Selector: sel})
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to list recording rules", "namespace", ns)
+		}
+
+		rules = append(rules, list.Items...)
+	}
+
+	return rules, nil
+}
+
+func rulesSelector(rs *lokiv1beta1.RulesSpec) (labels.Selector, error) {
+	if rs.Selector == nil {
+		return labels.Everything(), nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(rs.Selector)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create rules selector")
+	}
+
+	return sel, nil
+}
@@ -0,0 +1,82 @@
+package rules_test
+
+import (
+	"context"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/rules"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestList_WithoutNamespaceSelector_ListsStackNamespaceOnly(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+
+	var namespaces []string
+	k.ListStub = func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		lo := &client.ListOptions{}
+		lo.ApplyOptions(opts)
+		namespaces = append(namespaces, lo.Namespace)
+		return nil
+	}
+
+	ar, rr, err := rules.List(context.TODO(), k, "stack-ns", &lokiv1beta1.RulesSpec{Enabled: true})
+	require.NoError(t, err)
+	require.Empty(t, ar)
+	require.Empty(t, rr)
+	require.Equal(t, []string{"stack-ns", "stack-ns"}, namespaces)
+}
+
+func TestList_WithNamespaceSelector_ListsRulesFromSelectedNamespaces(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+
+	rs := &lokiv1beta1.RulesSpec{
+		Enabled: true,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "a"},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		lo := &client.ListOptions{}
+		lo.ApplyOptions(opts)
+
+		switch list.(type) {
+		case *corev1.NamespaceList:
+			k.SetClientObjectList(list, &corev1.NamespaceList{
+				Items: []corev1.Namespace{
+					{ObjectMeta: metav1.ObjectMeta{Name: "ns-a"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "ns-b"}},
+				},
+			})
+		case *lokiv1beta1.AlertingRuleList:
+			k.SetClientObjectList(list, &lokiv1beta1.AlertingRuleList{
+				Items: []lokiv1beta1.AlertingRule{
+					{ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: lo.Namespace}},
+				},
+			})
+		case *lokiv1beta1.RecordingRuleList:
+			if lo.Namespace == "ns-b" {
+				k.SetClientObjectList(list, &lokiv1beta1.RecordingRuleList{
+					Items: []lokiv1beta1.RecordingRule{
+						{ObjectMeta: metav1.ObjectMeta{Name: "records", Namespace: lo.Namespace}},
+					},
+				})
+			}
+		}
+		return nil
+	}
+
+	ar, rr, err := rules.List(context.TODO(), k, "stack-ns", rs)
+	require.NoError(t, err)
+	require.Len(t, ar, 2)
+	require.Equal(t, "ns-a", ar[0].Namespace)
+	require.Equal(t, "ns-b", ar[1].Namespace)
+	require.Len(t, rr, 1)
+	require.Equal(t, "ns-b", rr[0].Namespace)
+}
@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+
+	"github.com/prometheus/common/model"
+)
+
+// ValidateAlertingRule validates the alerting rule groups for
+// unique group names, valid durations and valid alert names and labels.
+func ValidateAlertingRule(r lokiv1beta1.AlertingRule) error {
+	if err := validateTenantID(r.Spec.TenantID); err != nil {
+		return err
+	}
+
+	groups := map[string]bool{}
+	for _, g := range r.Spec.Groups {
+		if groups[g.Name] {
+			return kverrors.New("duplicate group name", "group", g.Name)
+		}
+		groups[g.Name] = true
+
+		if err := validateDuration(g.Interval); err != nil {
+			return kverrors.Wrap(err, "invalid group interval", "group", g.Name)
+		}
+
+		for _, rule := range g.Rules {
+			if rule.Alert == "" || !model.LabelValue(rule.Alert).IsValid() {
+				return kverrors.New("invalid alert name", "group", g.Name, "alert", rule.Alert)
+			}
+
+			if strings.TrimSpace(rule.Expr) == "" {
+				return kverrors.New("missing alert expression", "group", g.Name, "alert", rule.Alert)
+			}
+
+			if err := validateDuration(rule.For); err != nil {
+				return kverrors.Wrap(err, "invalid alert for duration", "group", g.Name, "alert", rule.Alert)
+			}
+
+			if err := validateLabels(rule.Labels); err != nil {
+				return kverrors.Wrap(err, "invalid alert labels", "group", g.Name, "alert", rule.Alert)
+			}
+
+			if err := validateLabels(rule.Annotations); err != nil {
+				return kverrors.Wrap(err, "invalid alert annotations", "group", g.Name, "alert", rule.Alert)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateRecordingRule validates the recording rule groups for
+// unique group names, valid durations and valid metric names and labels.
+func ValidateRecordingRule(r lokiv1beta1.RecordingRule) error {
+	if err := validateTenantID(r.Spec.TenantID); err != nil {
+		return err
+	}
+
+	groups := map[string]bool{}
+	for _, g := range r.Spec.Groups {
+		if groups[g.Name] {
+			return kverrors.New("duplicate group name", "group", g.Name)
+		}
+		groups[g.Name] = true
+
+		if err := validateDuration(g.Interval); err != nil {
+			return kverrors.Wrap(err, "invalid group interval", "group", g.Name)
+		}
+
+		for _, rule := range g.Rules {
+			if !model.IsValidMetricName(model.LabelValue(rule.Record)) {
+				return kverrors.New("invalid metric name", "group", g.Name, "record", rule.Record)
+			}
+
+			if strings.TrimSpace(rule.Expr) == "" {
+				return kverrors.New("missing record expression", "group", g.Name, "record", rule.Record)
+			}
+
+			if err := validateLabels(rule.Labels); err != nil {
+				return kverrors.Wrap(err, "invalid record labels", "group", g.Name, "record", rule.Record)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTenantID(id string) error {
+	// The tenant ID is used as a directory name
+	// in the ruler local rules storage.
+	if id == "" || id == "." || id == ".." || strings.Contains(id, "/") {
+		return kverrors.New("invalid tenant id", "tenantID", id)
+	}
+
+	return nil
+}
+
+func validateDuration(d lokiv1beta1.PrometheusDuration) error {
+	if d == "" {
+		return nil
+	}
+
+	_, err := model.ParseDuration(string(d))
+	return err
+}
+
+func validateLabels(labels map[string]string) error {
+	for name, value := range labels {
+		if !model.LabelName(name).IsValid() {
+			return kverrors.New("invalid label name", "name", name)
+		}
+
+		if !model.LabelValue(value).IsValid() {
+			return kverrors.New("invalid label value", "name", name, "value", value)
+		}
+	}
+
+	return nil
+}
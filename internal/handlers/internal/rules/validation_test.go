@@ -0,0 +1,192 @@
+package rules_test
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAlertingRule(t *testing.T) {
+	type test struct {
+		name    string
+		spec    lokiv1beta1.AlertingRuleSpec
+		wantErr bool
+	}
+
+	table := []test{
+		{
+			name: "valid rule",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.AlertingRuleGroup{
+					{
+						Name:     "first",
+						Interval: "1m",
+						Rules: []*lokiv1beta1.AlertingRuleGroupSpec{
+							{
+								Alert: "HighErrorRate",
+								Expr:  `sum(rate({app="foo"} |= "error" [5m])) > 1`,
+								For:   "10m",
+								Labels: map[string]string{
+									"severity": "page",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid tenant id",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "../application",
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate group names",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.AlertingRuleGroup{
+					{Name: "first"},
+					{Name: "first"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid interval",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.AlertingRuleGroup{
+					{Name: "first", Interval: "1mm"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing expression",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.AlertingRuleGroup{
+					{
+						Name: "first",
+						Rules: []*lokiv1beta1.AlertingRuleGroupSpec{
+							{Alert: "HighErrorRate"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid label name",
+			spec: lokiv1beta1.AlertingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.AlertingRuleGroup{
+					{
+						Name: "first",
+						Rules: []*lokiv1beta1.AlertingRuleGroupSpec{
+							{
+								Alert: "HighErrorRate",
+								Expr:  `sum(rate({app="foo"}[5m])) > 1`,
+								Labels: map[string]string{
+									"invalid-name": "page",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := rules.ValidateAlertingRule(lokiv1beta1.AlertingRule{Spec: tst.spec})
+			if tst.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRecordingRule(t *testing.T) {
+	type test struct {
+		name    string
+		spec    lokiv1beta1.RecordingRuleSpec
+		wantErr bool
+	}
+
+	table := []test{
+		{
+			name: "valid rule",
+			spec: lokiv1beta1.RecordingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.RecordingRuleGroup{
+					{
+						Name:     "first",
+						Interval: "1m",
+						Rules: []*lokiv1beta1.RecordingRuleGroupSpec{
+							{
+								Record: "app:requests:rate5m",
+								Expr:   `sum(rate({app="foo"}[5m]))`,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate group names",
+			spec: lokiv1beta1.RecordingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.RecordingRuleGroup{
+					{Name: "first"},
+					{Name: "first"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid metric name",
+			spec: lokiv1beta1.RecordingRuleSpec{
+				TenantID: "application",
+				Groups: []*lokiv1beta1.RecordingRuleGroup{
+					{
+						Name: "first",
+						Rules: []*lokiv1beta1.RecordingRuleGroupSpec{
+							{
+								Record: "app-requests",
+								Expr:   `sum(rate({app="foo"}[5m]))`,
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := rules.ValidateRecordingRule(lokiv1beta1.RecordingRule{Spec: tst.spec})
+			if tst.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,30 +1,142 @@
 package secrets
 
 import (
+	"crypto/sha1"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/manifests"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
+// bucketNameRegex matches S3 bucket names of 3 to 63 lowercase letters,
+// numbers, dots and hyphens beginning and ending with a letter or number.
+var bucketNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
 // Extract reads a k8s secret into a manifest object storage struct if valid.
-func Extract(s *corev1.Secret) (*manifests.ObjectStorage, error) {
+func Extract(s *corev1.Secret, t lokiv1beta1.ObjectStorageSecretType) (*manifests.ObjectStorage, error) {
+	var err error
+	storage := &manifests.ObjectStorage{
+		SharedStore: t,
+	}
+
+	switch t {
+	case lokiv1beta1.ObjectStorageSecretAzure:
+		storage.Azure, err = extractAzureConfigSecret(s)
+	case lokiv1beta1.ObjectStorageSecretGCS:
+		storage.GCS, err = extractGCSConfigSecret(s)
+	case lokiv1beta1.ObjectStorageSecretS3, "":
+		storage.SharedStore = lokiv1beta1.ObjectStorageSecretS3
+		storage.S3, err = extractS3ConfigSecret(s)
+	case lokiv1beta1.ObjectStorageSecretSwift:
+		storage.Swift, err = extractSwiftConfigSecret(s)
+	default:
+		return nil, kverrors.New("unknown secret type", "type", t)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	storage.SecretSHA1, err = hashSecretData(s)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to hash secret data")
+	}
+
+	return storage, nil
+}
+
+// hashSecretData returns a stable hash over the secret data
+// independently of the map iteration order.
+func hashSecretData(s *corev1.Secret) (string, error) {
+	keys := make([]string, 0, len(s.Data))
+	for k := range s.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha1.New()
+	for _, k := range keys {
+		if _, err := h.Write([]byte(k)); err != nil {
+			return "", err
+		}
+		if _, err := h.Write([]byte(",")); err != nil {
+			return "", err
+		}
+		if _, err := h.Write(s.Data[k]); err != nil {
+			return "", err
+		}
+		if _, err := h.Write([]byte(";")); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func extractAzureConfigSecret(s *corev1.Secret) (*manifests.AzureStorageConfig, error) {
+	// Extract and validate mandatory fields
+	env, ok := s.Data["environment"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "environment")
+	}
+	container, ok := s.Data["container"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "container")
+	}
+	if _, ok = s.Data["account_name"]; !ok {
+		return nil, kverrors.New("missing secret field", "field", "account_name")
+	}
+	if _, ok = s.Data["account_key"]; !ok {
+		return nil, kverrors.New("missing secret field", "field", "account_key")
+	}
+
+	return &manifests.AzureStorageConfig{
+		Env:       string(env),
+		Container: string(container),
+	}, nil
+}
+
+func extractGCSConfigSecret(s *corev1.Secret) (*manifests.GCSStorageConfig, error) {
+	// Extract and validate mandatory fields
+	bucket, ok := s.Data["bucketname"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "bucketname")
+	}
+
+	// Check if google authentication credentials is provided
+	if _, ok := s.Data[manifests.GCSFileName]; !ok {
+		return nil, kverrors.New("missing google authentication credentials", "field", manifests.GCSFileName)
+	}
+
+	return &manifests.GCSStorageConfig{
+		Bucket: string(bucket),
+	}, nil
+}
+
+func extractS3ConfigSecret(s *corev1.Secret) (*manifests.S3StorageConfig, error) {
 	// Extract and validate mandatory fields
 	endpoint, ok := s.Data["endpoint"]
 	if !ok {
 		return nil, kverrors.New("missing secret field", "field", "endpoint")
 	}
-	buckets, ok := s.Data["bucketnames"]
+	bucketnames, ok := s.Data["bucketnames"]
 	if !ok {
 		return nil, kverrors.New("missing secret field", "field", "bucketnames")
 	}
-	// TODO buckets are comma-separated list
-	id, ok := s.Data["access_key_id"]
-	if !ok {
+	buckets, err := parseBuckets(string(bucketnames))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok = s.Data["access_key_id"]; !ok {
 		return nil, kverrors.New("missing secret field", "field", "access_key_id")
 	}
-	secret, ok := s.Data["access_key_secret"]
-	if !ok {
+	if _, ok = s.Data["access_key_secret"]; !ok {
 		return nil, kverrors.New("missing secret field", "field", "access_key_secret")
 	}
 
@@ -34,12 +146,94 @@ func Extract(s *corev1.Secret) (*manifests.ObjectStorage, error) {
 		region = []byte("")
 	}
 
-	return &manifests.ObjectStorage{
-		Endpoint:        string(endpoint),
-		Buckets:         string(buckets),
-		AccessKeyID:     string(id),
-		AccessKeySecret: string(secret),
-		Region:          string(region),
+	return &manifests.S3StorageConfig{
+		Endpoint: string(endpoint),
+		Buckets:  strings.Join(buckets, ","),
+		Region:   string(region),
+	}, nil
+}
+
+// parseBuckets splits the comma-separated list of bucket names and
+// validates each name against the S3 bucket naming rules.
+func parseBuckets(list string) ([]string, error) {
+	var buckets []string
+	seen := map[string]bool{}
+
+	for _, b := range strings.Split(list, ",") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			return nil, kverrors.New("empty bucket name in secret field", "field", "bucketnames")
+		}
+		if !bucketNameRegex.MatchString(b) || strings.Contains(b, "..") {
+			return nil, kverrors.New("invalid bucket name in secret field", "field", "bucketnames", "bucket", b)
+		}
+		if seen[b] {
+			return nil, kverrors.New("duplicate bucket name in secret field", "field", "bucketnames", "bucket", b)
+		}
+		seen[b] = true
+		buckets = append(buckets, b)
+	}
+
+	return buckets, nil
+}
+
+func extractSwiftConfigSecret(s *corev1.Secret) (*manifests.SwiftStorageConfig, error) {
+	// Extract and validate mandatory fields
+	url, ok := s.Data["auth_url"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "auth_url")
+	}
+	if _, ok = s.Data["username"]; !ok {
+		return nil, kverrors.New("missing secret field", "field", "username")
+	}
+	userDomainName, ok := s.Data["user_domain_name"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "user_domain_name")
+	}
+	userDomainID, ok := s.Data["user_domain_id"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "user_domain_id")
+	}
+	userID, ok := s.Data["user_id"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "user_id")
+	}
+	if _, ok = s.Data["password"]; !ok {
+		return nil, kverrors.New("missing secret field", "field", "password")
+	}
+	domainID, ok := s.Data["domain_id"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "domain_id")
+	}
+	domainName, ok := s.Data["domain_name"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "domain_name")
+	}
+	containerName, ok := s.Data["container_name"]
+	if !ok {
+		return nil, kverrors.New("missing secret field", "field", "container_name")
+	}
+
+	// Extract and validate optional fields
+	projectID := s.Data["project_id"]
+	projectName := s.Data["project_name"]
+	projectDomainID := s.Data["project_domain_id"]
+	projectDomainName := s.Data["project_domain_name"]
+	region := s.Data["region"]
+
+	return &manifests.SwiftStorageConfig{
+		AuthURL:           string(url),
+		UserDomainName:    string(userDomainName),
+		UserDomainID:      string(userDomainID),
+		UserID:            string(userID),
+		DomainID:          string(domainID),
+		DomainName:        string(domainName),
+		ProjectID:         string(projectID),
+		ProjectName:       string(projectName),
+		ProjectDomainID:   string(projectDomainID),
+		ProjectDomainName: string(projectDomainName),
+		Region:            string(region),
+		Container:         string(containerName),
 	}, nil
 }
 
@@ -3,12 +3,13 @@ package secrets_test
 import (
 	"testing"
 
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/handlers/internal/secrets"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 )
 
-func TestExtract(t *testing.T) {
+func TestExtract_S3(t *testing.T) {
 	type test struct {
 		name    string
 		secret  *corev1.Secret
@@ -67,7 +68,7 @@ func TestExtract(t *testing.T) {
 		t.Run(tst.name, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := secrets.Extract(tst.secret)
+			_, err := secrets.Extract(tst.secret, lokiv1beta1.ObjectStorageSecretS3)
 			if !tst.wantErr {
 				require.NoError(t, err)
 			}
@@ -78,6 +79,258 @@ func TestExtract(t *testing.T) {
 	}
 }
 
+func TestExtract_S3_Buckets(t *testing.T) {
+	table := []struct {
+		bucketnames string
+		want        string
+		wantErr     bool
+	}{
+		{bucketnames: "this", want: "this"},
+		{bucketnames: "this, that ,loki.logs-1", want: "this,that,loki.logs-1"},
+		{bucketnames: "", wantErr: true},
+		{bucketnames: "this,,that", wantErr: true},
+		{bucketnames: "this,", wantErr: true},
+		{bucketnames: "this,this", wantErr: true},
+		{bucketnames: "ab", wantErr: true},
+		{bucketnames: "Upper", wantErr: true},
+		{bucketnames: "under_score", wantErr: true},
+		{bucketnames: "-dash", wantErr: true},
+		{bucketnames: "dot.", wantErr: true},
+		{bucketnames: "two..dots", wantErr: true},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.bucketnames, func(t *testing.T) {
+			t.Parallel()
+
+			s := &corev1.Secret{
+				Data: map[string][]byte{
+					"endpoint":          []byte("here"),
+					"bucketnames":       []byte(tst.bucketnames),
+					"access_key_id":     []byte("id"),
+					"access_key_secret": []byte("secret"),
+				},
+			}
+
+			storage, err := secrets.Extract(s, lokiv1beta1.ObjectStorageSecretS3)
+			if tst.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tst.want, storage.S3.Buckets)
+		})
+	}
+}
+
+func TestExtract_Azure(t *testing.T) {
+	type test struct {
+		name    string
+		secret  *corev1.Secret
+		wantErr bool
+	}
+	table := []test{
+		{
+			name:    "missing environment",
+			secret:  &corev1.Secret{},
+			wantErr: true,
+		},
+		{
+			name: "missing container",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"environment": []byte("here"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing account_name",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"environment": []byte("here"),
+					"container":   []byte("this,that"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing account_key",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"environment":  []byte("here"),
+					"container":    []byte("this,that"),
+					"account_name": []byte("id"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "all set",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"environment":  []byte("here"),
+					"container":    []byte("this,that"),
+					"account_name": []byte("id"),
+					"account_key":  []byte("secret"),
+				},
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := secrets.Extract(tst.secret, lokiv1beta1.ObjectStorageSecretAzure)
+			if !tst.wantErr {
+				require.NoError(t, err)
+			}
+			if tst.wantErr {
+				require.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestExtract_GCS(t *testing.T) {
+	type test struct {
+		name    string
+		secret  *corev1.Secret
+		wantErr bool
+	}
+	table := []test{
+		{
+			name:    "missing bucketname",
+			secret:  &corev1.Secret{},
+			wantErr: true,
+		},
+		{
+			name: "missing key.json",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"bucketname": []byte("here"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "all set",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"bucketname": []byte("here"),
+					"key.json":   []byte("{\"type\": \"service_account\"}"),
+				},
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := secrets.Extract(tst.secret, lokiv1beta1.ObjectStorageSecretGCS)
+			if !tst.wantErr {
+				require.NoError(t, err)
+			}
+			if tst.wantErr {
+				require.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestExtract_Swift(t *testing.T) {
+	all := map[string][]byte{
+		"auth_url":         []byte("here"),
+		"username":         []byte("this,that"),
+		"user_domain_name": []byte("id"),
+		"user_domain_id":   []byte("secret"),
+		"user_id":          []byte("there"),
+		"password":         []byte("cred1"),
+		"domain_id":        []byte("text"),
+		"domain_name":      []byte("where"),
+		"container_name":   []byte("then"),
+	}
+
+	type test struct {
+		name    string
+		secret  *corev1.Secret
+		wantErr bool
+	}
+	table := []test{
+		{
+			name:    "missing auth_url",
+			secret:  &corev1.Secret{},
+			wantErr: true,
+		},
+		{
+			name:   "all set",
+			secret: &corev1.Secret{Data: all},
+		},
+	}
+
+	// One case per mandatory field missing
+	for key := range all {
+		data := map[string][]byte{}
+		for k, v := range all {
+			if k != key {
+				data[k] = v
+			}
+		}
+
+		table = append(table, test{
+			name:    "missing " + key,
+			secret:  &corev1.Secret{Data: data},
+			wantErr: true,
+		})
+	}
+
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := secrets.Extract(tst.secret, lokiv1beta1.ObjectStorageSecretSwift)
+			if !tst.wantErr {
+				require.NoError(t, err)
+			}
+			if tst.wantErr {
+				require.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestExtract_SecretHashChangesWithContents(t *testing.T) {
+	s := &corev1.Secret{
+		Data: map[string][]byte{
+			"endpoint":          []byte("here"),
+			"bucketnames":       []byte("this,that"),
+			"access_key_id":     []byte("id"),
+			"access_key_secret": []byte("secret"),
+		},
+	}
+
+	first, err := secrets.Extract(s, lokiv1beta1.ObjectStorageSecretS3)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.SecretSHA1)
+
+	again, err := secrets.Extract(s, lokiv1beta1.ObjectStorageSecretS3)
+	require.NoError(t, err)
+	require.Equal(t, first.SecretSHA1, again.SecretSHA1)
+
+	s.Data["access_key_secret"] = []byte("rotated")
+	rotated, err := secrets.Extract(s, lokiv1beta1.ObjectStorageSecretS3)
+	require.NoError(t, err)
+	require.NotEqual(t, first.SecretSHA1, rotated.SecretSHA1)
+}
+
+func TestExtract_UnknownType(t *testing.T) {
+	_, err := secrets.Extract(&corev1.Secret{}, "unknown")
+	require.Error(t, err)
+}
+
 func TestExtractGatewaySecret(t *testing.T) {
 	type test struct {
 		name       string
@@ -0,0 +1,210 @@
+package statefulsets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/go-logr/logr"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UnsupportedChangeError is returned for changes of the immutable fields of
+// a statefulset that cannot be applied to the existing persistent volume claims.
+type UnsupportedChangeError struct {
+	StatefulSet string
+	Reason      string
+}
+
+func (e *UnsupportedChangeError) Error() string {
+	return fmt.Sprintf("statefulset %s: %s", e.StatefulSet, e.Reason)
+}
+
+// Prepare applies changes of the immutable fields of the desired statefulsets,
+// e.g. grown volume claim templates after a size upgrade, to the existing ones:
+// The persistent volume claims are expanded first, then the statefulset is
+// deleted while orphaning its pods to be recreated by the next reconciliation.
+// It returns the objects to create or update, i.e. without the statefulsets
+// being recreated, or an UnsupportedChangeError.
+func Prepare(ctx context.Context, ll logr.Logger, k k8s.Client, namespace string, objs []client.Object) ([]client.Object, error) {
+	var apply []client.Object
+
+	for _, obj := range objs {
+		desired, ok := obj.(*appsv1.StatefulSet)
+		if !ok {
+			apply = append(apply, obj)
+			continue
+		}
+
+		var existing appsv1.StatefulSet
+		key := client.ObjectKey{Name: desired.Name, Namespace: namespace}
+		if err := k.Get(ctx, key, &existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				apply = append(apply, obj)
+				continue
+			}
+			return nil, kverrors.Wrap(err, "failed to lookup statefulset", "name", key)
+		}
+
+		if existing.CreationTimestamp.IsZero() {
+			// Objects without creation timestamp have not been persisted yet.
+			apply = append(apply, obj)
+			continue
+		}
+
+		if !existing.DeletionTimestamp.IsZero() {
+			// Wait for the orphaning deletion to complete.
+			continue
+		}
+
+		changed, grown, err := compare(&existing, desired)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			apply = append(apply, obj)
+			continue
+		}
+
+		l := ll.WithValues("statefulset", desired.Name)
+
+		if len(grown) > 0 {
+			if err := expandClaims(ctx, l, k, &existing, grown); err != nil {
+				return nil, err
+			}
+		}
+
+		orphan := client.PropagationPolicy(metav1.DeletePropagationOrphan)
+		if err := k.Delete(ctx, &existing, orphan); client.IgnoreNotFound(err) != nil {
+			return nil, kverrors.Wrap(err, "failed to delete statefulset for recreation", "name", key)
+		}
+		l.Info("Statefulset has been deleted for recreation, orphaning its pods")
+	}
+
+	return apply, nil
+}
+
+// compare returns whether any immutable field of the existing statefulset
+// differs from the desired one and the desired storage size of the grown
+// volume claim templates by name.
+func compare(existing, desired *appsv1.StatefulSet) (bool, map[string]resource.Quantity, error) {
+	unsupported := func(format string, args ...interface{}) error {
+		return &UnsupportedChangeError{StatefulSet: desired.Name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	et, dt := existing.Spec.VolumeClaimTemplates, desired.Spec.VolumeClaimTemplates
+	if len(et) != len(dt) {
+		return false, nil, unsupported("volume claim templates cannot be added or removed")
+	}
+
+	changed := existing.Spec.PodManagementPolicy != desired.Spec.PodManagementPolicy
+	grown := map[string]resource.Quantity{}
+
+	for i := range dt {
+		e, d := et[i], dt[i]
+		if e.Name != d.Name {
+			return false, nil, unsupported("volume claim template %s cannot be renamed to %s", e.Name, d.Name)
+		}
+		if !apiequality.Semantic.DeepEqual(e.Spec.StorageClassName, d.Spec.StorageClassName) {
+			return false, nil, unsupported("storage class of volume claim template %s cannot be changed", d.Name)
+		}
+		if !apiequality.Semantic.DeepEqual(e.Spec.AccessModes, d.Spec.AccessModes) {
+			return false, nil, unsupported("access modes of volume claim template %s cannot be changed", d.Name)
+		}
+
+		es, ds := e.Spec.Resources.Requests[corev1.ResourceStorage], d.Spec.Resources.Requests[corev1.ResourceStorage]
+		switch es.Cmp(ds) {
+		case 1:
+			return false, nil, unsupported("volume claim template %s cannot shrink from %s to %s", d.Name, es.String(), ds.String())
+		case -1:
+			grown[d.Name] = ds
+			changed = true
+		}
+
+		if !apiequality.Semantic.DeepEqual(e.Labels, d.Labels) {
+			changed = true
+		}
+	}
+
+	return changed, grown, nil
+}
+
+// expandClaims expands the persistent volume claims of all ordinals of the
+// statefulset including the ones retained after a scale-down.
+func expandClaims(ctx context.Context, ll logr.Logger, k k8s.Client, sts *appsv1.StatefulSet, grown map[string]resource.Quantity) error {
+	var expand []*corev1.PersistentVolumeClaim
+
+	for _, vct := range sts.Spec.VolumeClaimTemplates {
+		size, ok := grown[vct.Name]
+		if !ok {
+			continue
+		}
+
+		var pvcs corev1.PersistentVolumeClaimList
+		if err := k.List(ctx, &pvcs, client.InNamespace(sts.Namespace), client.MatchingLabels(vct.Labels)); err != nil {
+			return kverrors.Wrap(err, "failed to list persistent volume claims", "name", sts.Name)
+		}
+
+		prefix := fmt.Sprintf("%s-%s-", vct.Name, sts.Name)
+		for i := range pvcs.Items {
+			pvc := &pvcs.Items[i]
+			current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if !strings.HasPrefix(pvc.Name, prefix) || current.Cmp(size) >= 0 {
+				continue
+			}
+
+			ok, err := allowsExpansion(ctx, k, pvc.Spec.StorageClassName)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return &UnsupportedChangeError{
+					StatefulSet: sts.Name,
+					Reason:      fmt.Sprintf("storage class of persistent volume claim %s does not allow volume expansion", pvc.Name),
+				}
+			}
+
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			expand = append(expand, pvc)
+		}
+	}
+
+	// Expand only after all storage classes allow expansion to never leave claims half expanded.
+	for _, pvc := range expand {
+		if err := k.Update(ctx, pvc); err != nil {
+			return kverrors.Wrap(err, "failed to expand persistent volume claim", "name", pvc.Name)
+		}
+		ll.Info("Persistent volume claim has been expanded", "name", pvc.Name)
+	}
+
+	return nil
+}
+
+func allowsExpansion(ctx context.Context, k k8s.Client, name *string) (bool, error) {
+	if name == nil || *name == "" {
+		return false, nil
+	}
+
+	var sc storagev1.StorageClass
+	if err := k.Get(ctx, client.ObjectKey{Name: *name}, &sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, kverrors.Wrap(err, "failed to lookup storage class", "name", *name)
+	}
+
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
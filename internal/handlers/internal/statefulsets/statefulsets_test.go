@@ -0,0 +1,202 @@
+package statefulsets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ViaQ/logerr/log"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var labels = map[string]string{"loki.grafana.com/component": "ingester"}
+
+func statefulSet(storageClass, size string) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "loki-ingester-my-stack",
+			Namespace:         "some-ns",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            pointer.Int32Ptr(2),
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "storage", Labels: labels},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: pointer.StringPtr(storageClass),
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse(size),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func claim(name, storageClass, size string) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "some-ns", Labels: labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: pointer.StringPtr(storageClass),
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+}
+
+func newFakeClient(existing *appsv1.StatefulSet, pvcs ...corev1.PersistentVolumeClaim) *k8sfakes.FakeClient {
+	classes := map[string]*storagev1.StorageClass{
+		"expandable": {
+			ObjectMeta:           metav1.ObjectMeta{Name: "expandable"},
+			AllowVolumeExpansion: pointer.BoolPtr(true),
+		},
+		"fixed": {
+			ObjectMeta: metav1.ObjectMeta{Name: "fixed"},
+		},
+	}
+
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		switch object.(type) {
+		case *appsv1.StatefulSet:
+			if existing != nil && existing.Name == name.Name {
+				k.SetClientObject(object, existing)
+				return nil
+			}
+		case *storagev1.StorageClass:
+			if sc, ok := classes[name.Name]; ok {
+				k.SetClientObject(object, sc)
+				return nil
+			}
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, name.Name)
+	}
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		k.SetClientObjectList(l, &corev1.PersistentVolumeClaimList{Items: pvcs})
+		return nil
+	}
+	return k
+}
+
+func TestPrepare_WhenUnchanged_ApplyAll(t *testing.T) {
+	k := newFakeClient(statefulSet("expandable", "10Gi"))
+	objs := []client.Object{&corev1.ConfigMap{}, statefulSet("expandable", "10Gi")}
+
+	apply, err := Prepare(context.TODO(), log.WithName("test"), k, "some-ns", objs)
+	require.NoError(t, err)
+	require.Equal(t, objs, apply)
+	require.Zero(t, k.DeleteCallCount())
+}
+
+func TestPrepare_WhenStorageGrown_ExpandClaimsAndRecreate(t *testing.T) {
+	k := newFakeClient(statefulSet("expandable", "10Gi"),
+		claim("storage-loki-ingester-my-stack-0", "expandable", "10Gi"),
+		claim("storage-loki-ingester-my-stack-1", "expandable", "10Gi"),
+		claim("storage-loki-ingester-other-0", "expandable", "10Gi"),
+	)
+	cm := &corev1.ConfigMap{}
+
+	apply, err := Prepare(context.TODO(), log.WithName("test"), k, "some-ns", []client.Object{cm, statefulSet("expandable", "50Gi")})
+	require.NoError(t, err)
+	require.Equal(t, []client.Object{cm}, apply)
+
+	require.Equal(t, 2, k.UpdateCallCount())
+	for i := 0; i < k.UpdateCallCount(); i++ {
+		_, obj, _ := k.UpdateArgsForCall(i)
+		pvc := obj.(*corev1.PersistentVolumeClaim)
+		require.Equal(t, resource.MustParse("50Gi"), pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+	}
+
+	require.Equal(t, 1, k.DeleteCallCount())
+	_, obj, opts := k.DeleteArgsForCall(0)
+	require.Equal(t, "loki-ingester-my-stack", obj.GetName())
+
+	do := &client.DeleteOptions{}
+	do.ApplyOptions(opts)
+	require.Equal(t, metav1.DeletePropagationOrphan, *do.PropagationPolicy)
+}
+
+func TestPrepare_WhenPodManagementPolicyChanged_Recreate(t *testing.T) {
+	k := newFakeClient(statefulSet("fixed", "10Gi"))
+	desired := statefulSet("fixed", "10Gi")
+	desired.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+
+	apply, err := Prepare(context.TODO(), log.WithName("test"), k, "some-ns", []client.Object{desired})
+	require.NoError(t, err)
+	require.Empty(t, apply)
+	require.Zero(t, k.UpdateCallCount())
+	require.Equal(t, 1, k.DeleteCallCount())
+}
+
+func TestPrepare_WhenRecreationInProgress_SkipStatefulSet(t *testing.T) {
+	existing := statefulSet("expandable", "10Gi")
+	now := metav1.Now()
+	existing.DeletionTimestamp = &now
+	k := newFakeClient(existing)
+
+	apply, err := Prepare(context.TODO(), log.WithName("test"), k, "some-ns", []client.Object{statefulSet("expandable", "50Gi")})
+	require.NoError(t, err)
+	require.Empty(t, apply)
+	require.Zero(t, k.DeleteCallCount())
+}
+
+func TestPrepare_WhenUnsupportedChange_ReturnError(t *testing.T) {
+	type test struct {
+		name    string
+		desired *appsv1.StatefulSet
+		pvcs    []corev1.PersistentVolumeClaim
+	}
+	table := []test{
+		{
+			name:    "storage shrunk",
+			desired: statefulSet("expandable", "5Gi"),
+		},
+		{
+			name:    "storage class changed",
+			desired: statefulSet("other", "10Gi"),
+		},
+		{
+			name:    "storage class without expansion",
+			desired: statefulSet("expandable", "50Gi"),
+			pvcs: []corev1.PersistentVolumeClaim{
+				claim("storage-loki-ingester-my-stack-0", "expandable", "10Gi"),
+				claim("storage-loki-ingester-my-stack-1", "fixed", "10Gi"),
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			k := newFakeClient(statefulSet("expandable", "10Gi"), tst.pvcs...)
+
+			_, err := Prepare(context.TODO(), log.WithName("test"), k, "some-ns", []client.Object{tst.desired})
+
+			var uce *UnsupportedChangeError
+			require.True(t, errors.As(err, &uce))
+			require.Equal(t, "loki-ingester-my-stack", uce.StatefulSet)
+			require.Zero(t, k.UpdateCallCount())
+			require.Zero(t, k.DeleteCallCount())
+		})
+	}
+}
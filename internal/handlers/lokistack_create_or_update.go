@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha1"
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ViaQ/loki-operator/internal/manifests/openshift"
 
@@ -12,21 +15,45 @@ import (
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/external/k8s"
 	"github.com/ViaQ/loki-operator/internal/handlers/internal/gateway"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/ingesters"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/objectstorage"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/rules"
 	"github.com/ViaQ/loki-operator/internal/handlers/internal/secrets"
+	"github.com/ViaQ/loki-operator/internal/handlers/internal/statefulsets"
 	"github.com/ViaQ/loki-operator/internal/manifests"
 	"github.com/ViaQ/loki-operator/internal/metrics"
 	"github.com/ViaQ/loki-operator/internal/status"
+	"github.com/ViaQ/loki-operator/internal/validation"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ObjectStorageProbeInterval is the minimum interval between two probes
+// of the object storage of a LokiStack.
+const ObjectStorageProbeInterval = time.Minute
+
+var (
+	// ErrIngesterScaleDownInProgress is returned by CreateOrUpdateLokiStack while
+	// the ingesters removed by a scale-down are flushed and leave the ring.
+	ErrIngesterScaleDownInProgress = errors.New("ingester scale-down in progress")
+
+	// ErrObjectStorageUnavailable is returned by CreateOrUpdateLokiStack after
+	// applying the manifests when the object storage probe failed to access
+	// the configured buckets.
+	ErrObjectStorageUnavailable = errors.New("object storage unavailable")
+
+	objectStorageProber = objectstorage.NewProber(ObjectStorageProbeInterval)
 )
 
 // CreateOrUpdateLokiStack handles LokiStack create and update events.
-func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client, s *runtime.Scheme, flags manifests.FeatureFlags) error {
+func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client, s *runtime.Scheme, rec record.EventRecorder, flags manifests.FeatureFlags) error {
 	ll := log.WithValues("lokistack", req.NamespacedName, "event", "createOrUpdate")
 
 	var stack lokiv1beta1.LokiStack
@@ -39,6 +66,13 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		return kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
 	}
 
+	if !controllerutil.ContainsFinalizer(&stack, LokiStackFinalizer) {
+		controllerutil.AddFinalizer(&stack, LokiStackFinalizer)
+		if err := k.Update(ctx, &stack); err != nil {
+			return kverrors.Wrap(err, "failed to add lokistack finalizer", "name", req.NamespacedName)
+		}
+	}
+
 	img := os.Getenv(manifests.EnvRelatedImageLoki)
 	if img == "" {
 		img = manifests.DefaultContainerImage
@@ -49,24 +83,99 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		gwImg = manifests.DefaultLokiStackGatewayImage
 	}
 
-	var s3secret corev1.Secret
-	key := client.ObjectKey{Name: stack.Spec.Storage.Secret.Name, Namespace: stack.Namespace}
-	if err := k.Get(ctx, key, &s3secret); err != nil {
-		if apierrors.IsNotFound(err) {
+	memcachedImg := os.Getenv(manifests.EnvRelatedImageMemcached)
+	if memcachedImg == "" {
+		memcachedImg = manifests.DefaultMemcachedImage
+	}
+
+	var (
+		err                error
+		storageUnavailable bool
+	)
+	storage := &manifests.ObjectStorage{SharedStore: stack.Spec.Storage.Type}
+	if stack.Spec.Storage.Type != lokiv1beta1.ObjectStorageSecretFilesystem {
+		var storageSecret corev1.Secret
+		key := client.ObjectKey{Name: stack.Spec.Storage.Secret.Name, Namespace: stack.Namespace}
+		if key.Name == "" {
 			return status.SetDegradedCondition(ctx, k, req,
 				"Missing object storage secret",
 				lokiv1beta1.ReasonMissingObjectStorageSecret,
 			)
 		}
-		return kverrors.Wrap(err, "failed to lookup lokistack s3 secret", "name", key)
-	}
+		if err = k.Get(ctx, key, &storageSecret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return status.SetDegradedCondition(ctx, k, req,
+					"Missing object storage secret",
+					lokiv1beta1.ReasonMissingObjectStorageSecret,
+				)
+			}
+			return kverrors.Wrap(err, "failed to lookup lokistack storage secret", "name", key)
+		}
 
-	storage, err := secrets.Extract(&s3secret)
-	if err != nil {
-		return status.SetDegradedCondition(ctx, k, req,
-			"Invalid object storage secret contents",
-			lokiv1beta1.ReasonInvalidObjectStorageSecret,
-		)
+		storage, err = secrets.Extract(&storageSecret, stack.Spec.Storage.Type)
+		if err != nil {
+			return status.SetDegradedCondition(ctx, k, req,
+				"Invalid object storage secret contents",
+				lokiv1beta1.ReasonInvalidObjectStorageSecret,
+			)
+		}
+
+		var caBundle []byte
+		if tls := stack.Spec.Storage.TLS; tls != nil {
+			var cm corev1.ConfigMap
+			key := client.ObjectKey{Name: tls.CA, Namespace: stack.Namespace}
+			if err = k.Get(ctx, key, &cm); err != nil {
+				if apierrors.IsNotFound(err) {
+					return status.SetDegradedCondition(ctx, k, req,
+						"Missing object storage CA config map",
+						lokiv1beta1.ReasonMissingObjectStorageCAConfigMap,
+					)
+				}
+				return kverrors.Wrap(err, "failed to lookup lokistack object storage CA config map", "name", key)
+			}
+
+			data, ok := cm.Data[manifests.StorageCAKey(tls)]
+			if !ok {
+				return status.SetDegradedCondition(ctx, k, req,
+					fmt.Sprintf("Missing key %s in object storage CA config map", manifests.StorageCAKey(tls)),
+					lokiv1beta1.ReasonMissingObjectStorageCAConfigMap,
+				)
+			}
+
+			caBundle = []byte(data)
+			storage.CABundleSHA1 = fmt.Sprintf("%x", sha1.Sum(caBundle))
+		}
+
+		var conflict string
+		conflict, err = objectstorage.FindConflict(ctx, k, &stack, storage, &storageSecret)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to lookup conflicting object storage", "name", req.NamespacedName)
+		}
+		if conflict != "" {
+			return status.SetDegradedCondition(ctx, k, req,
+				fmt.Sprintf("Object storage buckets and prefix are already used by LokiStack %s", conflict),
+				lokiv1beta1.ReasonConflictingObjectStorage,
+			)
+		}
+
+		if flags.EnableObjectStorageProbe {
+			if err = objectStorageProber.Probe(ctx, &stack, storage, &storageSecret, caBundle); err != nil {
+				var pe *objectstorage.ProbeError
+				if !errors.As(err, &pe) {
+					return kverrors.Wrap(err, "failed to probe object storage", "name", req.NamespacedName)
+				}
+
+				if err = status.SetDegradedCondition(ctx, k, req,
+					fmt.Sprintf("Cannot connect to object storage: %s", pe),
+					pe.Reason,
+				); err != nil {
+					return err
+				}
+				// Keep applying the manifests to not hold back changes
+				// of the stack on transient object storage outages.
+				storageUnavailable = true
+			}
+		}
 	}
 
 	var (
@@ -75,7 +184,7 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		tenantConfigMap map[string]openshift.TenantData
 	)
 	if flags.EnableGateway && stack.Spec.Tenants != nil {
-		if err = gateway.ValidateModes(stack); err != nil {
+		if err = validation.ValidateModes(stack); err != nil {
 			return status.SetDegradedCondition(ctx, k, req,
 				fmt.Sprintf("Invalid tenants configuration: %s", err),
 				lokiv1beta1.ReasonInvalidTenantsConfiguration,
@@ -100,18 +209,105 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		}
 	}
 
+	if err = manifests.ValidateRetention(stack.Spec); err != nil {
+		return status.SetDegradedCondition(ctx, k, req,
+			fmt.Sprintf("Invalid retention configuration: %s", err),
+			lokiv1beta1.ReasonInvalidRetentionConfiguration,
+		)
+	}
+
+	applied := stack.Status.Storage.Schemas
+	if len(applied) == 0 {
+		// Stacks deployed before the applied schemas were recorded use the
+		// default schema, which must not be dropped or changed either.
+		var cm corev1.ConfigMap
+		key := client.ObjectKey{Name: manifests.LokiConfigMapName(stack.Name), Namespace: stack.Namespace}
+		if err = k.Get(ctx, key, &cm); client.IgnoreNotFound(err) != nil {
+			return kverrors.Wrap(err, "failed to lookup loki configmap", "name", key)
+		}
+		applied = manifests.AppliedSchemas(applied, err == nil)
+	}
+
+	if err = manifests.ValidateSchemas(stack.Spec.Storage.Schemas); err == nil {
+		err = manifests.ValidateSchemaUpdate(manifests.Schemas(stack.Spec), applied, time.Now())
+	}
+	if err != nil {
+		return status.SetDegradedCondition(ctx, k, req,
+			fmt.Sprintf("Invalid object storage schema configuration: %s", err),
+			lokiv1beta1.ReasonInvalidObjectStorageSchema,
+		)
+	}
+
+	var (
+		alertingRules  []lokiv1beta1.AlertingRule
+		recordingRules []lokiv1beta1.RecordingRule
+	)
+	if stack.Spec.Rules != nil && stack.Spec.Rules.Enabled {
+		alertingRules, recordingRules, err = rules.List(ctx, k, req.Namespace, stack.Spec.Rules)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to lookup rules", "spec", stack.Spec.Rules)
+		}
+
+		for _, r := range alertingRules {
+			if err = rules.ValidateAlertingRule(r); err != nil {
+				return status.SetDegradedCondition(ctx, k, req,
+					fmt.Sprintf("Invalid alerting rule %s/%s: %s", r.Namespace, r.Name, err),
+					lokiv1beta1.ReasonInvalidRulesConfiguration,
+				)
+			}
+		}
+
+		for _, r := range recordingRules {
+			if err = rules.ValidateRecordingRule(r); err != nil {
+				return status.SetDegradedCondition(ctx, k, req,
+					fmt.Sprintf("Invalid recording rule %s/%s: %s", r.Namespace, r.Name, err),
+					lokiv1beta1.ReasonInvalidRulesConfiguration,
+				)
+			}
+		}
+	}
+
+	var sizeProfile *manifests.SizeProfile
+	if name := stack.Spec.SizeProfile; name != "" {
+		var cm corev1.ConfigMap
+		key := client.ObjectKey{Name: manifests.SizeProfilesConfigMapName, Namespace: os.Getenv(manifests.EnvOperatorNamespace)}
+		if err = k.Get(ctx, key, &cm); client.IgnoreNotFound(err) != nil {
+			return kverrors.Wrap(err, "failed to lookup size profiles", "name", key)
+		}
+
+		data, ok := cm.Data[name]
+		if !ok {
+			return status.SetDegradedCondition(ctx, k, req,
+				fmt.Sprintf("Missing size profile %s", name),
+				lokiv1beta1.ReasonMissingSizeProfile,
+			)
+		}
+
+		sizeProfile, err = manifests.ParseSizeProfile(data)
+		if err != nil {
+			return status.SetDegradedCondition(ctx, k, req,
+				fmt.Sprintf("Invalid size profile %s: %s", name, err),
+				lokiv1beta1.ReasonInvalidSizeProfile,
+			)
+		}
+	}
+
 	// Here we will translate the lokiv1beta1.LokiStack options into manifest options
 	opts := manifests.Options{
 		Name:              req.Name,
 		Namespace:         req.Namespace,
 		Image:             img,
 		GatewayImage:      gwImg,
+		MemcachedImage:    memcachedImg,
 		GatewayBaseDomain: baseDomain,
 		Stack:             stack.Spec,
+		SizeProfile:       sizeProfile,
 		Flags:             flags,
 		ObjectStorage:     *storage,
 		TenantSecrets:     tenantSecrets,
 		TenantConfigMap:   tenantConfigMap,
+		AlertingRules:     alertingRules,
+		RecordingRules:    recordingRules,
 	}
 
 	ll.Info("begin building manifests")
@@ -121,6 +317,20 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		return optErr
 	}
 
+	if err = manifests.ValidateReplication(opts.Name, opts.Stack); err != nil {
+		return status.SetDegradedCondition(ctx, k, req,
+			fmt.Sprintf("Invalid replication configuration: %s", err),
+			lokiv1beta1.ReasonInvalidReplicationConfiguration,
+		)
+	}
+
+	if err = manifests.ValidateResources(opts); err != nil {
+		return status.SetDegradedCondition(ctx, k, req,
+			fmt.Sprintf("Invalid resource requirements: %s", err),
+			lokiv1beta1.ReasonInvalidResourceRequirements,
+		)
+	}
+
 	if flags.EnableGateway {
 		if optErr := manifests.ApplyGatewayDefaultOptions(&opts); optErr != nil {
 			ll.Error(optErr, "failed to apply defaults options to gateway settings ")
@@ -135,9 +345,44 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 	}
 	ll.Info("manifests built", "count", len(objects))
 
+	retired, err := ingesters.RetiredStatefulSets(ctx, k, &stack, objects)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to lookup retired ingester statefulsets", "name", req.NamespacedName)
+	}
+	objects = append(objects, retired...)
+
+	var caBundle []byte
+	if flags.EnableTLSServiceMonitorConfig {
+		caBundle, err = ingesters.ServiceCABundle(ctx, k, req.NamespacedName)
+		if err != nil {
+			return err
+		}
+	}
+
+	ic, err := ingesters.NewClient(flags.EnableTLSServiceMonitorConfig, caBundle)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to create ingester client", "name", req.NamespacedName)
+	}
+	draining, err := ingesters.PrepareScaleDown(ctx, ll, k, ic, req.NamespacedName, objects)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to prepare ingester scale-down", "name", req.NamespacedName)
+	}
+
+	apply, err := statefulsets.Prepare(ctx, ll, k, req.Namespace, objects)
+	if err != nil {
+		var uce *statefulsets.UnsupportedChangeError
+		if errors.As(err, &uce) {
+			return status.SetDegradedCondition(ctx, k, req,
+				fmt.Sprintf("Unsupported storage change: %s", err),
+				lokiv1beta1.ReasonUnsupportedStorageChange,
+			)
+		}
+		return kverrors.Wrap(err, "failed to prepare statefulsets", "name", req.NamespacedName)
+	}
+
 	var errCount int32
 
-	for _, obj := range objects {
+	for _, obj := range apply {
 		l := ll.WithValues(
 			"object_name", obj.GetName(),
 			"object_kind", obj.GetObjectKind(),
@@ -170,15 +415,48 @@ func CreateOrUpdateLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client
 		return kverrors.New("failed to configure lokistack resources", "name", req.NamespacedName)
 	}
 
+	if err := status.SetStorageSchemas(ctx, k, req, manifests.Schemas(stack.Spec)); err != nil {
+		return kverrors.Wrap(err, "failed to record applied storage schemas", "name", req.NamespacedName)
+	}
+
+	if err := pruneObjects(ctx, ll, k, s, rec, &stack, objects); err != nil {
+		return kverrors.Wrap(err, "failed to prune lokistack resources", "name", req.NamespacedName)
+	}
+
+	if deleteScaledPVCs(stack.Spec) {
+		if err := ingesters.PruneClaims(ctx, ll, k, req.NamespacedName, objects); err != nil {
+			return kverrors.Wrap(err, "failed to prune ingester persistent volume claims", "name", req.NamespacedName)
+		}
+	}
+
 	// 1x.extra-small is used only for development, so the metrics will not
-	// be collected.
-	if opts.Stack.Size != lokiv1beta1.SizeOneXExtraSmall {
-		metrics.Collect(&opts.Stack, opts.Name)
+	// be collected unless a size profile replaces it.
+	if opts.Stack.SizeProfile != "" || opts.Stack.Size != lokiv1beta1.SizeOneXExtraSmall {
+		metrics.Collect(&opts.Stack, opts.Name, opts.SizeProfile)
+	}
+
+	// Draining ingesters cannot flush to an unavailable object storage,
+	// thus poll the scale-down only as often as the object storage probe.
+	if storageUnavailable {
+		return ErrObjectStorageUnavailable
+	}
+
+	if draining {
+		return ErrIngesterScaleDownInProgress
+	}
+
+	if err := status.SetObservedGeneration(ctx, k, req, stack.Generation); err != nil {
+		return kverrors.Wrap(err, "failed to record observed generation", "name", req.NamespacedName)
 	}
 
 	return nil
 }
 
+func deleteScaledPVCs(spec lokiv1beta1.LokiStackSpec) bool {
+	p := spec.PersistentVolumeClaimRetentionPolicy
+	return p != nil && p.WhenScaled == lokiv1beta1.PersistentVolumeClaimRetentionPolicyDelete
+}
+
 func isNamespaceScoped(obj client.Object) bool {
 	switch obj.(type) {
 	case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
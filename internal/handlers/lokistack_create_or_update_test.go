@@ -5,8 +5,11 @@ import (
 	"errors"
 	"flag"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/ViaQ/logerr/log"
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
@@ -17,14 +20,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -32,8 +39,9 @@ import (
 )
 
 var (
-	scheme = runtime.NewScheme()
-	flags  = manifests.FeatureFlags{
+	scheme   = runtime.NewScheme()
+	recorder = record.NewFakeRecorder(100)
+	flags    = manifests.FeatureFlags{
 		EnableCertificateSigningService: false,
 		EnableServiceMonitors:           false,
 		EnableTLSServiceMonitorConfig:   false,
@@ -111,7 +119,7 @@ func TestCreateOrUpdateLokiStack_WhenGetReturnsNotFound_DoesNotError(t *testing.
 		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 	require.NoError(t, err)
 
 	// make sure create was NOT called because the Get failed
@@ -132,7 +140,7 @@ func TestCreateOrUpdateLokiStack_WhenGetReturnsAnErrorOtherThanNotFound_ReturnsT
 		return badRequestErr
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	require.Equal(t, badRequestErr, errors.Unwrap(err))
 
@@ -141,7 +149,9 @@ func TestCreateOrUpdateLokiStack_WhenGetReturnsAnErrorOtherThanNotFound_ReturnsT
 }
 
 func TestCreateOrUpdateLokiStack_SetsNamespaceOnAllObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -208,15 +218,71 @@ func TestCreateOrUpdateLokiStack_SetsNamespaceOnAllObjects(t *testing.T) {
 		return nil
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 	require.NoError(t, err)
 
 	// make sure create was called
 	require.NotZero(t, k.CreateCallCount())
 }
 
+func TestCreateOrUpdateLokiStack_WhenFilesystemStorage_CreateSharedClaimWithoutSecret(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+			},
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, out client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(out, &stack)
+			return nil
+		}
+		if _, ok := out.(*corev1.Secret); ok {
+			t.Fatalf("unexpected secret lookup: %s", name)
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+	}
+
+	var claims []string
+	k.CreateStub = func(_ context.Context, o client.Object, _ ...client.CreateOption) error {
+		if _, ok := o.(*corev1.PersistentVolumeClaim); ok {
+			claims = append(claims, o.GetName())
+		}
+		return nil
+	}
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+	require.NoError(t, err)
+
+	// make sure the shared claim was created
+	require.Equal(t, []string{"loki-shared-storage-my-stack"}, claims)
+}
+
 func TestCreateOrUpdateLokiStack_SetsOwnerRefOnAllObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -305,7 +371,7 @@ func TestCreateOrUpdateLokiStack_SetsOwnerRefOnAllObjects(t *testing.T) {
 		return nil
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 	require.NoError(t, err)
 
 	// make sure create was called
@@ -313,7 +379,9 @@ func TestCreateOrUpdateLokiStack_SetsOwnerRefOnAllObjects(t *testing.T) {
 }
 
 func TestCreateOrUpdateLokiStack_WhenSetControllerRefInvalid_ContinueWithOtherObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -354,14 +422,16 @@ func TestCreateOrUpdateLokiStack_WhenSetControllerRefInvalid_ContinueWithOtherOb
 		return nil
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.Error(t, err)
 }
 
 func TestCreateOrUpdateLokiStack_WhenGetReturnsNoError_UpdateObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -446,7 +516,7 @@ func TestCreateOrUpdateLokiStack_WhenGetReturnsNoError_UpdateObjects(t *testing.
 		return nil
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 	require.NoError(t, err)
 
 	// make sure create not called
@@ -457,7 +527,9 @@ func TestCreateOrUpdateLokiStack_WhenGetReturnsNoError_UpdateObjects(t *testing.
 }
 
 func TestCreateOrUpdateLokiStack_WhenCreateReturnsError_ContinueWithOtherObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -503,14 +575,16 @@ func TestCreateOrUpdateLokiStack_WhenCreateReturnsError_ContinueWithOtherObjects
 		return apierrors.NewTooManyRequestsError("too many create requests")
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.Error(t, err)
 }
 
 func TestCreateOrUpdateLokiStack_WhenUpdateReturnsError_ContinueWithOtherObjects(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
 	r := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      "my-stack",
@@ -601,7 +675,7 @@ func TestCreateOrUpdateLokiStack_WhenUpdateReturnsError_ContinueWithOtherObjects
 		return apierrors.NewTooManyRequestsError("too many create requests")
 	}
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.Error(t, err)
@@ -648,7 +722,7 @@ func TestCreateOrUpdateLokiStack_WhenMissingSecret_SetDegraded(t *testing.T) {
 
 	k.StatusStub = func() client.StatusWriter { return sw }
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.NoError(t, err)
@@ -703,7 +777,7 @@ func TestCreateOrUpdateLokiStack_WhenInvalidSecret_SetDegraded(t *testing.T) {
 
 	k.StatusStub = func() client.StatusWriter { return sw }
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, flags)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.NoError(t, err)
@@ -713,6 +787,216 @@ func TestCreateOrUpdateLokiStack_WhenInvalidSecret_SetDegraded(t *testing.T) {
 	require.NotZero(t, sw.UpdateCallCount())
 }
 
+func TestCreateOrUpdateLokiStack_WhenMissingObjectStorageCAConfigMap_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+				TLS: &lokiv1beta1.ObjectStorageTLSSpec{
+					CA: "storage-ca",
+				},
+			},
+		},
+	}
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if name.Name == defaultSecret.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports the missing config map
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	conds := obj.(*lokiv1beta1.LokiStack).Status.Conditions
+	require.Len(t, conds, 1)
+	require.Equal(t, string(lokiv1beta1.ReasonMissingObjectStorageCAConfigMap), conds[0].Reason)
+}
+
+func TestCreateOrUpdateLokiStack_WhenObjectStorageBucketMissing_SetDegradedAndApplyManifests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	probeFlags := manifests.FeatureFlags{
+		EnableObjectStorageProbe: true,
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+		},
+	}
+
+	storageSecret := defaultSecret.DeepCopy()
+	storageSecret.Data["endpoint"] = []byte(srv.URL)
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if name.Name == storageSecret.Name {
+			k.SetClientObject(object, storageSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, probeFlags)
+
+	// make sure the error is returned to probe again after the interval
+	require.ErrorIs(t, err, handlers.ErrObjectStorageUnavailable)
+
+	// make sure the objects are created regardless of the outage
+	require.NotZero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports the missing bucket
+	require.NotZero(t, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	conds := obj.(*lokiv1beta1.LokiStack).Status.Conditions
+	require.Len(t, conds, 1)
+	require.Equal(t, string(lokiv1beta1.ConditionDegraded), conds[0].Type)
+	require.Equal(t, string(lokiv1beta1.ReasonMissingObjectStorageBucket), conds[0].Reason)
+}
+
+func TestCreateOrUpdateLokiStack_WhenObjectStorageUsedByOtherStack_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "my-stack",
+			Namespace:         "some-ns",
+			UID:               "b23f9a38-9672-499f-8c29-15ede74d3ece",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+		},
+	}
+
+	other := stack.DeepCopy()
+	other.Namespace = "other-ns"
+	other.CreationTimestamp = metav1.NewTime(stack.CreationTimestamp.Add(-time.Hour))
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if name.Name == defaultSecret.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := list.(*lokiv1beta1.LokiStackList); ok {
+			k.SetClientObjectList(list, &lokiv1beta1.LokiStackList{
+				Items: []lokiv1beta1.LokiStack{*stack, *other},
+			})
+		}
+		return nil
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports the other stack
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	conds := obj.(*lokiv1beta1.LokiStack).Status.Conditions
+	require.Len(t, conds, 1)
+	require.Equal(t, string(lokiv1beta1.ReasonConflictingObjectStorage), conds[0].Reason)
+	require.Contains(t, conds[0].Message, "other-ns/my-stack")
+}
+
 func TestCreateOrUpdateLokiStack_WhenInvalidTenantsConfiguration_SetDegraded(t *testing.T) {
 	sw := &k8sfakes.FakeStatusWriter{}
 	k := &k8sfakes.FakeClient{}
@@ -777,7 +1061,7 @@ func TestCreateOrUpdateLokiStack_WhenInvalidTenantsConfiguration_SetDegraded(t *
 
 	k.StatusStub = func() client.StatusWriter { return sw }
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, ff)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, ff)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.NoError(t, err)
@@ -856,7 +1140,7 @@ func TestCreateOrUpdateLokiStack_WhenMissingGatewaySecret_SetDegraded(t *testing
 
 	k.StatusStub = func() client.StatusWriter { return sw }
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, ff)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, ff)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.Error(t, err)
@@ -939,7 +1223,7 @@ func TestCreateOrUpdateLokiStack_WhenInvalidGatewaySecret_SetDegraded(t *testing
 
 	k.StatusStub = func() client.StatusWriter { return sw }
 
-	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, ff)
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, ff)
 
 	// make sure error is returned to re-trigger reconciliation
 	require.Error(t, err)
@@ -948,3 +1232,739 @@ func TestCreateOrUpdateLokiStack_WhenInvalidGatewaySecret_SetDegraded(t *testing
 	require.NotZero(t, k.StatusCallCount())
 	require.NotZero(t, sw.UpdateCallCount())
 }
+
+func TestCreateOrUpdateLokiStack_WhenInvalidAlertingRule_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+			Rules: &lokiv1beta1.RulesSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	rules := lokiv1beta1.AlertingRuleList{
+		Items: []lokiv1beta1.AlertingRule{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "invalid-rule",
+					Namespace: "some-ns",
+				},
+				Spec: lokiv1beta1.AlertingRuleSpec{
+					TenantID: "application",
+					Groups: []*lokiv1beta1.AlertingRuleGroup{
+						{
+							Name:     "first",
+							Interval: "1m",
+						},
+						{
+							Name:     "first",
+							Interval: "1m",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := list.(*lokiv1beta1.AlertingRuleList); ok {
+			k.SetClientObjectList(list, &rules)
+		}
+		return nil
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure status and status-update calls
+	require.NotZero(t, k.StatusCallCount())
+	require.NotZero(t, sw.UpdateCallCount())
+}
+
+func TestCreateOrUpdateLokiStack_WhenInvalidRetention_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					Retention: &lokiv1beta1.RetentionLimitSpec{
+						// Not a multiple of the 24h schema index period
+						Period: "36h",
+					},
+				},
+			},
+		},
+	}
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure status and status-update calls
+	require.NotZero(t, k.StatusCallCount())
+	require.NotZero(t, sw.UpdateCallCount())
+}
+
+func TestCreateOrUpdateLokiStack_WhenReplicationFactorExceedsIngesters_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:              lokiv1beta1.SizeOneXSmall,
+			ReplicationFactor: 3,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 2,
+				},
+			},
+		},
+	}
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure status and status-update calls
+	require.NotZero(t, k.StatusCallCount())
+	require.NotZero(t, sw.UpdateCallCount())
+}
+
+func TestCreateOrUpdateLokiStack_WhenResourceRequestExceedsLimit_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:              lokiv1beta1.SizeOneXSmall,
+			ReplicationFactor: 1,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// GetStub looks up the CR first, so we need to return our fake stack
+	// return NotFound for everything else to trigger create.
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure status and status-update calls
+	require.NotZero(t, k.StatusCallCount())
+	require.NotZero(t, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(sw.UpdateCallCount() - 1)
+	conditions := obj.(*lokiv1beta1.LokiStack).Status.Conditions
+	require.Equal(t, string(lokiv1beta1.ReasonInvalidResourceRequirements), conditions[len(conditions)-1].Reason)
+}
+
+func TestCreateOrUpdateLokiStack_WhenUnsupportedStorageChange_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:             lokiv1beta1.SizeOneXExtraSmall,
+			StorageClassName: "new-class",
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+		},
+	}
+
+	// The existing compactor statefulset uses another storage class
+	compactor := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "loki-compactor-my-stack",
+			Namespace:         "some-ns",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "storage"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: pointer.StringPtr("old-class"),
+					},
+				},
+			},
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		if _, ok := object.(*appsv1.StatefulSet); ok && compactor.Name == name.Name {
+			k.SetClientObject(object, &compactor)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created or deleted
+	require.Zero(t, k.CreateCallCount())
+	require.Zero(t, k.DeleteCallCount())
+
+	// make sure the degraded condition reports the unsupported change
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	cond := obj.(*lokiv1beta1.LokiStack).Status.Conditions[0]
+	require.Equal(t, string(lokiv1beta1.ReasonUnsupportedStorageChange), cond.Reason)
+}
+
+func TestCreateOrUpdateLokiStack_WhenMissingSizeProfile_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:        lokiv1beta1.SizeOneXExtraSmall,
+			SizeProfile: "custom",
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+		},
+	}
+
+	sizeProfiles := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: manifests.SizeProfilesConfigMapName,
+		},
+		Data: map[string]string{
+			"other": "",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		if sizeProfiles.Name == name.Name {
+			k.SetClientObject(object, &sizeProfiles)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports the missing profile
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	cond := obj.(*lokiv1beta1.LokiStack).Status.Conditions[0]
+	require.Equal(t, string(lokiv1beta1.ReasonMissingSizeProfile), cond.Reason)
+	require.Equal(t, "Missing size profile custom", cond.Message)
+}
+
+func TestCreateOrUpdateLokiStack_WhenSchemaInEffectChanged_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+				Schemas: []lokiv1beta1.ObjectStorageSchema{
+					{
+						Version:       lokiv1beta1.ObjectStorageSchemaV12,
+						EffectiveDate: "2020-10-01",
+					},
+				},
+			},
+		},
+		Status: lokiv1beta1.LokiStackStatus{
+			Storage: lokiv1beta1.LokiStackStorageStatus{
+				Schemas: []lokiv1beta1.ObjectStorageSchema{
+					{
+						Version:       lokiv1beta1.ObjectStorageSchemaV11,
+						EffectiveDate: "2020-10-01",
+					},
+				},
+			},
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+
+	// make sure error is returned to re-trigger reconciliation
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports the invalid schemas
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	cond := obj.(*lokiv1beta1.LokiStack).Status.Conditions[0]
+	require.Equal(t, string(lokiv1beta1.ReasonInvalidObjectStorageSchema), cond.Reason)
+}
+
+func TestCreateOrUpdateLokiStack_WhenDeployedWithoutAppliedSchemasChangesDefaultSchema_SetDegraded(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	// Deployed before the applied schemas were recorded in the status
+	stack := &lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+				Schemas: []lokiv1beta1.ObjectStorageSchema{
+					{
+						Version:       lokiv1beta1.ObjectStorageSchemaV13,
+						EffectiveDate: "2021-01-01",
+					},
+				},
+			},
+		},
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loki-config-my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		if configMap.Name == name.Name && configMap.Namespace == name.Namespace {
+			k.SetClientObject(object, configMap)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, recorder, flags)
+	require.NoError(t, err)
+
+	// make sure no objects are created
+	require.Zero(t, k.CreateCallCount())
+
+	// make sure the degraded condition reports dropping the default schema
+	require.Equal(t, 1, sw.UpdateCallCount())
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	cond := obj.(*lokiv1beta1.LokiStack).Status.Conditions[0]
+	require.Equal(t, string(lokiv1beta1.ReasonInvalidObjectStorageSchema), cond.Reason)
+	require.Contains(t, cond.Message, "2020-10-01")
+}
+
+func TestCreateOrUpdateLokiStack_PrunesOwnedObjectsNoLongerDesired(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+	k.StatusStub = func() client.StatusWriter { return sw }
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: defaultSecret.Name,
+				},
+			},
+		},
+	}
+
+	ownerRefs := []metav1.OwnerReference{
+		{
+			APIVersion:         "loki.openshift.io/v1beta1",
+			Kind:               "LokiStack",
+			Name:               "my-stack",
+			UID:                "b23f9a38-9672-499f-8c29-15ede74d3ece",
+			Controller:         pointer.BoolPtr(true),
+			BlockOwnerDeletion: pointer.BoolPtr(true),
+		},
+	}
+
+	deployments := metav1.PartialObjectMetadataList{
+		Items: []metav1.PartialObjectMetadata{
+			{
+				// Still rendered by the manifests, thus kept
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "loki-distributor-my-stack",
+					Namespace:       "some-ns",
+					OwnerReferences: ownerRefs,
+				},
+			},
+			{
+				// Not rendered anymore after disabling the gateway, thus pruned
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "lokistack-gateway-my-stack",
+					Namespace:       "some-ns",
+					OwnerReferences: ownerRefs,
+				},
+			},
+			{
+				// Not owned by the stack, thus kept
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "some-other-deployment",
+					Namespace: "some-ns",
+				},
+			},
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, &stack)
+			return nil
+		}
+		if defaultSecret.Name == name.Name {
+			k.SetClientObject(object, &defaultSecret)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something is not found")
+	}
+
+	clusterRoles := metav1.PartialObjectMetadataList{
+		Items: []metav1.PartialObjectMetadata{
+			{
+				// Cluster-scoped without owner, not rendered anymore, thus pruned
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "lokistack-gateway-my-stack",
+				},
+			},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+		switch list.GetObjectKind().GroupVersionKind().Kind {
+		case "DeploymentList":
+			k.SetClientObjectList(list, &deployments)
+		case "ClusterRoleList":
+			// Cluster-scoped objects are selected by the namespace of the stack too
+			lo := &client.ListOptions{}
+			lo.ApplyOptions(opts)
+			require.True(t, lo.LabelSelector.Matches(labels.Set{
+				"app.kubernetes.io/name":     "loki",
+				"app.kubernetes.io/provider": "openshift",
+				"loki.grafana.com/name":      "my-stack",
+				"loki.grafana.com/namespace": "some-ns",
+			}))
+			require.False(t, lo.LabelSelector.Matches(labels.Set{
+				"app.kubernetes.io/name":     "loki",
+				"app.kubernetes.io/provider": "openshift",
+				"loki.grafana.com/name":      "my-stack",
+				"loki.grafana.com/namespace": "other-ns",
+			}))
+			k.SetClientObjectList(list, &clusterRoles)
+		}
+		return nil
+	}
+
+	rec := record.NewFakeRecorder(10)
+
+	err := handlers.CreateOrUpdateLokiStack(context.TODO(), r, k, scheme, rec, flags)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, k.DeleteCallCount())
+	_, obj, _ := k.DeleteArgsForCall(0)
+	require.Equal(t, "lokistack-gateway-my-stack", obj.GetName())
+	require.Equal(t, "Deployment", obj.GetObjectKind().GroupVersionKind().Kind)
+
+	_, obj, _ = k.DeleteArgsForCall(1)
+	require.Equal(t, "lokistack-gateway-my-stack", obj.GetName())
+	require.Equal(t, "ClusterRole", obj.GetObjectKind().GroupVersionKind().Kind)
+
+	require.Len(t, rec.Events, 2)
+	require.Contains(t, <-rec.Events, "PrunedObject")
+}
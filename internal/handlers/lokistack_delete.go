@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/ViaQ/loki-operator/internal/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// LokiStackFinalizer is the finalizer added to LokiStack custom resources
+// to clean up resources that cannot be garbage collected by owner references.
+const LokiStackFinalizer = "loki.openshift.io/finalizer"
+
+// DeleteLokiStack handles LokiStack delete events. It cleans up the cluster-scoped
+// objects, optionally the persistent volume claims and the metrics of the stack
+// before releasing the finalizer. It returns true if the stack does not exist
+// or is being deleted, i.e. no further reconciliation is required.
+func DeleteLokiStack(ctx context.Context, req ctrl.Request, k k8s.Client) (bool, error) {
+	ll := log.WithValues("lokistack", req.NamespacedName, "event", "delete")
+
+	var stack lokiv1beta1.LokiStack
+	if err := k.Get(ctx, req.NamespacedName, &stack); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
+	}
+
+	if stack.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(&stack, LokiStackFinalizer) {
+		return true, nil
+	}
+
+	// Stacks of the same name in different namespaces share the managed labels,
+	// thus cluster-scoped objects are selected by the namespace of the stack too.
+	clusterLabels := client.MatchingLabels(manifests.ClusterScopedLabels(stack.Name, stack.Namespace))
+
+	var crbs rbacv1.ClusterRoleBindingList
+	if err := k.List(ctx, &crbs, clusterLabels); err != nil {
+		return false, kverrors.Wrap(err, "failed to list lokistack cluster role bindings", "name", req.NamespacedName)
+	}
+
+	var crs rbacv1.ClusterRoleList
+	if err := k.List(ctx, &crs, clusterLabels); err != nil {
+		return false, kverrors.Wrap(err, "failed to list lokistack cluster roles", "name", req.NamespacedName)
+	}
+
+	var objs []client.Object
+	for i := range crbs.Items {
+		objs = append(objs, &crbs.Items[i])
+	}
+	for i := range crs.Items {
+		objs = append(objs, &crs.Items[i])
+	}
+
+	if deletePVCs(stack.Spec) {
+		var pvcs corev1.PersistentVolumeClaimList
+		labels := client.MatchingLabels(manifests.ManagedLabels(stack.Name))
+		if err := k.List(ctx, &pvcs, labels, client.InNamespace(stack.Namespace)); err != nil {
+			return false, kverrors.Wrap(err, "failed to list lokistack persistent volume claims", "name", req.NamespacedName)
+		}
+
+		for i := range pvcs.Items {
+			objs = append(objs, &pvcs.Items[i])
+		}
+	}
+
+	for _, obj := range objs {
+		if err := k.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return false, kverrors.Wrap(err, "failed to delete lokistack resource",
+				"name", req.NamespacedName,
+				"object_name", obj.GetName(),
+			)
+		}
+
+		ll.Info("Resource has been deleted", "object_name", obj.GetName(), "object_kind", obj.GetObjectKind())
+	}
+
+	metrics.Reset(stack.Name)
+	objectStorageProber.Forget(req.NamespacedName)
+
+	controllerutil.RemoveFinalizer(&stack, LokiStackFinalizer)
+	if err := k.Update(ctx, &stack); err != nil {
+		return false, kverrors.Wrap(err, "failed to remove lokistack finalizer", "name", req.NamespacedName)
+	}
+
+	return true, nil
+}
+
+func deletePVCs(spec lokiv1beta1.LokiStackSpec) bool {
+	p := spec.PersistentVolumeClaimRetentionPolicy
+	return p != nil && p.WhenDeleted == lokiv1beta1.PersistentVolumeClaimRetentionPolicyDelete
+}
@@ -0,0 +1,181 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/handlers"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeleteLokiStack_WhenGetReturnsNotFound_IsDeleted(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, _ types.NamespacedName, _ client.Object) error {
+		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+	}
+
+	deleted, err := handlers.DeleteLokiStack(context.TODO(), r, k)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.Zero(t, k.DeleteCallCount())
+	require.Zero(t, k.UpdateCallCount())
+}
+
+func TestDeleteLokiStack_WhenNotDeleting_IsNotDeleted(t *testing.T) {
+	k := &k8sfakes.FakeClient{}
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	stack := lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "my-stack",
+			Namespace:  "some-ns",
+			Finalizers: []string{handlers.LokiStackFinalizer},
+		},
+	}
+
+	k.GetStub = func(_ context.Context, _ types.NamespacedName, object client.Object) error {
+		k.SetClientObject(object, &stack)
+		return nil
+	}
+
+	deleted, err := handlers.DeleteLokiStack(context.TODO(), r, k)
+	require.NoError(t, err)
+	require.False(t, deleted)
+	require.Zero(t, k.DeleteCallCount())
+	require.Zero(t, k.UpdateCallCount())
+}
+
+func TestDeleteLokiStack_WhenDeleting_CleansUpAndRemovesFinalizer(t *testing.T) {
+	type test struct {
+		name       string
+		policy     *lokiv1beta1.PersistentVolumeClaimRetentionPolicySpec
+		wantDelete []string
+	}
+	table := []test{
+		{
+			name:       "retain persistent volume claims by default",
+			wantDelete: []string{"lokistack-gateway-my-stack", "lokistack-gateway-my-stack"},
+		},
+		{
+			name: "retain persistent volume claims",
+			policy: &lokiv1beta1.PersistentVolumeClaimRetentionPolicySpec{
+				WhenDeleted: lokiv1beta1.PersistentVolumeClaimRetentionPolicyRetain,
+			},
+			wantDelete: []string{"lokistack-gateway-my-stack", "lokistack-gateway-my-stack"},
+		},
+		{
+			name: "delete persistent volume claims",
+			policy: &lokiv1beta1.PersistentVolumeClaimRetentionPolicySpec{
+				WhenDeleted: lokiv1beta1.PersistentVolumeClaimRetentionPolicyDelete,
+			},
+			wantDelete: []string{"lokistack-gateway-my-stack", "lokistack-gateway-my-stack", "storage-loki-ingester-my-stack-0"},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			k := &k8sfakes.FakeClient{}
+			r := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+			}
+
+			now := metav1.Now()
+			stack := lokiv1beta1.LokiStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "my-stack",
+					Namespace:         "some-ns",
+					DeletionTimestamp: &now,
+					Finalizers:        []string{handlers.LokiStackFinalizer},
+				},
+				Spec: lokiv1beta1.LokiStackSpec{
+					Size:                                 lokiv1beta1.SizeOneXExtraSmall,
+					PersistentVolumeClaimRetentionPolicy: tst.policy,
+				},
+			}
+
+			k.GetStub = func(_ context.Context, _ types.NamespacedName, object client.Object) error {
+				k.SetClientObject(object, &stack)
+				return nil
+			}
+
+			k.ListStub = func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+				lo := &client.ListOptions{}
+				lo.ApplyOptions(opts)
+
+				switch list.(type) {
+				case *rbacv1.ClusterRoleBindingList, *rbacv1.ClusterRoleList:
+					// Do not select the cluster-scoped objects of a stack of the same name in another namespace
+					other := labels.Merge(manifests.ManagedLabels("my-stack"), labels.Set{"loki.grafana.com/namespace": "other-ns"})
+					require.False(t, lo.LabelSelector.Matches(other))
+					require.True(t, lo.LabelSelector.Matches(manifests.ClusterScopedLabels("my-stack", "some-ns")))
+				}
+
+				switch list.(type) {
+				case *rbacv1.ClusterRoleBindingList:
+					k.SetClientObjectList(list, &rbacv1.ClusterRoleBindingList{
+						Items: []rbacv1.ClusterRoleBinding{
+							{ObjectMeta: metav1.ObjectMeta{Name: "lokistack-gateway-my-stack"}},
+						},
+					})
+				case *rbacv1.ClusterRoleList:
+					k.SetClientObjectList(list, &rbacv1.ClusterRoleList{
+						Items: []rbacv1.ClusterRole{
+							{ObjectMeta: metav1.ObjectMeta{Name: "lokistack-gateway-my-stack"}},
+						},
+					})
+				case *corev1.PersistentVolumeClaimList:
+					k.SetClientObjectList(list, &corev1.PersistentVolumeClaimList{
+						Items: []corev1.PersistentVolumeClaim{
+							{ObjectMeta: metav1.ObjectMeta{Name: "storage-loki-ingester-my-stack-0", Namespace: "some-ns"}},
+						},
+					})
+				}
+				return nil
+			}
+
+			deleted, err := handlers.DeleteLokiStack(context.TODO(), r, k)
+			require.NoError(t, err)
+			require.True(t, deleted)
+
+			var names []string
+			for i := 0; i < k.DeleteCallCount(); i++ {
+				_, obj, _ := k.DeleteArgsForCall(i)
+				names = append(names, obj.GetName())
+			}
+			require.ElementsMatch(t, tst.wantDelete, names)
+
+			require.Equal(t, 1, k.UpdateCallCount())
+			_, obj, _ := k.UpdateArgsForCall(0)
+			require.Empty(t, obj.GetFinalizers())
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// prunableKinds lists the kinds of namespaced objects generated for a LokiStack.
+// Objects are listed by their metadata only, thus a kind does not need to be
+// registered in the scheme to be pruned, e.g. ServiceMonitors after disabling
+// the service monitors feature.
+var prunableKinds = []schema.GroupVersionKind{
+	corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+	corev1.SchemeGroupVersion.WithKind("Service"),
+	corev1.SchemeGroupVersion.WithKind("ServiceAccount"),
+	appsv1.SchemeGroupVersion.WithKind("Deployment"),
+	appsv1.SchemeGroupVersion.WithKind("StatefulSet"),
+	policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"),
+	autoscalingv2beta2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"),
+	networkingv1.SchemeGroupVersion.WithKind("Ingress"),
+	schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+	monitoringv1.SchemeGroupVersion.WithKind(monitoringv1.ServiceMonitorsKind),
+}
+
+// prunableClusterKinds lists the kinds of cluster-scoped objects generated for a LokiStack.
+var prunableClusterKinds = []schema.GroupVersionKind{
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRole"),
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"),
+}
+
+// pruneObjects deletes all objects owned by the stack that carry the stack's
+// managed labels but are not part of the desired objects anymore. Retired
+// ingester statefulsets are part of the desired objects until drained, see
+// ingesters.RetiredStatefulSets.
+func pruneObjects(ctx context.Context, ll logr.Logger, k k8s.Client, s *runtime.Scheme, rec record.EventRecorder, stack *lokiv1beta1.LokiStack, desired []client.Object) error {
+	keep := make(map[string]bool, len(desired))
+	for _, obj := range desired {
+		gvk, err := apiutil.GVKForObject(obj, s)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to lookup object kind", "name", obj.GetName())
+		}
+		keep[inventoryKey(gvk.GroupKind(), obj.GetName())] = true
+	}
+
+	var errCount int32
+
+	for _, gvk := range prunableKinds {
+		opts := []client.ListOption{
+			client.InNamespace(stack.Namespace),
+			client.MatchingLabels(manifests.ManagedLabels(stack.Name)),
+		}
+		n, err := pruneKind(ctx, ll, k, rec, stack, gvk, keep, true, opts...)
+		if err != nil {
+			return err
+		}
+		errCount += n
+	}
+
+	// Cluster-scoped objects cannot be owned by the stack. As on deletion
+	// they are selected by the cluster-scoped labels of the stack only.
+	for _, gvk := range prunableClusterKinds {
+		opts := client.MatchingLabels(manifests.ClusterScopedLabels(stack.Name, stack.Namespace))
+		n, err := pruneKind(ctx, ll, k, rec, stack, gvk, keep, false, opts)
+		if err != nil {
+			return err
+		}
+		errCount += n
+	}
+
+	if errCount > 0 {
+		return kverrors.New("failed to prune lokistack resources", "name", stack.Name)
+	}
+
+	return nil
+}
+
+// pruneKind deletes the objects of the kind matching the list options that are
+// not kept. If owned is true only objects controlled by the stack are deleted.
+// It returns the number of objects that failed to be deleted.
+func pruneKind(ctx context.Context, ll logr.Logger, k k8s.Client, rec record.EventRecorder, stack *lokiv1beta1.LokiStack, gvk schema.GroupVersionKind, keep map[string]bool, owned bool, opts ...client.ListOption) (int32, error) {
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	if err := k.List(ctx, list, opts...); err != nil {
+		if meta.IsNoMatchError(err) {
+			// The kind is not served by the cluster, e.g. Routes on plain Kubernetes.
+			return 0, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to list objects for pruning", "kind", gvk.Kind)
+	}
+
+	var errCount int32
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if keep[inventoryKey(gvk.GroupKind(), obj.GetName())] || (owned && !metav1.IsControlledBy(obj, stack)) {
+			continue
+		}
+
+		l := ll.WithValues("object_name", obj.GetName(), "object_kind", gvk.Kind)
+
+		obj.SetGroupVersionKind(gvk)
+		if err := k.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			l.Error(err, "failed to prune resource")
+			errCount++
+			continue
+		}
+
+		l.Info("Resource has been pruned")
+		rec.Event(stack, corev1.EventTypeNormal, "PrunedObject",
+			fmt.Sprintf("Pruned %s %s that is no longer desired", gvk.Kind, obj.GetName()))
+	}
+
+	return errCount, nil
+}
+
+func inventoryKey(gk schema.GroupKind, name string) string {
+	return fmt.Sprintf("%s/%s", gk, name)
+}
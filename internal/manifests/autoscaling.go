@@ -0,0 +1,121 @@
+package manifests
+
+import (
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultTargetCPUUtilizationPercentage int32 = 80
+
+// BuildHorizontalPodAutoscalers builds the horizontal pod autoscalers for
+// all autoscaled components.
+func BuildHorizontalPodAutoscalers(opts Options) []client.Object {
+	tpl := opts.Stack.Template
+	if tpl == nil {
+		return nil
+	}
+
+	type target struct {
+		component string
+		name      string
+		spec      *lokiv1beta1.LokiComponentSpec
+	}
+	targets := []target{
+		{component: LabelDistributorComponent, name: DistributorName(opts.Name), spec: tpl.Distributor},
+		{component: LabelQuerierComponent, name: QuerierName(opts.Name), spec: tpl.Querier},
+		{component: LabelQueryFrontendComponent, name: QueryFrontendName(opts.Name), spec: tpl.QueryFrontend},
+	}
+	if opts.Flags.EnableGateway {
+		targets = append(targets, target{component: LabelGatewayComponent, name: GatewayName(opts.Name), spec: tpl.Gateway})
+	}
+
+	var objs []client.Object
+	for _, t := range targets {
+		if t.spec == nil || t.spec.Autoscaling == nil {
+			continue
+		}
+		objs = append(objs, NewHorizontalPodAutoscaler(t.component, t.name, opts.Name, *t.spec.Autoscaling))
+	}
+
+	return objs
+}
+
+// NewHorizontalPodAutoscaler creates a horizontal pod autoscaler scaling the named component deployment.
+func NewHorizontalPodAutoscaler(component, name, stackName string, as lokiv1beta1.AutoscalingSpec) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	minReplicas := as.MinReplicas
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+
+	cpu := as.TargetCPUUtilizationPercentage
+	if cpu == nil && as.TargetMemoryUtilizationPercentage == nil {
+		cpu = pointer.Int32Ptr(defaultTargetCPUUtilizationPercentage)
+	}
+
+	var metrics []autoscalingv2beta2.MetricSpec
+	if cpu != nil {
+		metrics = append(metrics, utilizationMetric(corev1.ResourceCPU, *cpu))
+	}
+	if as.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, utilizationMetric(corev1.ResourceMemory, *as.TargetMemoryUtilizationPercentage))
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2beta2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: ComponentLabels(component, stackName),
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name,
+			},
+			MinReplicas: pointer.Int32Ptr(minReplicas),
+			MaxReplicas: as.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+func utilizationMetric(name corev1.ResourceName, percentage int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: pointer.Int32Ptr(percentage),
+			},
+		},
+	}
+}
+
+// deploymentReplicas returns the replicas of the component deployment or nil
+// if the replicas are owned by a horizontal pod autoscaler.
+func deploymentReplicas(c *lokiv1beta1.LokiComponentSpec) *int32 {
+	if c.Autoscaling != nil {
+		return nil
+	}
+	return pointer.Int32Ptr(c.Replicas)
+}
+
+// minComponentReplicas returns the lowest number of replicas the component
+// runs with, i.e. the autoscaler min replicas if the component is autoscaled.
+func minComponentReplicas(c *lokiv1beta1.LokiComponentSpec) int32 {
+	if c.Autoscaling != nil {
+		if c.Autoscaling.MinReplicas < 1 {
+			return 1
+		}
+		return c.Autoscaling.MinReplicas
+	}
+	return c.Replicas
+}
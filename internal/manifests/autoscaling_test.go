@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestBuildHorizontalPodAutoscalers_ForAutoscaledComponents(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Autoscaling: &lokiv1beta1.AutoscalingSpec{
+						MinReplicas:                       2,
+						MaxReplicas:                       6,
+						TargetMemoryUtilizationPercentage: pointer.Int32Ptr(70),
+					},
+				},
+				Distributor: &lokiv1beta1.LokiComponentSpec{
+					Autoscaling: &lokiv1beta1.AutoscalingSpec{
+						MaxReplicas: 4,
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	objs := BuildHorizontalPodAutoscalers(opts)
+	require.Len(t, objs, 2)
+
+	distributor := objs[0].(*autoscalingv2beta2.HorizontalPodAutoscaler)
+	require.Equal(t, DistributorName(opts.Name), distributor.Spec.ScaleTargetRef.Name)
+	require.Equal(t, "Deployment", distributor.Spec.ScaleTargetRef.Kind)
+	require.Equal(t, pointer.Int32Ptr(1), distributor.Spec.MinReplicas)
+	require.Equal(t, int32(4), distributor.Spec.MaxReplicas)
+	require.Len(t, distributor.Spec.Metrics, 1)
+	require.Equal(t, corev1.ResourceCPU, distributor.Spec.Metrics[0].Resource.Name)
+	require.Equal(t, pointer.Int32Ptr(defaultTargetCPUUtilizationPercentage), distributor.Spec.Metrics[0].Resource.Target.AverageUtilization)
+
+	querier := objs[1].(*autoscalingv2beta2.HorizontalPodAutoscaler)
+	require.Equal(t, QuerierName(opts.Name), querier.Spec.ScaleTargetRef.Name)
+	require.Equal(t, pointer.Int32Ptr(2), querier.Spec.MinReplicas)
+	require.Len(t, querier.Spec.Metrics, 1)
+	require.Equal(t, corev1.ResourceMemory, querier.Spec.Metrics[0].Resource.Name)
+
+	// Replicas of autoscaled deployments are owned by the autoscaler
+	require.Nil(t, NewQuerierDeployment(opts).Spec.Replicas)
+	require.Nil(t, NewDistributorDeployment(opts).Spec.Replicas)
+	require.NotNil(t, NewQueryFrontendDeployment(opts).Spec.Replicas)
+}
@@ -4,8 +4,11 @@ import (
 	"github.com/ViaQ/logerr/kverrors"
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/manifests/internal"
+	"github.com/ViaQ/loki-operator/internal/manifests/openshift"
 
 	"github.com/imdario/mergo"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,6 +53,9 @@ func BuildAll(opts Options) ([]client.Object, error) {
 	}
 
 	res = append(res, cm)
+	if opts.ObjectStorage.SharedStore == lokiv1beta1.ObjectStorageSecretFilesystem {
+		res = append(res, NewSharedStoragePersistentVolumeClaim(opts))
+	}
 	res = append(res, distributorObjs...)
 	res = append(res, ingesterObjs...)
 	res = append(res, querierObjs...)
@@ -57,6 +63,18 @@ func BuildAll(opts Options) ([]client.Object, error) {
 	res = append(res, queryFrontendObjs...)
 	res = append(res, indexGatewayObjs...)
 	res = append(res, BuildLokiGossipRingService(opts.Name))
+	res = append(res, BuildMemcached(opts)...)
+	res = append(res, BuildPodDisruptionBudgets(opts)...)
+	res = append(res, BuildHorizontalPodAutoscalers(opts)...)
+
+	if rulerEnabled(opts.Stack) {
+		rulerObjs, err := BuildRuler(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, rulerObjs...)
+	}
 
 	if opts.Flags.EnableGateway {
 		gatewayObjects, err := BuildGateway(opts)
@@ -67,13 +85,49 @@ func BuildAll(opts Options) ([]client.Object, error) {
 		res = append(res, gatewayObjects...)
 	}
 
+	if serviceCABundleRequired(opts) {
+		res = append(res, newServiceCABundleConfigMap(opts))
+	}
+
 	if opts.Flags.EnableServiceMonitors {
 		res = append(res, BuildServiceMonitors(opts)...)
 	}
 
+	for _, obj := range res {
+		switch obj.(type) {
+		case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+			obj.SetLabels(labels.Merge(obj.GetLabels(), ClusterScopedLabels(opts.Name, opts.Namespace)))
+		}
+	}
+
 	return res, nil
 }
 
+// serviceCABundleRequired returns true if the operator verifies the TLS
+// endpoints of the Loki components with the service CA, but the
+// gateway does not create the service CA bundle configmap.
+func serviceCABundleRequired(opts Options) bool {
+	if !opts.Flags.EnableTLSServiceMonitorConfig || !opts.Flags.EnableCertificateSigningService {
+		return false
+	}
+
+	gatewayOpenShift := opts.Flags.EnableGateway &&
+		opts.Stack.Tenants != nil &&
+		opts.Stack.Tenants.Mode == lokiv1beta1.OpenshiftLogging
+
+	return !gatewayOpenShift
+}
+
+func newServiceCABundleConfigMap(opts Options) client.Object {
+	return openshift.BuildServiceCAConfigMap(openshift.Options{
+		BuildOpts: openshift.BuildOptions{
+			GatewayName:      GatewayName(opts.Name),
+			GatewayNamespace: opts.Namespace,
+			Labels:           ComponentLabels(LabelGatewayComponent, opts.Name),
+		},
+	})
+}
+
 // DefaultLokiStackSpec returns the default configuration for a LokiStack of
 // the specified size
 func DefaultLokiStackSpec(size lokiv1beta1.LokiStackSizeType) *lokiv1beta1.LokiStackSpec {
@@ -81,10 +135,22 @@ func DefaultLokiStackSpec(size lokiv1beta1.LokiStackSizeType) *lokiv1beta1.LokiS
 	return (&defaults).DeepCopy()
 }
 
+// IsSupportedSize returns true if the operator provides a
+// default configuration for a LokiStack of the specified size.
+func IsSupportedSize(size lokiv1beta1.LokiStackSizeType) bool {
+	_, ok := internal.StackSizeTable[size]
+	return ok
+}
+
 // ApplyDefaultSettings manipulates the options to conform to
 // build specifications
 func ApplyDefaultSettings(opts *Options) error {
 	spec := DefaultLokiStackSpec(opts.Stack.Size)
+	resources := internal.ResourceRequirementsTable[opts.Stack.Size]
+	if p := opts.SizeProfile; p != nil {
+		spec = p.Stack.DeepCopy()
+		resources = p.Resources
+	}
 
 	if err := mergo.Merge(spec, opts.Stack, mergo.WithOverride); err != nil {
 		return kverrors.Wrap(err, "failed merging stack user options", "name", opts.Name)
@@ -104,8 +170,59 @@ func ApplyDefaultSettings(opts *Options) error {
 		return kverrors.Wrap(err, "failed to merge strict defaults")
 	}
 
-	opts.ResourceRequirements = internal.ResourceRequirementsTable[opts.Stack.Size]
+	opts.ResourceRequirements = componentResources(resources, *spec)
 	opts.Stack = *spec
 
 	return nil
 }
+
+// ValidateReplication validates the replication factor and zones against the
+// effective number of ingester replicas and the zone values for use in the
+// names of the stack's ingester statefulsets. It expects the spec to be
+// conformed to the build specifications by ApplyDefaultSettings.
+func ValidateReplication(stackName string, spec lokiv1beta1.LokiStackSpec) error {
+	var replicas int32
+	if spec.Template != nil && spec.Template.Ingester != nil {
+		replicas = spec.Template.Ingester.Replicas
+	}
+
+	if spec.ReplicationFactor > replicas {
+		return kverrors.New("replication factor exceeds the number of ingester replicas",
+			"replication_factor", spec.ReplicationFactor,
+			"ingester_replicas", replicas,
+		)
+	}
+
+	if !zoneAwarenessEnabled(spec) {
+		return nil
+	}
+
+	zones := spec.Replication.Zones
+	seen := map[string]bool{}
+	for _, z := range zones {
+		if seen[z.Value] {
+			return kverrors.New("replication zones must be unique", "zone", z.Value)
+		}
+		seen[z.Value] = true
+
+		if err := ValidateZone(stackName, z.Value); err != nil {
+			return err
+		}
+	}
+
+	if spec.ReplicationFactor > int32(len(zones)) {
+		return kverrors.New("replication factor exceeds the number of replication zones",
+			"replication_factor", spec.ReplicationFactor,
+			"zones", len(zones),
+		)
+	}
+
+	if replicas < int32(len(zones)) {
+		return kverrors.New("number of replication zones exceeds the number of ingester replicas",
+			"zones", len(zones),
+			"ingester_replicas", replicas,
+		)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
@@ -16,6 +17,8 @@ func TestApplyUserOptions_OverrideDefaults(t *testing.T) {
 		lokiv1beta1.SizeOneXExtraSmall,
 		lokiv1beta1.SizeOneXSmall,
 		lokiv1beta1.SizeOneXMedium,
+		lokiv1beta1.SizeOneXLarge,
+		lokiv1beta1.SizeTwoXMedium,
 	}
 	for _, size := range allSizes {
 		opt := Options{
@@ -56,6 +59,8 @@ func TestApplyUserOptions_AlwaysSetCompactorReplicasToOne(t *testing.T) {
 		lokiv1beta1.SizeOneXExtraSmall,
 		lokiv1beta1.SizeOneXSmall,
 		lokiv1beta1.SizeOneXMedium,
+		lokiv1beta1.SizeOneXLarge,
+		lokiv1beta1.SizeTwoXMedium,
 	}
 	for _, size := range allSizes {
 		opt := Options{
@@ -80,6 +85,61 @@ func TestApplyUserOptions_AlwaysSetCompactorReplicasToOne(t *testing.T) {
 	}
 }
 
+func TestValidateReplication(t *testing.T) {
+	type test struct {
+		name              string
+		replicationFactor int32
+		ingesterReplicas  int32
+		wantErr           bool
+	}
+	table := []test{
+		{
+			name:              "replication factor equals ingester replicas",
+			replicationFactor: 2,
+			ingesterReplicas:  2,
+		},
+		{
+			name:              "replication factor below ingester replicas",
+			replicationFactor: 2,
+			ingesterReplicas:  3,
+		},
+		{
+			name:              "replication factor exceeds ingester replicas",
+			replicationFactor: 3,
+			ingesterReplicas:  2,
+			wantErr:           true,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			opt := Options{
+				Name:      "abcd",
+				Namespace: "efgh",
+				Stack: lokiv1beta1.LokiStackSpec{
+					Size:              lokiv1beta1.SizeOneXSmall,
+					ReplicationFactor: tst.replicationFactor,
+					Template: &lokiv1beta1.LokiTemplateSpec{
+						Ingester: &lokiv1beta1.LokiComponentSpec{
+							Replicas: tst.ingesterReplicas,
+						},
+					},
+				},
+			}
+			require.NoError(t, ApplyDefaultSettings(&opt))
+
+			err := ValidateReplication(opt.Name, opt.Stack)
+			if tst.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestBuildAll_WithFeatureFlags_EnableServiceMonitors(t *testing.T) {
 	type test struct {
 		desc         string
@@ -120,6 +180,25 @@ func TestBuildAll_WithFeatureFlags_EnableServiceMonitors(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:         "service monitor per component including ruler created",
+			MonitorCount: 8,
+			BuildOptions: Options{
+				Name:      "test",
+				Namespace: "test",
+				Stack: lokiv1beta1.LokiStackSpec{
+					Size: lokiv1beta1.SizeOneXSmall,
+					Rules: &lokiv1beta1.RulesSpec{
+						Enabled: true,
+					},
+				},
+				Flags: FeatureFlags{
+					EnableCertificateSigningService: false,
+					EnableServiceMonitors:           true,
+					EnableTLSServiceMonitorConfig:   false,
+				},
+			},
+		},
 	}
 
 	for _, tst := range table {
@@ -193,6 +272,7 @@ func TestBuildAll_WithFeatureFlags_EnableCertificateSigningService(t *testing.T)
 				NewCompactorHTTPService(tst.BuildOptions),
 				NewIndexGatewayHTTPService(tst.BuildOptions),
 				NewGatewayHTTPService(tst.BuildOptions),
+				NewRulerHTTPService(tst.BuildOptions),
 			}
 
 			for _, service := range httpServices {
@@ -206,6 +286,34 @@ func TestBuildAll_WithFeatureFlags_EnableCertificateSigningService(t *testing.T)
 	}
 }
 
+func TestBuildAll_WithFeatureFlags_EnableTLSServiceMonitorConfig_CreateServiceCABundle(t *testing.T) {
+	opts := Options{
+		Name:      "test",
+		Namespace: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+		},
+		Flags: FeatureFlags{
+			EnableCertificateSigningService: true,
+			EnableTLSServiceMonitorConfig:   true,
+		},
+	}
+	err := ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	objects, err := BuildAll(opts)
+	require.NoError(t, err)
+
+	var cms []*corev1.ConfigMap
+	for _, obj := range objects {
+		if cm, ok := obj.(*corev1.ConfigMap); ok && cm.Name == "lokistack-gateway-test-ca-bundle" {
+			cms = append(cms, cm)
+		}
+	}
+	require.Len(t, cms, 1)
+	require.Equal(t, "true", cms[0].Annotations["service.beta.openshift.io/inject-cabundle"])
+}
+
 func TestBuildAll_WithFeatureFlags_EnableGateway(t *testing.T) {
 	type test struct {
 		desc         string
@@ -281,6 +389,88 @@ func TestBuildAll_WithFeatureFlags_EnableGateway(t *testing.T) {
 	}
 }
 
+func TestBuildAll_ClusterScopedObjectsLabeledWithNamespace(t *testing.T) {
+	opts := Options{
+		Name:      "test",
+		Namespace: "test-ns",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Tenants: &lokiv1beta1.TenantsSpec{
+				Mode: lokiv1beta1.OpenshiftLogging,
+			},
+		},
+		Flags: FeatureFlags{
+			EnableGateway: true,
+		},
+	}
+	err := ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+	err = ApplyGatewayDefaultOptions(&opts)
+	require.NoError(t, err)
+
+	objects, err := BuildAll(opts)
+	require.NoError(t, err)
+
+	var clusterScoped int
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *rbacv1.ClusterRole, *rbacv1.ClusterRoleBinding:
+			clusterScoped++
+			require.Equal(t, "test-ns", obj.GetLabels()["loki.grafana.com/namespace"])
+		default:
+			require.NotContains(t, obj.GetLabels(), "loki.grafana.com/namespace")
+		}
+	}
+	require.Equal(t, 2, clusterScoped)
+}
+
+func TestBuildAll_WithRulesEnabled(t *testing.T) {
+	type test struct {
+		desc         string
+		BuildOptions Options
+	}
+	table := []test{
+		{
+			desc: "no ruler created",
+			BuildOptions: Options{
+				Name:      "test",
+				Namespace: "test",
+				Stack: lokiv1beta1.LokiStackSpec{
+					Size: lokiv1beta1.SizeOneXSmall,
+				},
+			},
+		},
+		{
+			desc: "ruler created",
+			BuildOptions: Options{
+				Name:      "test",
+				Namespace: "test",
+				Stack: lokiv1beta1.LokiStackSpec{
+					Size: lokiv1beta1.SizeOneXSmall,
+					Rules: &lokiv1beta1.RulesSpec{
+						Enabled: true,
+					},
+				},
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			t.Parallel()
+			err := ApplyDefaultSettings(&tst.BuildOptions)
+			require.NoError(t, err)
+			objects, buildErr := BuildAll(tst.BuildOptions)
+			require.NoError(t, buildErr)
+			if tst.BuildOptions.Stack.Rules != nil && tst.BuildOptions.Stack.Rules.Enabled {
+				require.True(t, checkRulerDeployed(objects, tst.BuildOptions.Name))
+			} else {
+				require.False(t, checkRulerDeployed(objects, tst.BuildOptions.Name))
+			}
+		})
+	}
+}
+
 func serviceMonitorCount(objects []client.Object) int {
 	monitors := 0
 	for _, obj := range objects {
@@ -300,3 +490,13 @@ func checkGatewayDeployed(objects []client.Object, stackName string) bool {
 	}
 	return false
 }
+
+func checkRulerDeployed(objects []client.Object, stackName string) bool {
+	for _, obj := range objects {
+		if obj.GetObjectKind().GroupVersionKind().Kind == "StatefulSet" &&
+			obj.GetName() == RulerName(stackName) {
+			return true
+		}
+	}
+	return false
+}
@@ -25,6 +25,10 @@ func BuildCompactor(opts Options) ([]client.Object, error) {
 		}
 	}
 
+	if err := configureObjectStorage(&statefulSet.Spec.Template, opts); err != nil {
+		return nil, err
+	}
+
 	return []client.Object{
 		statefulSet,
 		NewCompactorGRPCService(opts),
@@ -42,7 +46,7 @@ func NewCompactorStatefulSet(opts Options) *appsv1.StatefulSet {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -60,6 +64,7 @@ func NewCompactorStatefulSet(opts Options) *appsv1.StatefulSet {
 					"-target=compactor",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -120,8 +125,7 @@ func NewCompactorStatefulSet(opts Options) *appsv1.StatefulSet {
 	}
 
 	if opts.Stack.Template != nil && opts.Stack.Template.Compactor != nil {
-		podSpec.Tolerations = opts.Stack.Template.Compactor.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.Compactor.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.Compactor)
 	}
 
 	l := ComponentLabels(LabelCompactorComponent, opts.Name)
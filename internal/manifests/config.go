@@ -3,7 +3,10 @@ package manifests
 import (
 	"crypto/sha1"
 	"fmt"
+	"path"
+	"strings"
 
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +33,7 @@ func LokiConfigMap(opt Options) (*corev1.ConfigMap, string, error) {
 			APIVersion: corev1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   lokiConfigMapName(opt.Name),
+			Name:   LokiConfigMapName(opt.Name),
 			Labels: commonLabels(opt.Name),
 		},
 		BinaryData: map[string][]byte{
@@ -63,13 +66,7 @@ func ConfigOptions(opt Options) config.Options {
 			Port: grpcPort,
 		},
 		StorageDirectory: dataDirectory,
-		ObjectStorage: config.ObjectStorage{
-			Endpoint:        opt.ObjectStorage.Endpoint,
-			Buckets:         opt.ObjectStorage.Buckets,
-			Region:          opt.ObjectStorage.Region,
-			AccessKeyID:     opt.ObjectStorage.AccessKeyID,
-			AccessKeySecret: opt.ObjectStorage.AccessKeySecret,
-		},
+		ObjectStorage:    objectStorageConfig(opt.ObjectStorage, opt.Stack.Storage),
 		QueryParallelism: config.Parallelism{
 			QuerierCPULimits:      opt.ResourceRequirements.Querier.Requests.Cpu().Value(),
 			QueryFrontendReplicas: opt.Stack.Template.QueryFrontend.Replicas,
@@ -78,9 +75,76 @@ func ConfigOptions(opt Options) config.Options {
 			Directory:             walDirectory,
 			IngesterMemoryRequest: opt.ResourceRequirements.Ingester.Requests.Memory().Value(),
 		},
+		Ruler:     rulerConfig(opt.Stack),
+		Retention: retentionConfig(opt.Stack),
+		Caches:    cachesConfig(opt),
+		Schemas:   schemaConfig(opt.Stack),
+
+		ZoneAwarenessEnabled: zoneAwarenessEnabled(opt.Stack),
+	}
+}
+
+func rulerConfig(spec lokiv1beta1.LokiStackSpec) config.Ruler {
+	if !rulerEnabled(spec) {
+		return config.Ruler{}
+	}
+
+	return config.Ruler{
+		Enabled:               true,
+		RulesStorageDirectory: rulesStorageDirectory,
+		AlertManagerURL:       strings.Join(spec.Rules.AlertManagerEndpoints, ","),
 	}
 }
 
-func lokiConfigMapName(stackName string) string {
+func objectStorageConfig(s ObjectStorage, spec lokiv1beta1.ObjectStorageSpec) config.ObjectStorage {
+	cfg := config.ObjectStorage{
+		SharedStore: s.SharedStore,
+		Prefix:      spec.Prefix,
+	}
+
+	switch s.SharedStore {
+	case lokiv1beta1.ObjectStorageSecretAzure:
+		if s.Azure != nil {
+			c := config.AzureStorage(*s.Azure)
+			cfg.Azure = &c
+		}
+	case lokiv1beta1.ObjectStorageSecretGCS:
+		if s.GCS != nil {
+			c := config.GCSStorage(*s.GCS)
+			cfg.GCS = &c
+		}
+	case lokiv1beta1.ObjectStorageSecretSwift:
+		if s.Swift != nil {
+			c := config.SwiftStorage(*s.Swift)
+			cfg.Swift = &c
+		}
+	case lokiv1beta1.ObjectStorageSecretFilesystem:
+		cfg.Filesystem = &config.FilesystemStorage{
+			Directory: path.Join(sharedStorageDirectory, "chunks"),
+		}
+	default:
+		cfg.SharedStore = lokiv1beta1.ObjectStorageSecretS3
+		if s.S3 != nil {
+			cfg.S3 = &config.S3Storage{
+				Endpoint:           s.S3.Endpoint,
+				Region:             s.S3.Region,
+				Buckets:            s.S3.Buckets,
+				VirtualHostedStyle: spec.AddressingStyle == lokiv1beta1.ObjectStorageAddressingVirtualHosted,
+				Insecure:           spec.Insecure,
+			}
+			if sse := spec.SSE; sse != nil {
+				cfg.S3.SSE = &config.S3SSE{
+					Type:     string(sse.Type),
+					KMSKeyID: sse.KMSKeyID,
+				}
+			}
+		}
+	}
+
+	return cfg
+}
+
+// LokiConfigMapName is the name of the configmap holding the Loki configuration of the stack.
+func LokiConfigMapName(stackName string) string {
 	return fmt.Sprintf("loki-config-%s", stackName)
 }
@@ -10,17 +10,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	walVolumeName     = "wal"
-	configVolumeName  = "config"
-	storageVolumeName = "storage"
-	walDirectory      = "/tmp/wal"
-	dataDirectory     = "/tmp/loki"
-	secretDirectory   = "/etc/proxy/secrets"
+	walVolumeName         = "wal"
+	configVolumeName      = "config"
+	rulesVolumeName       = "rules"
+	storageVolumeName     = "storage"
+	walDirectory          = "/tmp/wal"
+	dataDirectory         = "/tmp/loki"
+	rulesStorageDirectory = "/tmp/rules"
+	secretDirectory       = "/etc/proxy/secrets"
 )
 
 // BuildDistributor returns a list of k8s objects for Loki Distributor
@@ -32,6 +33,10 @@ func BuildDistributor(opts Options) ([]client.Object, error) {
 		}
 	}
 
+	if err := configureObjectStorage(&deployment.Spec.Template, opts); err != nil {
+		return nil, err
+	}
+
 	return []client.Object{
 		deployment,
 		NewDistributorGRPCService(opts),
@@ -49,7 +54,7 @@ func NewDistributorDeployment(opts Options) *appsv1.Deployment {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -73,6 +78,7 @@ func NewDistributorDeployment(opts Options) *appsv1.Deployment {
 					"-target=distributor",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -137,9 +143,10 @@ func NewDistributorDeployment(opts Options) *appsv1.Deployment {
 		},
 	}
 
+	podSpec.TopologySpreadConstraints = defaultTopologySpreadConstraints(ComponentLabels(LabelDistributorComponent, opts.Name))
+
 	if opts.Stack.Template != nil && opts.Stack.Template.Distributor != nil {
-		podSpec.Tolerations = opts.Stack.Template.Distributor.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.Distributor.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.Distributor)
 	}
 
 	l := ComponentLabels(LabelDistributorComponent, opts.Name)
@@ -155,7 +162,7 @@ func NewDistributorDeployment(opts Options) *appsv1.Deployment {
 			Labels: l,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(opts.Stack.Template.Distributor.Replicas),
+			Replicas: deploymentReplicas(opts.Stack.Template.Distributor),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels.Merge(l, GossipLabels()),
 			},
@@ -176,9 +176,45 @@ func NewGatewayDeployment(opts Options, sha1C string) *appsv1.Deployment {
 		},
 	}
 
+	if rulerEnabled(opts.Stack) {
+		podSpec.Containers[0].Args = append(podSpec.Containers[0].Args,
+			fmt.Sprintf("--logs.rules.endpoint=http://%s:%d", fqdn(serviceNameRulerHTTP(opts.Name), opts.Namespace), httpPort),
+			"--logs.rules.read-only=true",
+		)
+	}
+
 	l := ComponentLabels(LabelGatewayComponent, opts.Name)
 	a := commonAnnotations(sha1C)
 
+	// Spread the gateway replicas across nodes as it is the single entry
+	// point for all log traffic. The anti-affinity is preferred only to
+	// keep the gateway schedulable on clusters with fewer nodes than replicas.
+	podSpec.Affinity = &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: l,
+						},
+						TopologyKey: topologyKeyHostname,
+					},
+				},
+			},
+		},
+	}
+	podSpec.TopologySpreadConstraints = defaultTopologySpreadConstraints(l)
+
+	replicas := pointer.Int32Ptr(1)
+	if opts.Stack.Template != nil && opts.Stack.Template.Gateway != nil {
+		replicas = deploymentReplicas(opts.Stack.Template.Gateway)
+		configureScheduling(&podSpec, opts.Stack.Template.Gateway)
+	}
+
+	maxUnavailable := intstr.FromInt(0)
+	maxSurge := intstr.FromInt(1)
+
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Deployment",
@@ -189,7 +225,7 @@ func NewGatewayDeployment(opts Options, sha1C string) *appsv1.Deployment {
 			Labels: l,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(1),
+			Replicas: replicas,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: l,
 			},
@@ -203,6 +239,10 @@ func NewGatewayDeployment(opts Options, sha1C string) *appsv1.Deployment {
 			},
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxUnavailable: &maxUnavailable,
+					MaxSurge:       &maxSurge,
+				},
 			},
 		},
 	}
@@ -48,6 +48,28 @@ func TestNewGatewayDeployment_HasTemplateConfigHashAnnotation(t *testing.T) {
 	require.Equal(t, annotations[expected], sha1C)
 }
 
+func TestNewGatewayDeployment_HasRulesEndpointWhenRulesEnabled(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Rules: &lokiv1beta1.RulesSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	dpl := NewGatewayDeployment(opts, "deadbeef")
+	require.Contains(t, dpl.Spec.Template.Spec.Containers[0].Args, "--logs.rules.endpoint=http://loki-ruler-http-abcd.efgh.svc.cluster.local:3100")
+	require.Contains(t, dpl.Spec.Template.Spec.Containers[0].Args, "--logs.rules.read-only=true")
+
+	opts.Stack.Rules.Enabled = false
+	dpl = NewGatewayDeployment(opts, "deadbeef")
+	for _, arg := range dpl.Spec.Template.Spec.Containers[0].Args {
+		require.NotContains(t, arg, "--logs.rules")
+	}
+}
+
 func TestGatewayConfigMap_ReturnsSHA1OfBinaryContents(t *testing.T) {
 	opts := Options{
 		Name:      uuid.New().String(),
@@ -276,3 +298,41 @@ func TestBuildGateway_WithExtraObjectsForTenantMode_ReplacesIngressWithRoute(t *
 	require.NotContains(t, kinds, "*v1.Ingress")
 	require.Contains(t, kinds, "*v1.Route")
 }
+
+func TestNewGatewayDeployment_HonorsGatewayTemplate(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Gateway: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 3,
+					NodeSelector: map[string]string{
+						"node-role.kubernetes.io/infra": "",
+					},
+					Tolerations: []corev1.Toleration{
+						{
+							Key:    "node-role.kubernetes.io/infra",
+							Effect: corev1.TaintEffectNoSchedule,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	dpl := NewGatewayDeployment(opts, "deadbeef")
+
+	require.Equal(t, int32(3), *dpl.Spec.Replicas)
+	require.Equal(t, opts.Stack.Template.Gateway.NodeSelector, dpl.Spec.Template.Spec.NodeSelector)
+	require.Equal(t, opts.Stack.Template.Gateway.Tolerations, dpl.Spec.Template.Spec.Tolerations)
+
+	require.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, dpl.Spec.Strategy.Type)
+	require.NotNil(t, dpl.Spec.Strategy.RollingUpdate)
+	require.Equal(t, 0, dpl.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue())
+
+	terms := dpl.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	require.Len(t, terms, 1)
+	require.Equal(t, "kubernetes.io/hostname", terms[0].PodAffinityTerm.TopologyKey)
+	require.Equal(t, dpl.Spec.Selector.MatchLabels, terms[0].PodAffinityTerm.LabelSelector.MatchLabels)
+}
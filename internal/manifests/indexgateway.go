@@ -24,6 +24,10 @@ func BuildIndexGateway(opts Options) ([]client.Object, error) {
 		}
 	}
 
+	if err := configureObjectStorage(&statefulSet.Spec.Template, opts); err != nil {
+		return nil, err
+	}
+
 	return []client.Object{
 		statefulSet,
 		NewIndexGatewayGRPCService(opts),
@@ -41,7 +45,7 @@ func NewIndexGatewayStatefulSet(opts Options) *appsv1.StatefulSet {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -59,6 +63,7 @@ func NewIndexGatewayStatefulSet(opts Options) *appsv1.StatefulSet {
 					"-target=index-gateway",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -119,8 +124,7 @@ func NewIndexGatewayStatefulSet(opts Options) *appsv1.StatefulSet {
 	}
 
 	if opts.Stack.Template != nil && opts.Stack.Template.IndexGateway != nil {
-		podSpec.Tolerations = opts.Stack.Template.IndexGateway.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.IndexGateway.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.IndexGateway)
 	}
 
 	l := ComponentLabels(LabelIndexGatewayComponent, opts.Name)
@@ -17,18 +17,30 @@ import (
 
 // BuildIngester builds the k8s objects required to run Loki Ingester
 func BuildIngester(opts Options) ([]client.Object, error) {
-	statefulSet := NewIngesterStatefulSet(opts)
-	if opts.Flags.EnableTLSServiceMonitorConfig {
-		if err := configureIngesterServiceMonitorPKI(statefulSet, opts.Name); err != nil {
+	statefulSets := []*appsv1.StatefulSet{NewIngesterStatefulSet(opts)}
+	if zoneAwarenessEnabled(opts.Stack) {
+		statefulSets = newIngesterZoneStatefulSets(opts)
+	}
+
+	var objs []client.Object
+	for _, statefulSet := range statefulSets {
+		if opts.Flags.EnableTLSServiceMonitorConfig {
+			if err := configureIngesterServiceMonitorPKI(statefulSet, opts.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := configureObjectStorage(&statefulSet.Spec.Template, opts); err != nil {
 			return nil, err
 		}
+
+		objs = append(objs, statefulSet)
 	}
 
-	return []client.Object{
-		statefulSet,
+	return append(objs,
 		NewIngesterGRPCService(opts),
 		NewIngesterHTTPService(opts),
-	}, nil
+	), nil
 }
 
 // NewIngesterStatefulSet creates a deployment object for an ingester
@@ -41,7 +53,7 @@ func NewIngesterStatefulSet(opts Options) *appsv1.StatefulSet {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -59,6 +71,7 @@ func NewIngesterStatefulSet(opts Options) *appsv1.StatefulSet {
 					"-target=ingester",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -128,9 +141,10 @@ func NewIngesterStatefulSet(opts Options) *appsv1.StatefulSet {
 		},
 	}
 
+	podSpec.TopologySpreadConstraints = ingesterTopologySpreadConstraints(ComponentLabels(LabelIngesterComponent, opts.Name))
+
 	if opts.Stack.Template != nil && opts.Stack.Template.Ingester != nil {
-		podSpec.Tolerations = opts.Stack.Template.Ingester.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.Ingester.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.Ingester)
 	}
 
 	l := ComponentLabels(LabelIngesterComponent, opts.Name)
@@ -5,6 +5,7 @@ import (
 
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
 )
 
 func TestBuild_ConfigAndRuntimeConfig_NoRuntimeConfigGenerated(t *testing.T) {
@@ -13,9 +14,9 @@ func TestBuild_ConfigAndRuntimeConfig_NoRuntimeConfigGenerated(t *testing.T) {
 auth_enabled: true
 chunk_store_config:
   chunk_cache_config:
-    enable_fifocache: true
-    fifocache:
-      max_size_bytes: 500MB
+    embedded_cache:
+      enabled: true
+      max_size_mb: 500
 compactor:
   compaction_interval: 2h
   shared_store: s3
@@ -88,6 +89,460 @@ limits_config:
   cardinality_limit: 100000
   max_streams_matchers_per_query: 1000
   max_cache_freshness_per_query: 10m
+  split_queries_by_interval: 30m
+  per_stream_rate_limit: 3MB
+  per_stream_rate_limit_burst: 15MB
+memberlist:
+  abort_if_cluster_join_fails: true
+  bind_port: 7946
+  join_members:
+    - loki-gossip-ring-lokistack-dev.default.svc.cluster.local:7946
+  max_join_backoff: 1m
+  max_join_retries: 10
+  min_join_backoff: 1s
+querier:
+  engine:
+    max_look_back_period: 30s
+    timeout: 3m
+  extra_query_delay: 0s
+  query_ingesters_within: 2h
+  query_timeout: 1m
+  tail_max_duration: 1h
+query_range:
+  align_queries_with_step: true
+  cache_results: true
+  max_retries: 5
+  results_cache:
+    cache:
+      embedded_cache:
+        enabled: true
+        max_size_mb: 500
+  parallelise_shardable_queries: false
+schema_config:
+  configs:
+    - from: "2020-10-01"
+      index:
+        period: 24h
+        prefix: index_
+      object_store: s3
+      schema: v11
+      store: boltdb-shipper
+server:
+  graceful_shutdown_timeout: 5s
+  grpc_server_min_time_between_pings: '10s'
+  grpc_server_ping_without_stream_allowed: true
+  grpc_server_max_concurrent_streams: 1000
+  grpc_server_max_recv_msg_size: 104857600
+  grpc_server_max_send_msg_size: 104857600
+  http_listen_port: 3100
+  http_server_idle_timeout: 120s
+  http_server_write_timeout: 1m
+  log_level: info
+storage_config:
+  boltdb_shipper:
+    active_index_directory: /tmp/loki/index
+    cache_location: /tmp/loki/index_cache
+    cache_ttl: 24h
+    resync_interval: 5m
+    shared_store: s3
+    index_gateway_client:
+      server_address: dns:///loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local:9095
+  aws:
+    s3: http://test.default.svc.cluster.local.:9000
+    bucketnames: loki
+    region: us-east
+    access_key_id: ${AWS_ACCESS_KEY_ID}
+    secret_access_key: ${AWS_ACCESS_KEY_SECRET}
+    s3forcepathstyle: true
+tracing:
+  enabled: false
+`
+	expRCfg := `
+---
+overrides:
+`
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+						IngestionRate:             4,
+						IngestionBurstSize:        6,
+						MaxLabelNameLength:        1024,
+						MaxLabelValueLength:       2048,
+						MaxLabelNamesPerSeries:    30,
+						MaxGlobalStreamsPerTenant: 0,
+						MaxLineSize:               256000,
+					},
+					QueryLimits: &lokiv1beta1.QueryLimitSpec{
+						MaxEntriesLimitPerQuery: 5000,
+						MaxChunksPerQuery:       2000000,
+						MaxQuerySeries:          500,
+					},
+				},
+			},
+		},
+		Namespace: "test-ns",
+		Name:      "test",
+		FrontendWorker: Address{
+			FQDN: "loki-query-frontend-grpc-lokistack-dev.default.svc.cluster.local",
+			Port: 9095,
+		},
+		GossipRing: Address{
+			FQDN: "loki-gossip-ring-lokistack-dev.default.svc.cluster.local",
+			Port: 7946,
+		},
+		Querier: Address{
+			FQDN: "loki-querier-http-lokistack-dev.default.svc.cluster.local",
+			Port: 3100,
+		},
+		IndexGateway: Address{
+			FQDN: "loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local",
+			Port: 9095,
+		},
+		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+		ObjectStorage: ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			S3: &S3Storage{
+				Endpoint: "http://test.default.svc.cluster.local.:9000",
+				Region:   "us-east",
+				Buckets:  "loki",
+			},
+		},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+		WriteAheadLog: WriteAheadLog{
+			Directory:             "/tmp/wal",
+			IngesterMemoryRequest: 5000,
+		},
+	}
+	cfg, rCfg, err := Build(opts)
+	require.NoError(t, err)
+	require.YAMLEq(t, expCfg, string(cfg))
+	require.YAMLEq(t, expRCfg, string(rCfg))
+}
+
+func TestBuild_ConfigAndRuntimeConfig_RulerConfigGenerated(t *testing.T) {
+	expCfg := `
+---
+auth_enabled: true
+chunk_store_config:
+  chunk_cache_config:
+    embedded_cache:
+      enabled: true
+      max_size_mb: 500
+compactor:
+  compaction_interval: 2h
+  shared_store: s3
+  working_directory: /tmp/loki/compactor
+frontend:
+  tail_proxy_url: http://loki-querier-http-lokistack-dev.default.svc.cluster.local:3100
+  compress_responses: true
+  max_outstanding_per_tenant: 256
+  log_queries_longer_than: 5s
+frontend_worker:
+  frontend_address: loki-query-frontend-grpc-lokistack-dev.default.svc.cluster.local:9095
+  grpc_client_config:
+    max_send_msg_size: 104857600
+  parallelism: 1
+ingester:
+  chunk_block_size: 262144
+  chunk_encoding: snappy
+  chunk_idle_period: 1h
+  chunk_retain_period: 30s
+  chunk_target_size: 1048576
+  lifecycler:
+    final_sleep: 0s
+    heartbeat_period: 5s
+    interface_names:
+      - eth0
+    join_after: 30s
+    num_tokens: 512
+    ring:
+      replication_factor: 1
+      heartbeat_timeout: 1m
+  max_transfer_retries: 0
+  wal:
+    enabled: true
+    dir: /tmp/wal
+    replay_memory_ceiling: 2500
+ingester_client:
+  grpc_client_config:
+    max_recv_msg_size: 67108864
+  remote_timeout: 1s
+# NOTE: Keep the order of keys as in Loki docs
+# to enable easy diffs when vendoring newer
+# Loki releases.
+# (See https://grafana.com/docs/loki/latest/configuration/#limits_config)
+#
+# Values for not exposed fields are taken from the grafana/loki production
+# configuration manifests.
+# (See https://github.com/grafana/loki/blob/main/production/ksonnet/loki/config.libsonnet)
+limits_config:
+  ingestion_rate_strategy: global
+  ingestion_rate_mb: 4
+  ingestion_burst_size_mb: 6
+  max_label_name_length: 1024
+  max_label_value_length: 2048
+  max_label_names_per_series: 30
+  reject_old_samples: true
+  reject_old_samples_max_age: 168h
+  creation_grace_period: 10m
+  enforce_metric_name: false
+  # Keep max_streams_per_user always to 0 to default
+  # using max_global_streams_per_user always.
+  # (See https://github.com/grafana/loki/blob/main/pkg/ingester/limiter.go#L73)
+  max_streams_per_user: 0
+  max_line_size: 256000
+  max_entries_limit_per_query: 5000
+  max_global_streams_per_user: 0
+  max_chunks_per_query: 2000000
+  max_query_length: 721h
+  max_query_parallelism: 32
+  max_query_series: 500
+  cardinality_limit: 100000
+  max_streams_matchers_per_query: 1000
+  max_cache_freshness_per_query: 10m
+  split_queries_by_interval: 30m
+  per_stream_rate_limit: 3MB
+  per_stream_rate_limit_burst: 15MB
+memberlist:
+  abort_if_cluster_join_fails: true
+  bind_port: 7946
+  join_members:
+    - loki-gossip-ring-lokistack-dev.default.svc.cluster.local:7946
+  max_join_backoff: 1m
+  max_join_retries: 10
+  min_join_backoff: 1s
+querier:
+  engine:
+    max_look_back_period: 30s
+    timeout: 3m
+  extra_query_delay: 0s
+  query_ingesters_within: 2h
+  query_timeout: 1m
+  tail_max_duration: 1h
+query_range:
+  align_queries_with_step: true
+  cache_results: true
+  max_retries: 5
+  results_cache:
+    cache:
+      embedded_cache:
+        enabled: true
+        max_size_mb: 500
+  parallelise_shardable_queries: false
+ruler:
+  enable_api: true
+  enable_sharding: true
+  evaluation_interval: 1m
+  poll_interval: 1m
+  alertmanager_url: http://alertmanager-0:9093,http://alertmanager-1:9093
+  enable_alertmanager_v2: true
+  rule_path: /tmp/loki/scratch
+  storage:
+    type: local
+    local:
+      directory: /tmp/rules
+schema_config:
+  configs:
+    - from: "2020-10-01"
+      index:
+        period: 24h
+        prefix: index_
+      object_store: s3
+      schema: v11
+      store: boltdb-shipper
+server:
+  graceful_shutdown_timeout: 5s
+  grpc_server_min_time_between_pings: '10s'
+  grpc_server_ping_without_stream_allowed: true
+  grpc_server_max_concurrent_streams: 1000
+  grpc_server_max_recv_msg_size: 104857600
+  grpc_server_max_send_msg_size: 104857600
+  http_listen_port: 3100
+  http_server_idle_timeout: 120s
+  http_server_write_timeout: 1m
+  log_level: info
+storage_config:
+  boltdb_shipper:
+    active_index_directory: /tmp/loki/index
+    cache_location: /tmp/loki/index_cache
+    cache_ttl: 24h
+    resync_interval: 5m
+    shared_store: s3
+    index_gateway_client:
+      server_address: dns:///loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local:9095
+  aws:
+    s3: http://test.default.svc.cluster.local.:9000
+    bucketnames: loki
+    region: us-east
+    access_key_id: ${AWS_ACCESS_KEY_ID}
+    secret_access_key: ${AWS_ACCESS_KEY_SECRET}
+    s3forcepathstyle: true
+tracing:
+  enabled: false
+`
+	expRCfg := `
+---
+overrides:
+`
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+						IngestionRate:             4,
+						IngestionBurstSize:        6,
+						MaxLabelNameLength:        1024,
+						MaxLabelValueLength:       2048,
+						MaxLabelNamesPerSeries:    30,
+						MaxGlobalStreamsPerTenant: 0,
+						MaxLineSize:               256000,
+					},
+					QueryLimits: &lokiv1beta1.QueryLimitSpec{
+						MaxEntriesLimitPerQuery: 5000,
+						MaxChunksPerQuery:       2000000,
+						MaxQuerySeries:          500,
+					},
+				},
+			},
+		},
+		Namespace: "test-ns",
+		Name:      "test",
+		FrontendWorker: Address{
+			FQDN: "loki-query-frontend-grpc-lokistack-dev.default.svc.cluster.local",
+			Port: 9095,
+		},
+		GossipRing: Address{
+			FQDN: "loki-gossip-ring-lokistack-dev.default.svc.cluster.local",
+			Port: 7946,
+		},
+		Querier: Address{
+			FQDN: "loki-querier-http-lokistack-dev.default.svc.cluster.local",
+			Port: 3100,
+		},
+		IndexGateway: Address{
+			FQDN: "loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local",
+			Port: 9095,
+		},
+		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+		ObjectStorage: ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			S3: &S3Storage{
+				Endpoint: "http://test.default.svc.cluster.local.:9000",
+				Region:   "us-east",
+				Buckets:  "loki",
+			},
+		},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+		WriteAheadLog: WriteAheadLog{
+			Directory:             "/tmp/wal",
+			IngesterMemoryRequest: 5000,
+		},
+		Ruler: Ruler{
+			Enabled:               true,
+			RulesStorageDirectory: "/tmp/rules",
+			AlertManagerURL:       "http://alertmanager-0:9093,http://alertmanager-1:9093",
+		},
+	}
+	cfg, rCfg, err := Build(opts)
+	require.NoError(t, err)
+	require.YAMLEq(t, expCfg, string(cfg))
+	require.YAMLEq(t, expRCfg, string(rCfg))
+}
+
+func TestBuild_ConfigAndRuntimeConfig_BothGenerated(t *testing.T) {
+	expCfg := `
+---
+auth_enabled: true
+chunk_store_config:
+  chunk_cache_config:
+    embedded_cache:
+      enabled: true
+      max_size_mb: 500
+compactor:
+  compaction_interval: 2h
+  shared_store: s3
+  working_directory: /tmp/loki/compactor
+frontend:
+  tail_proxy_url: http://loki-querier-http-lokistack-dev.default.svc.cluster.local:3100
+  compress_responses: true
+  max_outstanding_per_tenant: 256
+  log_queries_longer_than: 5s
+frontend_worker:
+  frontend_address: loki-query-frontend-grpc-lokistack-dev.default.svc.cluster.local:9095
+  grpc_client_config:
+    max_send_msg_size: 104857600
+  parallelism: 1
+ingester:
+  chunk_block_size: 262144
+  chunk_encoding: snappy
+  chunk_idle_period: 1h
+  chunk_retain_period: 30s
+  chunk_target_size: 1048576
+  lifecycler:
+    final_sleep: 0s
+    heartbeat_period: 5s
+    interface_names:
+      - eth0
+    join_after: 30s
+    num_tokens: 512
+    ring:
+      replication_factor: 1
+      heartbeat_timeout: 1m
+  max_transfer_retries: 0
+  wal:
+    enabled: true
+    dir: /tmp/wal
+    replay_memory_ceiling: 2500
+ingester_client:
+  grpc_client_config:
+    max_recv_msg_size: 67108864
+  remote_timeout: 1s
+# NOTE: Keep the order of keys as in Loki docs
+# to enable easy diffs when vendoring newer
+# Loki releases.
+# (See https://grafana.com/docs/loki/latest/configuration/#limits_config)
+#
+# Values for not exposed fields are taken from the grafana/loki production
+# configuration manifests.
+# (See https://github.com/grafana/loki/blob/main/production/ksonnet/loki/config.libsonnet)
+limits_config:
+  ingestion_rate_strategy: global
+  ingestion_rate_mb: 4
+  ingestion_burst_size_mb: 6
+  max_label_name_length: 1024
+  max_label_value_length: 2048
+  max_label_names_per_series: 30
+  reject_old_samples: true
+  reject_old_samples_max_age: 168h
+  creation_grace_period: 10m
+  enforce_metric_name: false
+  # Keep max_streams_per_user always to 0 to default
+  # using max_global_streams_per_user always.
+  # (See https://github.com/grafana/loki/blob/main/pkg/ingester/limiter.go#L73)
+  max_streams_per_user: 0
+  max_line_size: 256000
+  max_entries_limit_per_query: 5000
+  max_global_streams_per_user: 0
+  max_chunks_per_query: 2000000
+  max_query_length: 721h
+  max_query_parallelism: 32
+  max_query_series: 500
+  cardinality_limit: 100000
+  max_streams_matchers_per_query: 1000
+  max_cache_freshness_per_query: 10m
+  split_queries_by_interval: 30m
   per_stream_rate_limit: 3MB
   per_stream_rate_limit_burst: 15MB
 memberlist:
@@ -112,10 +567,9 @@ query_range:
   max_retries: 5
   results_cache:
     cache:
-      enable_fifocache: true
-      fifocache:
-        max_size_bytes: 500MB
-  split_queries_by_interval: 30m
+      embedded_cache:
+        enabled: true
+        max_size_mb: 500
   parallelise_shardable_queries: false
 schema_config:
   configs:
@@ -150,8 +604,8 @@ storage_config:
     s3: http://test.default.svc.cluster.local.:9000
     bucketnames: loki
     region: us-east
-    access_key_id: test
-    secret_access_key: test123
+    access_key_id: ${AWS_ACCESS_KEY_ID}
+    secret_access_key: ${AWS_ACCESS_KEY_SECRET}
     s3forcepathstyle: true
 tracing:
   enabled: false
@@ -159,6 +613,11 @@ tracing:
 	expRCfg := `
 ---
 overrides:
+  test-a:
+    ingestion_rate_mb: 2
+    ingestion_burst_size_mb: 5
+    max_global_streams_per_user: 1
+    max_chunks_per_query: 1000000
 `
 	opts := Options{
 		Stack: lokiv1beta1.LokiStackSpec{
@@ -180,6 +639,18 @@ overrides:
 						MaxQuerySeries:          500,
 					},
 				},
+				Tenants: map[string]lokiv1beta1.LimitsTemplateSpec{
+					"test-a": {
+						IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+							IngestionRate:             2,
+							IngestionBurstSize:        5,
+							MaxGlobalStreamsPerTenant: 1,
+						},
+						QueryLimits: &lokiv1beta1.QueryLimitSpec{
+							MaxChunksPerQuery: 1000000,
+						},
+					},
+				},
 			},
 		},
 		Namespace: "test-ns",
@@ -201,12 +672,14 @@ overrides:
 			Port: 9095,
 		},
 		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
 		ObjectStorage: ObjectStorage{
-			Endpoint:        "http://test.default.svc.cluster.local.:9000",
-			Region:          "us-east",
-			Buckets:         "loki",
-			AccessKeyID:     "test",
-			AccessKeySecret: "test123",
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			S3: &S3Storage{
+				Endpoint: "http://test.default.svc.cluster.local.:9000",
+				Region:   "us-east",
+				Buckets:  "loki",
+			},
 		},
 		QueryParallelism: Parallelism{
 			QuerierCPULimits:      2,
@@ -223,19 +696,22 @@ overrides:
 	require.YAMLEq(t, expRCfg, string(rCfg))
 }
 
-func TestBuild_ConfigAndRuntimeConfig_BothGenerated(t *testing.T) {
+func TestBuild_ConfigAndRuntimeConfig_RetentionConfigGenerated(t *testing.T) {
 	expCfg := `
 ---
 auth_enabled: true
 chunk_store_config:
   chunk_cache_config:
-    enable_fifocache: true
-    fifocache:
-      max_size_bytes: 500MB
+    embedded_cache:
+      enabled: true
+      max_size_mb: 500
 compactor:
   compaction_interval: 2h
   shared_store: s3
   working_directory: /tmp/loki/compactor
+  retention_enabled: true
+  retention_delete_delay: 4h
+  retention_delete_worker_count: 150
 frontend:
   tail_proxy_url: http://loki-querier-http-lokistack-dev.default.svc.cluster.local:3100
   compress_responses: true
@@ -304,8 +780,14 @@ limits_config:
   cardinality_limit: 100000
   max_streams_matchers_per_query: 1000
   max_cache_freshness_per_query: 10m
+  split_queries_by_interval: 30m
   per_stream_rate_limit: 3MB
   per_stream_rate_limit_burst: 15MB
+  retention_period: 168h
+  retention_stream:
+    - period: 24h
+      priority: 1
+      selector: '{namespace="dev"}'
 memberlist:
   abort_if_cluster_join_fails: true
   bind_port: 7946
@@ -328,10 +810,9 @@ query_range:
   max_retries: 5
   results_cache:
     cache:
-      enable_fifocache: true
-      fifocache:
-        max_size_bytes: 500MB
-  split_queries_by_interval: 30m
+      embedded_cache:
+        enabled: true
+        max_size_mb: 500
   parallelise_shardable_queries: false
 schema_config:
   configs:
@@ -366,8 +847,8 @@ storage_config:
     s3: http://test.default.svc.cluster.local.:9000
     bucketnames: loki
     region: us-east
-    access_key_id: test
-    secret_access_key: test123
+    access_key_id: ${AWS_ACCESS_KEY_ID}
+    secret_access_key: ${AWS_ACCESS_KEY_SECRET}
     s3forcepathstyle: true
 tracing:
   enabled: false
@@ -380,6 +861,11 @@ overrides:
     ingestion_burst_size_mb: 5
     max_global_streams_per_user: 1
     max_chunks_per_query: 1000000
+    retention_period: 720h
+    retention_stream:
+      - period: 48h
+        priority: 2
+        selector: '{namespace="prod", app="nginx"}'
 `
 	opts := Options{
 		Stack: lokiv1beta1.LokiStackSpec{
@@ -400,6 +886,16 @@ overrides:
 						MaxChunksPerQuery:       2000000,
 						MaxQuerySeries:          500,
 					},
+					Retention: &lokiv1beta1.RetentionLimitSpec{
+						Period: "168h",
+						Streams: []*lokiv1beta1.RetentionStreamSpec{
+							{
+								Period:   "24h",
+								Priority: 1,
+								Selector: `{namespace="dev"}`,
+							},
+						},
+					},
 				},
 				Tenants: map[string]lokiv1beta1.LimitsTemplateSpec{
 					"test-a": {
@@ -411,6 +907,16 @@ overrides:
 						QueryLimits: &lokiv1beta1.QueryLimitSpec{
 							MaxChunksPerQuery: 1000000,
 						},
+						Retention: &lokiv1beta1.RetentionLimitSpec{
+							Period: "720h",
+							Streams: []*lokiv1beta1.RetentionStreamSpec{
+								{
+									Period:   "48h",
+									Priority: 2,
+									Selector: `{namespace="prod", app="nginx"}`,
+								},
+							},
+						},
 					},
 				},
 			},
@@ -434,12 +940,14 @@ overrides:
 			Port: 9095,
 		},
 		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
 		ObjectStorage: ObjectStorage{
-			Endpoint:        "http://test.default.svc.cluster.local.:9000",
-			Region:          "us-east",
-			Buckets:         "loki",
-			AccessKeyID:     "test",
-			AccessKeySecret: "test123",
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			S3: &S3Storage{
+				Endpoint: "http://test.default.svc.cluster.local.:9000",
+				Region:   "us-east",
+				Buckets:  "loki",
+			},
 		},
 		QueryParallelism: Parallelism{
 			QuerierCPULimits:      2,
@@ -449,6 +957,10 @@ overrides:
 			Directory:             "/tmp/wal",
 			IngesterMemoryRequest: 5000,
 		},
+		Retention: RetentionOptions{
+			Enabled:           true,
+			DeleteWorkerCount: 150,
+		},
 	}
 	cfg, rCfg, err := Build(opts)
 	require.NoError(t, err)
@@ -456,6 +968,51 @@ overrides:
 	require.YAMLEq(t, expRCfg, string(rCfg))
 }
 
+func TestBuild_ConfigAndRuntimeConfig_TenantRetentionOnly(t *testing.T) {
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+					QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+				},
+				Tenants: map[string]lokiv1beta1.LimitsTemplateSpec{
+					"tenant-a": {
+						Retention: &lokiv1beta1.RetentionLimitSpec{Period: "7d"},
+					},
+				},
+			},
+		},
+		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+		Retention: RetentionOptions{
+			Enabled:           true,
+			DeleteWorkerCount: 150,
+		},
+	}
+
+	cfg, rCfg, err := Build(opts)
+	require.NoError(t, err)
+
+	var got struct {
+		Compactor struct {
+			RetentionEnabled bool `json:"retention_enabled"`
+		} `json:"compactor"`
+		LimitsConfig map[string]interface{} `json:"limits_config"`
+	}
+	require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+	require.True(t, got.Compactor.RetentionEnabled)
+	require.Equal(t, "0s", got.LimitsConfig["retention_period"])
+	require.NotContains(t, got.LimitsConfig, "retention_stream")
+	require.Contains(t, string(rCfg), "retention_period: 7d")
+}
+
 func TestBuild_ConfigAndRuntimeConfig_CreateLokiConfigFailed(t *testing.T) {
 	opts := Options{
 		Stack: lokiv1beta1.LokiStackSpec{
@@ -495,12 +1052,14 @@ func TestBuild_ConfigAndRuntimeConfig_CreateLokiConfigFailed(t *testing.T) {
 			Port: 9095,
 		},
 		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
 		ObjectStorage: ObjectStorage{
-			Endpoint:        "http://test.default.svc.cluster.local.:9000",
-			Region:          "us-east",
-			Buckets:         "loki",
-			AccessKeyID:     "test",
-			AccessKeySecret: "test123",
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			S3: &S3Storage{
+				Endpoint: "http://test.default.svc.cluster.local.:9000",
+				Region:   "us-east",
+				Buckets:  "loki",
+			},
 		},
 		QueryParallelism: Parallelism{
 			QuerierCPULimits:      2,
@@ -516,3 +1075,503 @@ func TestBuild_ConfigAndRuntimeConfig_CreateLokiConfigFailed(t *testing.T) {
 	require.Empty(t, cfg)
 	require.Empty(t, rCfg)
 }
+
+func TestBuild_ConfigAndRuntimeConfig_ObjectStorageBackends(t *testing.T) {
+	table := []struct {
+		desc       string
+		storage    ObjectStorage
+		wantConfig string
+	}{
+		{
+			desc: "azure",
+			storage: ObjectStorage{
+				SharedStore: lokiv1beta1.ObjectStorageSecretAzure,
+				Azure: &AzureStorage{
+					Env:       "AzureGlobal",
+					Container: "loki",
+				},
+			},
+			wantConfig: `
+azure:
+  environment: AzureGlobal
+  container_name: loki
+  account_name: ${AZURE_STORAGE_ACCOUNT_NAME}
+  account_key: ${AZURE_STORAGE_ACCOUNT_KEY}
+`,
+		},
+		{
+			desc: "filesystem",
+			storage: ObjectStorage{
+				SharedStore: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &FilesystemStorage{
+					Directory: "/tmp/loki-shared/chunks",
+				},
+			},
+			wantConfig: `
+filesystem:
+  directory: /tmp/loki-shared/chunks
+`,
+		},
+		{
+			desc: "gcs",
+			storage: ObjectStorage{
+				SharedStore: lokiv1beta1.ObjectStorageSecretGCS,
+				GCS: &GCSStorage{
+					Bucket: "loki",
+				},
+			},
+			wantConfig: `
+gcs:
+  bucket_name: loki
+`,
+		},
+		{
+			desc: "swift",
+			storage: ObjectStorage{
+				SharedStore: lokiv1beta1.ObjectStorageSecretSwift,
+				Swift: &SwiftStorage{
+					AuthURL:           "http://keystone:5000/v3",
+					UserDomainName:    "Default",
+					UserDomainID:      "default",
+					UserID:            "user-id",
+					DomainID:          "default",
+					DomainName:        "Default",
+					ProjectID:         "project-id",
+					ProjectName:       "loki",
+					ProjectDomainID:   "default",
+					ProjectDomainName: "Default",
+					Region:            "RegionOne",
+					Container:         "loki",
+				},
+			},
+			wantConfig: `
+swift:
+  auth_url: http://keystone:5000/v3
+  username: ${SWIFT_USERNAME}
+  user_domain_name: Default
+  user_domain_id: default
+  user_id: user-id
+  password: ${SWIFT_PASSWORD}
+  domain_id: default
+  domain_name: Default
+  project_id: project-id
+  project_name: loki
+  project_domain_id: default
+  project_domain_name: Default
+  region_name: RegionOne
+  container_name: loki
+`,
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts := Options{
+				Stack: lokiv1beta1.LokiStackSpec{
+					ReplicationFactor: 1,
+					Limits: &lokiv1beta1.LimitsSpec{
+						Global: &lokiv1beta1.LimitsTemplateSpec{
+							IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+							QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+						},
+					},
+				},
+				StorageDirectory: "/tmp/loki",
+				Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+				ObjectStorage:    tc.storage,
+				QueryParallelism: Parallelism{
+					QuerierCPULimits:      2,
+					QueryFrontendReplicas: 2,
+				},
+			}
+
+			cfg, _, err := Build(opts)
+			require.NoError(t, err)
+
+			var got struct {
+				Compactor struct {
+					SharedStore string `json:"shared_store"`
+				} `json:"compactor"`
+				SchemaConfig struct {
+					Configs []struct {
+						ObjectStore string `json:"object_store"`
+					} `json:"configs"`
+				} `json:"schema_config"`
+				StorageConfig map[string]interface{} `json:"storage_config"`
+			}
+			require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+			store := string(tc.storage.SharedStore)
+			require.Equal(t, store, got.Compactor.SharedStore)
+			require.Equal(t, store, got.SchemaConfig.Configs[0].ObjectStore)
+
+			boltdb := got.StorageConfig["boltdb_shipper"].(map[string]interface{})
+			require.Equal(t, store, boltdb["shared_store"])
+
+			delete(got.StorageConfig, "boltdb_shipper")
+			backend, err := yaml.Marshal(got.StorageConfig)
+			require.NoError(t, err)
+			require.YAMLEq(t, tc.wantConfig, string(backend))
+		})
+	}
+}
+
+func TestBuild_ConfigAndRuntimeConfig_ZoneAwarenessEnabled(t *testing.T) {
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 3,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+					QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+				},
+			},
+		},
+		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+		ZoneAwarenessEnabled: true,
+	}
+
+	cfg, _, err := Build(opts)
+	require.NoError(t, err)
+
+	var got struct {
+		Ingester struct {
+			Lifecycler struct {
+				AvailabilityZone string `json:"availability_zone"`
+				Ring             struct {
+					ReplicationFactor    int  `json:"replication_factor"`
+					ZoneAwarenessEnabled bool `json:"zone_awareness_enabled"`
+				} `json:"ring"`
+			} `json:"lifecycler"`
+		} `json:"ingester"`
+	}
+	require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+	lc := got.Ingester.Lifecycler
+	require.Equal(t, "${INSTANCE_AVAILABILITY_ZONE}", lc.AvailabilityZone)
+	require.Equal(t, 3, lc.Ring.ReplicationFactor)
+	require.True(t, lc.Ring.ZoneAwarenessEnabled)
+}
+
+func TestBuild_ConfigAndRuntimeConfig_Schemas(t *testing.T) {
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+					QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+				},
+			},
+		},
+		IndexGateway: Address{
+			FQDN: "loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local",
+			Port: 9095,
+		},
+		StorageDirectory: "/tmp/loki",
+		ObjectStorage: ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+		},
+		Schemas: Schemas{
+			{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"},
+			{From: "2022-06-01", Version: "v12", IndexStore: "boltdb-shipper"},
+			{From: "2024-04-01", Version: "v13", IndexStore: "tsdb"},
+		},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+	}
+
+	cfg, _, err := Build(opts)
+	require.NoError(t, err)
+
+	var got struct {
+		SchemaConfig struct {
+			Configs []map[string]interface{} `json:"configs"`
+		} `json:"schema_config"`
+		StorageConfig struct {
+			TSDBShipper map[string]interface{} `json:"tsdb_shipper"`
+		} `json:"storage_config"`
+	}
+	require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+	wantConfigs := `
+- from: "2020-10-01"
+  index:
+    period: 24h
+    prefix: index_
+  object_store: s3
+  schema: v11
+  store: boltdb-shipper
+- from: "2022-06-01"
+  index:
+    period: 24h
+    prefix: index_
+  object_store: s3
+  schema: v12
+  store: boltdb-shipper
+- from: "2024-04-01"
+  index:
+    period: 24h
+    prefix: index_
+  object_store: s3
+  schema: v13
+  store: tsdb
+`
+	configs, err := yaml.Marshal(got.SchemaConfig.Configs)
+	require.NoError(t, err)
+	require.YAMLEq(t, wantConfigs, string(configs))
+
+	wantTSDB := `
+active_index_directory: /tmp/loki/tsdb-index
+cache_location: /tmp/loki/tsdb-cache
+cache_ttl: 24h
+resync_interval: 5m
+shared_store: s3
+index_gateway_client:
+  server_address: dns:///loki-index-gateway-grpc-lokistack-dev.default.svc.cluster.local:9095
+`
+	tsdb, err := yaml.Marshal(got.StorageConfig.TSDBShipper)
+	require.NoError(t, err)
+	require.YAMLEq(t, wantTSDB, string(tsdb))
+}
+
+func TestBuild_ConfigAndRuntimeConfig_ObjectStoragePrefix(t *testing.T) {
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+					QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+				},
+			},
+		},
+		StorageDirectory: "/tmp/loki",
+		ObjectStorage: ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			Prefix:      "team-a/dev",
+			S3: &S3Storage{
+				Endpoint: "http://minio:9000",
+				Buckets:  "loki,loki-2",
+			},
+		},
+		Schemas: Schemas{
+			{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"},
+			{From: "2022-06-01", Version: "v12", IndexStore: "boltdb-shipper"},
+			{From: "2024-04-01", Version: "v13", IndexStore: "tsdb"},
+		},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+	}
+
+	cfg, _, err := Build(opts)
+	require.NoError(t, err)
+
+	var got struct {
+		Compactor struct {
+			SharedStoreKeyPrefix string `json:"shared_store_key_prefix"`
+		} `json:"compactor"`
+		StorageConfig struct {
+			BoltDBShipper struct {
+				SharedStoreKeyPrefix string `json:"shared_store_key_prefix"`
+			} `json:"boltdb_shipper"`
+			TSDBShipper struct {
+				SharedStoreKeyPrefix string `json:"shared_store_key_prefix"`
+			} `json:"tsdb_shipper"`
+			AWS struct {
+				BucketNames string `json:"bucketnames"`
+			} `json:"aws"`
+		} `json:"storage_config"`
+	}
+	require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+	require.Equal(t, "team-a/dev/index/", got.Compactor.SharedStoreKeyPrefix)
+	require.Equal(t, "team-a/dev/index/", got.StorageConfig.BoltDBShipper.SharedStoreKeyPrefix)
+	require.Equal(t, "team-a/dev/index/", got.StorageConfig.TSDBShipper.SharedStoreKeyPrefix)
+	require.Equal(t, "loki,loki-2", got.StorageConfig.AWS.BucketNames)
+}
+
+func TestBuild_ConfigAndRuntimeConfig_S3Options(t *testing.T) {
+	table := []struct {
+		name string
+		s3   S3Storage
+		want string
+	}{
+		{
+			name: "defaults",
+			s3:   S3Storage{Endpoint: "https://s3.example.com", Region: "eu-central-1", Buckets: "loki"},
+			want: `
+s3: https://s3.example.com
+bucketnames: loki
+region: eu-central-1
+access_key_id: ${AWS_ACCESS_KEY_ID}
+secret_access_key: ${AWS_ACCESS_KEY_SECRET}
+s3forcepathstyle: true
+`,
+		},
+		{
+			name: "all set",
+			s3: S3Storage{
+				Endpoint:           "s3.example.com",
+				Region:             "eu-central-1",
+				Buckets:            "loki",
+				VirtualHostedStyle: true,
+				Insecure:           true,
+				SSE:                &S3SSE{Type: "SSE-KMS", KMSKeyID: "kms-key"},
+			},
+			want: `
+s3: s3.example.com
+bucketnames: loki
+region: eu-central-1
+access_key_id: ${AWS_ACCESS_KEY_ID}
+secret_access_key: ${AWS_ACCESS_KEY_SECRET}
+s3forcepathstyle: false
+insecure: true
+sse:
+  type: SSE-KMS
+  kms_key_id: kms-key
+`,
+		},
+		{
+			name: "sse-s3",
+			s3: S3Storage{
+				Endpoint: "https://s3.example.com",
+				Region:   "eu-central-1",
+				Buckets:  "loki",
+				SSE:      &S3SSE{Type: "SSE-S3"},
+			},
+			want: `
+s3: https://s3.example.com
+bucketnames: loki
+region: eu-central-1
+access_key_id: ${AWS_ACCESS_KEY_ID}
+secret_access_key: ${AWS_ACCESS_KEY_SECRET}
+s3forcepathstyle: true
+sse:
+  type: SSE-S3
+`,
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s3 := tc.s3
+			opts := Options{
+				Stack: lokiv1beta1.LokiStackSpec{
+					ReplicationFactor: 1,
+					Limits: &lokiv1beta1.LimitsSpec{
+						Global: &lokiv1beta1.LimitsTemplateSpec{
+							IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+							QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+						},
+					},
+				},
+				StorageDirectory: "/tmp/loki",
+				ObjectStorage: ObjectStorage{
+					SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+					S3:          &s3,
+				},
+				Schemas: Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+				QueryParallelism: Parallelism{
+					QuerierCPULimits:      2,
+					QueryFrontendReplicas: 2,
+				},
+			}
+
+			cfg, _, err := Build(opts)
+			require.NoError(t, err)
+
+			var got struct {
+				StorageConfig struct {
+					AWS map[string]interface{} `json:"aws"`
+				} `json:"storage_config"`
+			}
+			require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+			aws, err := yaml.Marshal(got.StorageConfig.AWS)
+			require.NoError(t, err)
+			require.YAMLEq(t, tc.want, string(aws))
+		})
+	}
+}
+
+func TestBuild_ConfigAndRuntimeConfig_Caches(t *testing.T) {
+	opts := Options{
+		Stack: lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: 1,
+			Limits: &lokiv1beta1.LimitsSpec{
+				Global: &lokiv1beta1.LimitsTemplateSpec{
+					IngestionLimits: &lokiv1beta1.IngestionLimitSpec{},
+					QueryLimits:     &lokiv1beta1.QueryLimitSpec{},
+				},
+			},
+		},
+		StorageDirectory: "/tmp/loki",
+		Schemas:          Schemas{{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"}},
+		QueryParallelism: Parallelism{
+			QuerierCPULimits:      2,
+			QueryFrontendReplicas: 2,
+		},
+		Caches: Caches{
+			Chunks: Cache{
+				Memcached: &MemcachedCache{Addresses: "dnssrvnoa+_client._tcp.memcached-chunks"},
+			},
+			IndexQueries: Cache{
+				Redis: &RedisCache{Endpoint: "redis:6379"},
+			},
+		},
+	}
+
+	cfg, _, err := Build(opts)
+	require.NoError(t, err)
+
+	type cacheConfig struct {
+		EmbeddedCache struct {
+			Enabled bool `json:"enabled"`
+		} `json:"embedded_cache"`
+		MemcachedClient struct {
+			Addresses string `json:"addresses"`
+		} `json:"memcached_client"`
+		Redis struct {
+			Endpoint string `json:"endpoint"`
+		} `json:"redis"`
+	}
+
+	var got struct {
+		ChunkStoreConfig struct {
+			ChunkCacheConfig cacheConfig `json:"chunk_cache_config"`
+		} `json:"chunk_store_config"`
+		StorageConfig struct {
+			IndexQueriesCacheConfig cacheConfig `json:"index_queries_cache_config"`
+		} `json:"storage_config"`
+		QueryRange struct {
+			ResultsCache struct {
+				Cache cacheConfig `json:"cache"`
+			} `json:"results_cache"`
+		} `json:"query_range"`
+	}
+	require.NoError(t, yaml.Unmarshal(cfg, &got))
+
+	chunks := got.ChunkStoreConfig.ChunkCacheConfig
+	require.False(t, chunks.EmbeddedCache.Enabled)
+	require.Equal(t, "dnssrvnoa+_client._tcp.memcached-chunks", chunks.MemcachedClient.Addresses)
+
+	index := got.StorageConfig.IndexQueriesCacheConfig
+	require.Equal(t, "redis:6379", index.Redis.Endpoint)
+
+	results := got.QueryRange.ResultsCache.Cache
+	require.True(t, results.EmbeddedCache.Enabled)
+}
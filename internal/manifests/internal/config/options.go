@@ -21,6 +21,15 @@ type Options struct {
 	ObjectStorage    ObjectStorage
 	QueryParallelism Parallelism
 	WriteAheadLog    WriteAheadLog
+	Ruler            Ruler
+	Retention        RetentionOptions
+	Caches           Caches
+	Schemas          Schemas
+
+	// ZoneAwarenessEnabled renders the ingester availability zone from
+	// the INSTANCE_AVAILABILITY_ZONE environment variable and enables
+	// zone-aware replication in the ingester ring.
+	ZoneAwarenessEnabled bool
 }
 
 // Address FQDN and port for a k8s service.
@@ -31,13 +40,130 @@ type Address struct {
 	Port int
 }
 
-// ObjectStorage for storage config.
+// ObjectStorage for storage config. The credentials are not part
+// of it but rendered as environment variable references expanded by
+// Loki on startup (see -config.expand-env).
 type ObjectStorage struct {
-	Endpoint        string
-	Region          string
-	Buckets         string
-	AccessKeyID     string
-	AccessKeySecret string
+	SharedStore lokiv1beta1.ObjectStorageSecretType
+	// Prefix of the object keys in the shared store if not empty.
+	Prefix string
+
+	Azure *AzureStorage
+	GCS   *GCSStorage
+	S3    *S3Storage
+	Swift *SwiftStorage
+
+	Filesystem *FilesystemStorage
+}
+
+// AzureStorage for Azure storage config.
+type AzureStorage struct {
+	Env       string
+	Container string
+}
+
+// GCSStorage for GCS storage config.
+type GCSStorage struct {
+	Bucket string
+}
+
+// S3Storage for S3 storage config.
+type S3Storage struct {
+	Endpoint string
+	Region   string
+	Buckets  string
+
+	VirtualHostedStyle bool
+	Insecure           bool
+	SSE                *S3SSE
+}
+
+// S3SSE for the S3 server-side encryption config.
+type S3SSE struct {
+	Type     string
+	KMSKeyID string
+}
+
+// SwiftStorage for Swift storage config.
+type SwiftStorage struct {
+	AuthURL           string
+	UserDomainName    string
+	UserDomainID      string
+	UserID            string
+	DomainID          string
+	DomainName        string
+	ProjectID         string
+	ProjectName       string
+	ProjectDomainID   string
+	ProjectDomainName string
+	Region            string
+	Container         string
+}
+
+// Schema for a single period of the schema config.
+type Schema struct {
+	From       string
+	Version    string
+	IndexStore string
+}
+
+// Schemas for the schema config ordered by the start of their periods.
+type Schemas []Schema
+
+// UsesIndexStore returns true if any of the schemas
+// uses the given index store.
+func (s Schemas) UsesIndexStore(store string) bool {
+	for _, schema := range s {
+		if schema.IndexStore == store {
+			return true
+		}
+	}
+	return false
+}
+
+// FilesystemStorage for the filesystem storage config
+// on a volume shared by all components.
+type FilesystemStorage struct {
+	Directory string
+}
+
+// Caches for the chunks, index queries and query results
+// cache config. Caches without a backend use the in-memory
+// cache of each process.
+type Caches struct {
+	Chunks       Cache
+	IndexQueries Cache
+	Results      Cache
+}
+
+// Cache for a single cache config backed by either
+// memcached or redis.
+type Cache struct {
+	Memcached *MemcachedCache
+	Redis     *RedisCache
+}
+
+// MemcachedCache for the memcached client config.
+type MemcachedCache struct {
+	Addresses string
+}
+
+// RedisCache for the redis client config.
+type RedisCache struct {
+	Endpoint string
+}
+
+// Ruler for the ruler component config.
+type Ruler struct {
+	Enabled               bool
+	RulesStorageDirectory string
+	AlertManagerURL       string
+}
+
+// RetentionOptions configures the compactor to apply retention.
+type RetentionOptions struct {
+	Enabled           bool
+	DeleteWorkerCount uint
 }
 
 // Parallelism for query processing parallelism
@@ -8,22 +8,23 @@ import (
 
 // ComponentResources is a map of component->requests/limits
 type ComponentResources struct {
-	IndexGateway ResourceRequirements
-	Ingester     ResourceRequirements
-	Compactor    ResourceRequirements
-	WALStorage   ResourceRequirements
+	IndexGateway ResourceRequirements `json:"indexGateway"`
+	Ingester     ResourceRequirements `json:"ingester"`
+	Compactor    ResourceRequirements `json:"compactor"`
+	Ruler        ResourceRequirements `json:"ruler"`
+	WALStorage   ResourceRequirements `json:"walStorage"`
 	// these two don't need a PVCSize
-	Querier       corev1.ResourceRequirements
-	Distributor   corev1.ResourceRequirements
-	QueryFrontend corev1.ResourceRequirements
-	Gateway       corev1.ResourceRequirements
+	Querier       corev1.ResourceRequirements `json:"querier"`
+	Distributor   corev1.ResourceRequirements `json:"distributor"`
+	QueryFrontend corev1.ResourceRequirements `json:"queryFrontend"`
+	Gateway       corev1.ResourceRequirements `json:"gateway"`
 }
 
 // ResourceRequirements sets CPU, Memory, and PVC requirements for a component
 type ResourceRequirements struct {
-	Limits   corev1.ResourceList
-	Requests corev1.ResourceList
-	PVCSize  resource.Quantity
+	Limits   corev1.ResourceList `json:"limits,omitempty"`
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	PVCSize  resource.Quantity   `json:"pvcSize,omitempty"`
 }
 
 // ResourceRequirementsTable defines the default resource requests and limits for each size
@@ -74,6 +75,13 @@ var ResourceRequirementsTable = map[lokiv1beta1.LokiStackSizeType]ComponentResou
 				corev1.ResourceMemory: resource.MustParse("1Gi"),
 			},
 		},
+		Ruler: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("15Gi"),
 		},
@@ -124,6 +132,13 @@ var ResourceRequirementsTable = map[lokiv1beta1.LokiStackSizeType]ComponentResou
 				corev1.ResourceMemory: resource.MustParse("2Gi"),
 			},
 		},
+		Ruler: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("150Gi"),
 		},
@@ -174,6 +189,127 @@ var ResourceRequirementsTable = map[lokiv1beta1.LokiStackSizeType]ComponentResou
 				corev1.ResourceMemory: resource.MustParse("2Gi"),
 			},
 		},
+		Ruler: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+		WALStorage: ResourceRequirements{
+			PVCSize: resource.MustParse("150Gi"),
+		},
+	},
+	lokiv1beta1.SizeOneXLarge: {
+		Querier: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+		Ingester: ResourceRequirements{
+			PVCSize: resource.MustParse("20Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("40Gi"),
+			},
+		},
+		Distributor: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		QueryFrontend: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		Compactor: ResourceRequirements{
+			PVCSize: resource.MustParse("20Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+		Gateway: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		IndexGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("100Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		Ruler: ResourceRequirements{
+			PVCSize: resource.MustParse("20Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+		WALStorage: ResourceRequirements{
+			PVCSize: resource.MustParse("300Gi"),
+		},
+	},
+	lokiv1beta1.SizeTwoXMedium: {
+		Querier: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+		Ingester: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("30Gi"),
+			},
+		},
+		Distributor: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		QueryFrontend: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("2.5Gi"),
+			},
+		},
+		Compactor: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		Gateway: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		IndexGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("50Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		Ruler: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("150Gi"),
 		},
@@ -227,6 +363,9 @@ var StackSizeTable = map[lokiv1beta1.LokiStackSizeType]lokiv1beta1.LokiStackSpec
 			IndexGateway: &lokiv1beta1.LokiComponentSpec{
 				Replicas: 1,
 			},
+			Ruler: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 1,
+			},
 		},
 	},
 
@@ -276,6 +415,9 @@ var StackSizeTable = map[lokiv1beta1.LokiStackSizeType]lokiv1beta1.LokiStackSpec
 			IndexGateway: &lokiv1beta1.LokiComponentSpec{
 				Replicas: 2,
 			},
+			Ruler: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
 		},
 	},
 
@@ -325,6 +467,156 @@ var StackSizeTable = map[lokiv1beta1.LokiStackSizeType]lokiv1beta1.LokiStackSpec
 			IndexGateway: &lokiv1beta1.LokiComponentSpec{
 				Replicas: 2,
 			},
+			Ruler: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
 		},
 	},
+
+	lokiv1beta1.SizeOneXLarge: {
+		Size:              lokiv1beta1.SizeOneXLarge,
+		ReplicationFactor: 3,
+		Limits: &lokiv1beta1.LimitsSpec{
+			Global: &lokiv1beta1.LimitsTemplateSpec{
+				IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+					// Custom for 1x.large
+					IngestionRate:             20,
+					IngestionBurstSize:        40,
+					MaxGlobalStreamsPerTenant: 50000,
+					// Defaults from Loki docs
+					MaxLabelNameLength:     1024,
+					MaxLabelValueLength:    2048,
+					MaxLabelNamesPerSeries: 30,
+					MaxLineSize:            256000,
+				},
+				QueryLimits: &lokiv1beta1.QueryLimitSpec{
+					// Defaults from Loki docs
+					MaxEntriesLimitPerQuery: 5000,
+					MaxChunksPerQuery:       2000000,
+					MaxQuerySeries:          500,
+				},
+			},
+		},
+		Template: &lokiv1beta1.LokiTemplateSpec{
+			Compactor: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 1,
+			},
+			Distributor: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 3,
+			},
+			Ingester: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			Querier: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			QueryFrontend: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
+			Gateway: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
+			IndexGateway: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
+			Ruler: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 2,
+			},
+		},
+	},
+
+	lokiv1beta1.SizeTwoXMedium: {
+		Size:              lokiv1beta1.SizeTwoXMedium,
+		ReplicationFactor: 3,
+		Limits: &lokiv1beta1.LimitsSpec{
+			Global: &lokiv1beta1.LimitsTemplateSpec{
+				IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+					// Custom for 2x.medium
+					IngestionRate:             20,
+					IngestionBurstSize:        40,
+					MaxGlobalStreamsPerTenant: 50000,
+					// Defaults from Loki docs
+					MaxLabelNameLength:     1024,
+					MaxLabelValueLength:    2048,
+					MaxLabelNamesPerSeries: 30,
+					MaxLineSize:            256000,
+				},
+				QueryLimits: &lokiv1beta1.QueryLimitSpec{
+					// Defaults from Loki docs
+					MaxEntriesLimitPerQuery: 5000,
+					MaxChunksPerQuery:       2000000,
+					MaxQuerySeries:          500,
+				},
+			},
+		},
+		Template: &lokiv1beta1.LokiTemplateSpec{
+			Compactor: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 1,
+			},
+			Distributor: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			Ingester: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 6,
+			},
+			Querier: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 6,
+			},
+			QueryFrontend: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			Gateway: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			IndexGateway: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+			Ruler: &lokiv1beta1.LokiComponentSpec{
+				Replicas: 4,
+			},
+		},
+	},
+}
+
+// MemcachedSize defines the replicas and the cache memory in megabytes of
+// each operator managed memcached cache.
+type MemcachedSize struct {
+	Replicas             int32 `json:"replicas"`
+	ChunksMemoryMB       int32 `json:"chunksMemoryMB"`
+	IndexQueriesMemoryMB int32 `json:"indexQueriesMemoryMB"`
+	ResultsMemoryMB      int32 `json:"resultsMemoryMB"`
+}
+
+// MemcachedSizeTable defines the default managed memcached caches for each size
+var MemcachedSizeTable = map[lokiv1beta1.LokiStackSizeType]MemcachedSize{
+	lokiv1beta1.SizeOneXExtraSmall: {
+		Replicas:             1,
+		ChunksMemoryMB:       1024,
+		IndexQueriesMemoryMB: 256,
+		ResultsMemoryMB:      256,
+	},
+	lokiv1beta1.SizeOneXSmall: {
+		Replicas:             2,
+		ChunksMemoryMB:       4096,
+		IndexQueriesMemoryMB: 1024,
+		ResultsMemoryMB:      1024,
+	},
+	lokiv1beta1.SizeOneXMedium: {
+		Replicas:             3,
+		ChunksMemoryMB:       6144,
+		IndexQueriesMemoryMB: 1024,
+		ResultsMemoryMB:      1024,
+	},
+	lokiv1beta1.SizeOneXLarge: {
+		Replicas:             3,
+		ChunksMemoryMB:       8192,
+		IndexQueriesMemoryMB: 2048,
+		ResultsMemoryMB:      2048,
+	},
+	lokiv1beta1.SizeTwoXMedium: {
+		Replicas:             6,
+		ChunksMemoryMB:       6144,
+		IndexQueriesMemoryMB: 1024,
+		ResultsMemoryMB:      1024,
+	},
 }
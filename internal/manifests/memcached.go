@@ -0,0 +1,242 @@
+package manifests
+
+import (
+	"fmt"
+	"math"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	memcachedPort     = 11211
+	memcachedPortName = "client"
+
+	memcachedCacheChunks       = "chunks"
+	memcachedCacheIndexQueries = "index-queries"
+	memcachedCacheResults      = "results"
+)
+
+// BuildMemcached builds the k8s objects required to run the operator managed
+// memcached caches for chunks, index queries and query results.
+func BuildMemcached(opts Options) []client.Object {
+	if !managedCachingEnabled(opts.Stack) {
+		return nil
+	}
+
+	size := internal.MemcachedSizeTable[opts.Stack.Size]
+	if p := opts.SizeProfile; p != nil && p.Memcached != nil {
+		size = *p.Memcached
+	}
+
+	caches := []struct {
+		name     string
+		memoryMB int32
+	}{
+		{name: memcachedCacheChunks, memoryMB: size.ChunksMemoryMB},
+		{name: memcachedCacheIndexQueries, memoryMB: size.IndexQueriesMemoryMB},
+		{name: memcachedCacheResults, memoryMB: size.ResultsMemoryMB},
+	}
+
+	var objs []client.Object
+	for _, c := range caches {
+		objs = append(objs,
+			NewMemcachedStatefulSet(opts, c.name, size.Replicas, c.memoryMB),
+			NewMemcachedService(opts, c.name),
+		)
+	}
+
+	return objs
+}
+
+// NewMemcachedStatefulSet creates a statefulset object for a memcached cache
+func NewMemcachedStatefulSet(opts Options, cache string, replicas, memoryMB int32) *appsv1.StatefulSet {
+	// Reserve additional memory for the connections and the process overhead.
+	memory := resource.MustParse(fmt.Sprintf("%dMi", int64(math.Ceil(float64(memoryMB)*1.2))))
+
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Image: opts.MemcachedImage,
+				Name:  "memcached",
+				Args: []string{
+					"-m", fmt.Sprintf("%d", memoryMB),
+					"-I", "5m",
+					"-c", "16384",
+					"-v",
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: memory,
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: memory,
+					},
+				},
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{
+							Port: intstr.FromInt(memcachedPort),
+						},
+					},
+					PeriodSeconds:       10,
+					InitialDelaySeconds: 5,
+					TimeoutSeconds:      1,
+					SuccessThreshold:    1,
+					FailureThreshold:    3,
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{
+							Port: intstr.FromInt(memcachedPort),
+						},
+					},
+					TimeoutSeconds:   2,
+					PeriodSeconds:    30,
+					FailureThreshold: 10,
+					SuccessThreshold: 1,
+				},
+				Ports: []corev1.ContainerPort{
+					{
+						Name:          memcachedPortName,
+						ContainerPort: memcachedPort,
+						Protocol:      protocolTCP,
+					},
+				},
+				TerminationMessagePath:   "/dev/termination-log",
+				TerminationMessagePolicy: "File",
+				ImagePullPolicy:          "IfNotPresent",
+			},
+		},
+	}
+
+	l := memcachedLabels(opts.Name, cache)
+	podSpec.TopologySpreadConstraints = defaultTopologySpreadConstraints(l)
+
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   MemcachedName(opts.Name, cache),
+			Labels: l,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			PodManagementPolicy:  appsv1.ParallelPodManagement,
+			RevisionHistoryLimit: pointer.Int32Ptr(10),
+			Replicas:             pointer.Int32Ptr(replicas),
+			ServiceName:          serviceNameMemcached(opts.Name, cache),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: l,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   MemcachedName(opts.Name, cache),
+					Labels: l,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
+
+// NewMemcachedService creates a headless k8s service for a memcached cache
+// to discover the memcached pods via DNS SRV records.
+func NewMemcachedService(opts Options, cache string) *corev1.Service {
+	l := memcachedLabels(opts.Name, cache)
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serviceNameMemcached(opts.Name, cache),
+			Labels: l,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+			Ports: []corev1.ServicePort{
+				{
+					Name:       memcachedPortName,
+					Port:       memcachedPort,
+					Protocol:   protocolTCP,
+					TargetPort: intstr.IntOrString{IntVal: memcachedPort},
+				},
+			},
+			Selector: l,
+		},
+	}
+}
+
+func memcachedLabels(stackName, cache string) labels.Set {
+	return labels.Merge(ComponentLabels(LabelMemcachedComponent, stackName), map[string]string{
+		"loki.grafana.com/cache": cache,
+	})
+}
+
+func managedCachingEnabled(spec lokiv1beta1.LokiStackSpec) bool {
+	return spec.Caching != nil && spec.Caching.Mode == lokiv1beta1.CachingModeManaged
+}
+
+// cachesConfig returns the cache config for the managed memcached caches
+// or the external caches. All other caches use the in-memory cache.
+func cachesConfig(opts Options) config.Caches {
+	c := opts.Stack.Caching
+	if c == nil {
+		return config.Caches{}
+	}
+
+	switch c.Mode {
+	case lokiv1beta1.CachingModeManaged:
+		return config.Caches{
+			Chunks:       managedCacheConfig(opts, memcachedCacheChunks),
+			IndexQueries: managedCacheConfig(opts, memcachedCacheIndexQueries),
+			Results:      managedCacheConfig(opts, memcachedCacheResults),
+		}
+	case lokiv1beta1.CachingModeExternal:
+		return config.Caches{
+			Chunks:       externalCacheConfig(c.Chunks),
+			IndexQueries: externalCacheConfig(c.IndexQueries),
+			Results:      externalCacheConfig(c.Results),
+		}
+	default:
+		return config.Caches{}
+	}
+}
+
+func managedCacheConfig(opts Options, cache string) config.Cache {
+	svc := fqdn(serviceNameMemcached(opts.Name, cache), opts.Namespace)
+	return config.Cache{
+		Memcached: &config.MemcachedCache{
+			Addresses: fmt.Sprintf("dnssrvnoa+_%s._tcp.%s", memcachedPortName, svc),
+		},
+	}
+}
+
+func externalCacheConfig(s *lokiv1beta1.ExternalCacheSpec) config.Cache {
+	if s == nil {
+		return config.Cache{}
+	}
+
+	if s.Type == lokiv1beta1.CacheBackendRedis {
+		return config.Cache{
+			Redis: &config.RedisCache{Endpoint: s.Endpoint},
+		}
+	}
+
+	return config.Cache{
+		Memcached: &config.MemcachedCache{Addresses: s.Endpoint},
+	}
+}
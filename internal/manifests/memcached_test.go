@@ -0,0 +1,94 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildMemcached_WhenNotManaged_BuildsNothing(t *testing.T) {
+	opts := Options{
+		Name: "abcd",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Caching: &lokiv1beta1.CachingSpec{
+				Mode: lokiv1beta1.CachingModeInMemory,
+			},
+		},
+	}
+
+	require.Empty(t, BuildMemcached(opts))
+	require.Equal(t, config.Caches{}, cachesConfig(opts))
+}
+
+func TestBuildMemcached_WhenManaged_BuildsCachePerType(t *testing.T) {
+	opts := Options{
+		Name:           "abcd",
+		Namespace:      "efgh",
+		MemcachedImage: "memcached:latest",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Caching: &lokiv1beta1.CachingSpec{
+				Mode: lokiv1beta1.CachingModeManaged,
+			},
+		},
+	}
+
+	objs := BuildMemcached(opts)
+	require.Len(t, objs, 6)
+
+	var names []string
+	for _, o := range objs {
+		switch obj := o.(type) {
+		case *appsv1.StatefulSet:
+			names = append(names, obj.Name)
+			require.Equal(t, int32(2), *obj.Spec.Replicas)
+			require.Equal(t, "memcached:latest", obj.Spec.Template.Spec.Containers[0].Image)
+		case *corev1.Service:
+			require.Equal(t, "None", obj.Spec.ClusterIP)
+		}
+	}
+	require.Equal(t, []string{
+		"loki-memcached-chunks-abcd",
+		"loki-memcached-index-queries-abcd",
+		"loki-memcached-results-abcd",
+	}, names)
+
+	caches := cachesConfig(opts)
+	require.Equal(t, "dnssrvnoa+_client._tcp.loki-memcached-chunks-abcd.efgh.svc.cluster.local", caches.Chunks.Memcached.Addresses)
+	require.Equal(t, "dnssrvnoa+_client._tcp.loki-memcached-index-queries-abcd.efgh.svc.cluster.local", caches.IndexQueries.Memcached.Addresses)
+	require.Equal(t, "dnssrvnoa+_client._tcp.loki-memcached-results-abcd.efgh.svc.cluster.local", caches.Results.Memcached.Addresses)
+}
+
+func TestCachesConfig_WhenExternal_UsesEndpoints(t *testing.T) {
+	opts := Options{
+		Name: "abcd",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Caching: &lokiv1beta1.CachingSpec{
+				Mode: lokiv1beta1.CachingModeExternal,
+				Chunks: &lokiv1beta1.ExternalCacheSpec{
+					Endpoint: "memcached-0:11211,memcached-1:11211",
+				},
+				Results: &lokiv1beta1.ExternalCacheSpec{
+					Type:     lokiv1beta1.CacheBackendRedis,
+					Endpoint: "redis:6379",
+				},
+			},
+		},
+	}
+
+	require.Empty(t, BuildMemcached(opts))
+	require.Equal(t, config.Caches{
+		Chunks: config.Cache{
+			Memcached: &config.MemcachedCache{Addresses: "memcached-0:11211,memcached-1:11211"},
+		},
+		Results: config.Cache{
+			Redis: &config.RedisCache{Endpoint: "redis:6379"},
+		},
+	}, cachesConfig(opts))
+}
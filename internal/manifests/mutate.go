@@ -10,8 +10,10 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -24,7 +26,10 @@ import (
 // - Service
 // - Deployment
 // - StatefulSet
+// - PodDisruptionBudget
+// - HorizontalPodAutoscaler
 // - ServiceMonitor
+// - PersistentVolumeClaim
 func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 	return func() error {
 		existingAnnotations := existing.GetAnnotations()
@@ -71,6 +76,16 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 			wantSts := desired.(*appsv1.StatefulSet)
 			mutateStatefulSet(sts, wantSts)
 
+		case *policyv1.PodDisruptionBudget:
+			pdb := existing.(*policyv1.PodDisruptionBudget)
+			wantPdb := desired.(*policyv1.PodDisruptionBudget)
+			mutatePodDisruptionBudget(pdb, wantPdb)
+
+		case *autoscalingv2beta2.HorizontalPodAutoscaler:
+			hpa := existing.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+			wantHpa := desired.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+			mutateHorizontalPodAutoscaler(hpa, wantHpa)
+
 		case *monitoringv1.ServiceMonitor:
 			svcMonitor := existing.(*monitoringv1.ServiceMonitor)
 			wantSvcMonitor := desired.(*monitoringv1.ServiceMonitor)
@@ -86,6 +101,11 @@ func MutateFuncFor(existing, desired client.Object) controllerutil.MutateFn {
 			wantRt := desired.(*routev1.Route)
 			mutateRoute(rt, wantRt)
 
+		case *corev1.PersistentVolumeClaim:
+			pvc := existing.(*corev1.PersistentVolumeClaim)
+			wantPvc := desired.(*corev1.PersistentVolumeClaim)
+			mutatePersistentVolumeClaim(pvc, wantPvc)
+
 		default:
 			t := reflect.TypeOf(existing).String()
 			return kverrors.New("missing mutate implementation for resource type", "type", t)
@@ -134,7 +154,10 @@ func mutateDeployment(existing, desired *appsv1.Deployment) {
 	if existing.CreationTimestamp.IsZero() {
 		mergeWithOverride(existing.Spec.Selector, desired.Spec.Selector)
 	}
-	existing.Spec.Replicas = desired.Spec.Replicas
+	// Replicas are left to the HorizontalPodAutoscaler if not desired
+	if desired.Spec.Replicas != nil {
+		existing.Spec.Replicas = desired.Spec.Replicas
+	}
 	mergeWithOverride(&existing.Spec.Template, desired.Spec.Template)
 	mergeWithOverride(&existing.Spec.Strategy, desired.Spec.Strategy)
 }
@@ -155,6 +178,25 @@ func mutateStatefulSet(existing, desired *appsv1.StatefulSet) {
 	}
 }
 
+func mutatePodDisruptionBudget(existing, desired *policyv1.PodDisruptionBudget) {
+	existing.Labels = desired.Labels
+	existing.Spec = desired.Spec
+}
+
+// mutatePersistentVolumeClaim applies only the storage request,
+// as all other fields of a bound claim are immutable.
+func mutatePersistentVolumeClaim(existing, desired *corev1.PersistentVolumeClaim) {
+	if existing.Spec.Resources.Requests == nil {
+		existing.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	existing.Spec.Resources.Requests[corev1.ResourceStorage] = desired.Spec.Resources.Requests[corev1.ResourceStorage]
+}
+
+func mutateHorizontalPodAutoscaler(existing, desired *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	existing.Labels = desired.Labels
+	existing.Spec = desired.Spec
+}
+
 func mutateServiceMonitor(existing, desired *monitoringv1.ServiceMonitor) {
 	// ServiceMonitor selector is immutable so we set this value only if
 	// a new object is going to be created
@@ -10,9 +10,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
@@ -496,6 +499,60 @@ func TestGeMutateFunc_MutateDeploymentSpec(t *testing.T) {
 	}
 }
 
+func TestGeMutateFunc_MutateDeploymentSpec_KeepsReplicasOwnedByAutoscaler(t *testing.T) {
+	got := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32Ptr(5),
+		},
+	}
+	want := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{},
+		},
+	}
+
+	f := manifests.MutateFuncFor(got, want)
+	err := f()
+	require.NoError(t, err)
+
+	require.Equal(t, pointer.Int32Ptr(5), got.Spec.Replicas)
+}
+
+func TestGetMutateFunc_MutateHorizontalPodAutoscaler(t *testing.T) {
+	got := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test": "test",
+			},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			MinReplicas: pointer.Int32Ptr(1),
+			MaxReplicas: 3,
+		},
+	}
+
+	want := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test":  "test",
+				"other": "label",
+			},
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			MinReplicas: pointer.Int32Ptr(2),
+			MaxReplicas: 5,
+		},
+	}
+
+	f := manifests.MutateFuncFor(got, want)
+	err := f()
+	require.NoError(t, err)
+
+	// Ensure partial mutation applied
+	require.Equal(t, got.Labels, want.Labels)
+	require.Equal(t, got.Spec, want.Spec)
+}
+
 func TestGeMutateFunc_MutateStatefulSetSpec(t *testing.T) {
 	type test struct {
 		name string
@@ -653,6 +710,86 @@ func TestGeMutateFunc_MutateStatefulSetSpec(t *testing.T) {
 	}
 }
 
+func TestGetMutateFunc_MutatePodDisruptionBudget(t *testing.T) {
+	one := intstr.FromInt(1)
+	two := intstr.FromInt(2)
+
+	got := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test": "test",
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &one,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"test": "test",
+				},
+			},
+		},
+	}
+
+	want := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test":  "test",
+				"other": "label",
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &two,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"test":  "test",
+					"other": "label",
+				},
+			},
+		},
+	}
+
+	f := manifests.MutateFuncFor(got, want)
+	err := f()
+	require.NoError(t, err)
+
+	// Ensure partial mutation applied
+	require.Equal(t, got.Labels, want.Labels)
+	require.Equal(t, got.Spec, want.Spec)
+}
+
+func TestGetMutateFunc_MutatePersistentVolumeClaim(t *testing.T) {
+	got := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("10Gi"),
+				},
+			},
+			VolumeName: "pv-1",
+		},
+	}
+
+	want := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse("20Gi"),
+				},
+			},
+		},
+	}
+
+	f := manifests.MutateFuncFor(got, want)
+	err := f()
+	require.NoError(t, err)
+
+	// Ensure only the storage request is mutated
+	require.Equal(t, want.Spec.Resources, got.Spec.Resources)
+	require.Equal(t, "pv-1", got.Spec.VolumeName)
+}
+
 func TestGetMutateFunc_MutateServiceMonitorSpec(t *testing.T) {
 	type test struct {
 		name string
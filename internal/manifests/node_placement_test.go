@@ -42,6 +42,10 @@ func TestTolerationsAreSetForEachComponent(t *testing.T) {
 					Tolerations: tolerations,
 					Replicas:    1,
 				},
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Tolerations: tolerations,
+					Replicas:    1,
+				},
 			},
 		},
 		ObjectStorage: ObjectStorage{},
@@ -68,6 +72,9 @@ func TestTolerationsAreSetForEachComponent(t *testing.T) {
 				IndexGateway: &lokiv1beta1.LokiComponentSpec{
 					Replicas: 1,
 				},
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
 			},
 		},
 		ObjectStorage: ObjectStorage{},
@@ -102,6 +109,11 @@ func TestTolerationsAreSetForEachComponent(t *testing.T) {
 		assert.Equal(t, tolerations, NewIndexGatewayStatefulSet(optsWithTolerations).Spec.Template.Spec.Tolerations)
 		assert.Empty(t, NewIndexGatewayStatefulSet(optsWithoutTolerations).Spec.Template.Spec.Tolerations)
 	})
+
+	t.Run("ruler", func(t *testing.T) {
+		assert.Equal(t, tolerations, NewRulerStatefulSet(optsWithTolerations).Spec.Template.Spec.Tolerations)
+		assert.Empty(t, NewRulerStatefulSet(optsWithoutTolerations).Spec.Template.Spec.Tolerations)
+	})
 }
 
 func TestNodeSelectorsAreSetForEachComponent(t *testing.T) {
@@ -133,6 +145,10 @@ func TestNodeSelectorsAreSetForEachComponent(t *testing.T) {
 					NodeSelector: nodeSelectors,
 					Replicas:     1,
 				},
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					NodeSelector: nodeSelectors,
+					Replicas:     1,
+				},
 			},
 		},
 		ObjectStorage: ObjectStorage{},
@@ -159,6 +175,9 @@ func TestNodeSelectorsAreSetForEachComponent(t *testing.T) {
 				IndexGateway: &lokiv1beta1.LokiComponentSpec{
 					Replicas: 1,
 				},
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
 			},
 		},
 		ObjectStorage: ObjectStorage{},
@@ -193,4 +212,9 @@ func TestNodeSelectorsAreSetForEachComponent(t *testing.T) {
 		assert.Equal(t, nodeSelectors, NewIndexGatewayStatefulSet(optsWithNodeSelectors).Spec.Template.Spec.NodeSelector)
 		assert.Empty(t, NewIndexGatewayStatefulSet(optsWithoutNodeSelectors).Spec.Template.Spec.NodeSelector)
 	})
+
+	t.Run("ruler", func(t *testing.T) {
+		assert.Equal(t, nodeSelectors, NewRulerStatefulSet(optsWithNodeSelectors).Spec.Template.Spec.NodeSelector)
+		assert.Empty(t, NewRulerStatefulSet(optsWithoutNodeSelectors).Spec.Template.Spec.NodeSelector)
+	})
 }
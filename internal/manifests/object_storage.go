@@ -0,0 +1,295 @@
+package manifests
+
+import (
+	"path"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/imdario/mergo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// GCSFileName is the key of the object storage secret holding
+	// the google service account credentials for GCS.
+	GCSFileName = "key.json"
+
+	storageSecretVolumeName  = "storage-secret"
+	storageSecretDirectory   = "/etc/storage/secrets"
+	storageCAVolumeName      = "storage-ca"
+	storageCADirectory       = "/etc/storage/ca"
+	storageCAHashKey         = "loki.openshift.io/storage-ca-hash"
+	defaultStorageCAKey      = "service-ca.crt"
+	envSSLCertDir            = "SSL_CERT_DIR"
+	sharedStorageVolumeName  = "shared-storage"
+	sharedStorageDirectory   = "/tmp/loki-shared"
+	storageSecretHashKey     = "loki.openshift.io/storage-secret-hash"
+	envGoogleCredentialsFile = "GOOGLE_APPLICATION_CREDENTIALS"
+
+	// The following environment variables are referenced
+	// in the storage_config section of loki-config.yaml.
+	envAWSAccessKeyID          = "AWS_ACCESS_KEY_ID"
+	envAWSAccessKeySecret      = "AWS_ACCESS_KEY_SECRET"
+	envAzureStorageAccountName = "AZURE_STORAGE_ACCOUNT_NAME"
+	envAzureStorageAccountKey  = "AZURE_STORAGE_ACCOUNT_KEY"
+	envSwiftUsername           = "SWIFT_USERNAME"
+	envSwiftPassword           = "SWIFT_PASSWORD"
+)
+
+var defaultSharedStorageSize = resource.MustParse("10Gi")
+
+// configureObjectStorage applies the object storage credentials to the
+// pod template of a Loki component. Credentials are passed as environment
+// variables from the object storage secret and a hash of the secret is
+// annotated to roll out the pods on credentials rotation. The same applies
+// to the CA bundle verifying the object storage endpoint if configured.
+func configureObjectStorage(p *corev1.PodTemplateSpec, opts Options) error {
+	if opts.ObjectStorage.SharedStore == lokiv1beta1.ObjectStorageSecretFilesystem {
+		return configureFilesystem(&p.Spec, opts.Name)
+	}
+
+	secretName := opts.Stack.Storage.Secret.Name
+
+	for key, hash := range map[string]string{
+		storageSecretHashKey: opts.ObjectStorage.SecretSHA1,
+		storageCAHashKey:     opts.ObjectStorage.CABundleSHA1,
+	} {
+		if hash == "" {
+			continue
+		}
+		if p.Annotations == nil {
+			p.Annotations = map[string]string{}
+		}
+		p.Annotations[key] = hash
+	}
+
+	if tls := opts.Stack.Storage.TLS; tls != nil {
+		if err := configureObjectStorageCA(&p.Spec, tls); err != nil {
+			return err
+		}
+	}
+
+	var env []corev1.EnvVar
+	switch opts.ObjectStorage.SharedStore {
+	case lokiv1beta1.ObjectStorageSecretAzure:
+		env = []corev1.EnvVar{
+			secretKeyEnvVar(envAzureStorageAccountName, secretName, "account_name"),
+			secretKeyEnvVar(envAzureStorageAccountKey, secretName, "account_key"),
+		}
+	case lokiv1beta1.ObjectStorageSecretGCS:
+		return configureGCS(&p.Spec, secretName)
+	case lokiv1beta1.ObjectStorageSecretSwift:
+		env = []corev1.EnvVar{
+			secretKeyEnvVar(envSwiftUsername, secretName, "username"),
+			secretKeyEnvVar(envSwiftPassword, secretName, "password"),
+		}
+	default:
+		env = []corev1.EnvVar{
+			secretKeyEnvVar(envAWSAccessKeyID, secretName, "access_key_id"),
+			secretKeyEnvVar(envAWSAccessKeySecret, secretName, "access_key_secret"),
+		}
+	}
+
+	if err := mergo.Merge(&p.Spec.Containers[0], corev1.Container{Env: env}, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge container")
+	}
+
+	return nil
+}
+
+// configureGCS mounts the GCS service account credentials from the
+// object storage secret and points the google client library to it.
+func configureGCS(podSpec *corev1.PodSpec, secretName string) error {
+	secretVolumeSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{
+				Name: storageSecretVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: secretName,
+					},
+				},
+			},
+		},
+	}
+	secretContainerSpec := corev1.Container{
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      storageSecretVolumeName,
+				ReadOnly:  false,
+				MountPath: storageSecretDirectory,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  envGoogleCredentialsFile,
+				Value: path.Join(storageSecretDirectory, GCSFileName),
+			},
+		},
+	}
+
+	if err := mergo.Merge(podSpec, secretVolumeSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge volumes")
+	}
+
+	if err := mergo.Merge(&podSpec.Containers[0], secretContainerSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge container")
+	}
+
+	return nil
+}
+
+// StorageCAKey returns the key of the CA bundle in the ConfigMap
+// referenced by the object storage TLS configuration.
+func StorageCAKey(tls *lokiv1beta1.ObjectStorageTLSSpec) string {
+	if tls.CAKey == "" {
+		return defaultStorageCAKey
+	}
+	return tls.CAKey
+}
+
+// configureObjectStorageCA mounts the CA bundle ConfigMap and adds its
+// directory to the directories of trusted certificates of the Go TLS stack
+// (see SSL_CERT_DIR). The system certificates remain trusted as they are
+// loaded from the system certificate file.
+func configureObjectStorageCA(podSpec *corev1.PodSpec, tls *lokiv1beta1.ObjectStorageTLSSpec) error {
+	key := StorageCAKey(tls)
+
+	volumeSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{
+				Name: storageCAVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: tls.CA,
+						},
+						Items: []corev1.KeyToPath{
+							{Key: key, Path: key},
+						},
+					},
+				},
+			},
+		},
+	}
+	containerSpec := corev1.Container{
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      storageCAVolumeName,
+				ReadOnly:  true,
+				MountPath: storageCADirectory,
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  envSSLCertDir,
+				Value: storageCADirectory,
+			},
+		},
+	}
+
+	if err := mergo.Merge(podSpec, volumeSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge volumes")
+	}
+
+	if err := mergo.Merge(&podSpec.Containers[0], containerSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge container")
+	}
+
+	return nil
+}
+
+// configureFilesystem mounts the shared persistent volume claim
+// storing the chunks and index for the filesystem storage type.
+func configureFilesystem(podSpec *corev1.PodSpec, stackName string) error {
+	volumeSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{
+				Name: sharedStorageVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: SharedStorageName(stackName),
+					},
+				},
+			},
+		},
+	}
+	containerSpec := corev1.Container{
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      sharedStorageVolumeName,
+				MountPath: sharedStorageDirectory,
+			},
+		},
+	}
+
+	if err := mergo.Merge(podSpec, volumeSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge volumes")
+	}
+
+	if err := mergo.Merge(&podSpec.Containers[0], containerSpec, mergo.WithAppendSlice); err != nil {
+		return kverrors.Wrap(err, "failed to merge container")
+	}
+
+	return nil
+}
+
+// NewSharedStoragePersistentVolumeClaim creates the persistent volume claim
+// shared by all components for the filesystem storage type. The claim is
+// ReadWriteMany unless ReadWriteOnce is requested for single-node clusters.
+func NewSharedStoragePersistentVolumeClaim(opts Options) *corev1.PersistentVolumeClaim {
+	size := defaultSharedStorageSize
+	accessMode := corev1.ReadWriteMany
+	var storageClassName *string
+
+	if fs := opts.Stack.Storage.Filesystem; fs != nil {
+		if fs.AccessMode == lokiv1beta1.ObjectStorageFilesystemReadWriteOnce {
+			accessMode = corev1.ReadWriteOnce
+		}
+		if fs.StorageSize != nil {
+			size = fs.StorageSize.DeepCopy()
+		}
+		if fs.StorageClassName != "" {
+			storageClassName = &fs.StorageClassName
+		}
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   SharedStorageName(opts.Name),
+			Labels: commonLabels(opts.Name),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				accessMode,
+			},
+			Resources: corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceStorage: size,
+				},
+			},
+			VolumeMode:       &volumeFileSystemMode,
+			StorageClassName: storageClassName,
+		},
+	}
+}
+
+func secretKeyEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: key,
+			},
+		},
+	}
+}
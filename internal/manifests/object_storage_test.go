@@ -0,0 +1,318 @@
+package manifests_test
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestBuildAll_GCSCredentialsMountedInLokiComponents(t *testing.T) {
+	opts := manifests.Options{
+		Name:      "test",
+		Namespace: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretGCS,
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: "gcs.secret",
+				},
+			},
+		},
+		ObjectStorage: manifests.ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretGCS,
+			GCS: &manifests.GCSStorageConfig{
+				Bucket: "loki",
+			},
+		},
+	}
+
+	err := manifests.ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	objects, err := manifests.BuildAll(opts)
+	require.NoError(t, err)
+
+	mounted := map[string]bool{}
+	for _, o := range objects {
+		var spec *corev1.PodSpec
+		switch obj := o.(type) {
+		case *appsv1.Deployment:
+			spec = &obj.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			spec = &obj.Spec.Template.Spec
+		default:
+			continue
+		}
+
+		mounted[o.GetName()] = hasGCSCredentials(spec)
+	}
+
+	require.Equal(t, map[string]bool{
+		manifests.DistributorName("test"):   true,
+		manifests.IngesterName("test"):      true,
+		manifests.QuerierName("test"):       true,
+		manifests.CompactorName("test"):     true,
+		manifests.QueryFrontendName("test"): true,
+		manifests.IndexGatewayName("test"):  true,
+	}, mounted)
+}
+
+func TestBuildAll_S3CredentialsFromSecretInLokiComponents(t *testing.T) {
+	opts := manifests.Options{
+		Name:      "test",
+		Namespace: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretS3,
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: "s3-secret",
+				},
+			},
+		},
+		ObjectStorage: manifests.ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretS3,
+			SecretSHA1:  "deadbeef",
+			S3: &manifests.S3StorageConfig{
+				Endpoint: "https://s3.example.com",
+				Buckets:  "loki",
+			},
+		},
+	}
+
+	err := manifests.ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	objects, err := manifests.BuildAll(opts)
+	require.NoError(t, err)
+
+	expectedEnv := []corev1.EnvVar{
+		{
+			Name: "AWS_ACCESS_KEY_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "s3-secret"},
+					Key:                  "access_key_id",
+				},
+			},
+		},
+		{
+			Name: "AWS_ACCESS_KEY_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "s3-secret"},
+					Key:                  "access_key_secret",
+				},
+			},
+		},
+	}
+
+	var count int
+	for _, o := range objects {
+		var tpl *corev1.PodTemplateSpec
+		switch obj := o.(type) {
+		case *appsv1.Deployment:
+			tpl = &obj.Spec.Template
+		case *appsv1.StatefulSet:
+			tpl = &obj.Spec.Template
+		default:
+			continue
+		}
+		count++
+
+		c := tpl.Spec.Containers[0]
+		require.Equal(t, expectedEnv, c.Env, o.GetName())
+		require.Contains(t, c.Args, "-config.expand-env=true", o.GetName())
+		require.Equal(t, "deadbeef", tpl.Annotations["loki.openshift.io/storage-secret-hash"], o.GetName())
+		require.Contains(t, tpl.Annotations, "loki.openshift.io/config-hash", o.GetName())
+	}
+
+	require.Equal(t, 6, count)
+}
+
+func TestBuildAll_ObjectStorageCAMountedInLokiComponents(t *testing.T) {
+	opts := manifests.Options{
+		Name:      "test",
+		Namespace: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretS3,
+				Secret: lokiv1beta1.ObjectStorageSecretSpec{
+					Name: "s3-secret",
+				},
+				TLS: &lokiv1beta1.ObjectStorageTLSSpec{
+					CA: "s3-ca",
+				},
+			},
+		},
+		ObjectStorage: manifests.ObjectStorage{
+			SharedStore:  lokiv1beta1.ObjectStorageSecretS3,
+			CABundleSHA1: "cafebabe",
+			S3: &manifests.S3StorageConfig{
+				Endpoint: "https://s3.example.com",
+				Buckets:  "loki",
+			},
+		},
+	}
+
+	err := manifests.ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	objects, err := manifests.BuildAll(opts)
+	require.NoError(t, err)
+
+	expectedVolume := corev1.Volume{
+		Name: "storage-ca",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "s3-ca"},
+				Items: []corev1.KeyToPath{
+					{Key: "service-ca.crt", Path: "service-ca.crt"},
+				},
+			},
+		},
+	}
+
+	var count int
+	for _, o := range objects {
+		var tpl *corev1.PodTemplateSpec
+		switch obj := o.(type) {
+		case *appsv1.Deployment:
+			tpl = &obj.Spec.Template
+		case *appsv1.StatefulSet:
+			tpl = &obj.Spec.Template
+		default:
+			continue
+		}
+		count++
+
+		c := tpl.Spec.Containers[0]
+		require.Contains(t, tpl.Spec.Volumes, expectedVolume, o.GetName())
+		require.Contains(t, c.VolumeMounts, corev1.VolumeMount{
+			Name:      "storage-ca",
+			ReadOnly:  true,
+			MountPath: "/etc/storage/ca",
+		}, o.GetName())
+		require.Contains(t, c.Env, corev1.EnvVar{Name: "SSL_CERT_DIR", Value: "/etc/storage/ca"}, o.GetName())
+		require.Equal(t, "cafebabe", tpl.Annotations["loki.openshift.io/storage-ca-hash"], o.GetName())
+	}
+
+	require.Equal(t, 6, count)
+}
+
+func TestBuildAll_FilesystemMountedInLokiComponents(t *testing.T) {
+	size := resource.MustParse("20Gi")
+	opts := manifests.Options{
+		Name:      "test",
+		Namespace: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXExtraSmall,
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{
+					StorageClassName: "nfs",
+					StorageSize:      &size,
+				},
+			},
+		},
+		ObjectStorage: manifests.ObjectStorage{
+			SharedStore: lokiv1beta1.ObjectStorageSecretFilesystem,
+		},
+	}
+
+	err := manifests.ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	objects, err := manifests.BuildAll(opts)
+	require.NoError(t, err)
+
+	var (
+		claim *corev1.PersistentVolumeClaim
+		count int
+	)
+	for _, o := range objects {
+		var tpl *corev1.PodTemplateSpec
+		switch obj := o.(type) {
+		case *corev1.PersistentVolumeClaim:
+			claim = obj
+			continue
+		case *appsv1.Deployment:
+			tpl = &obj.Spec.Template
+		case *appsv1.StatefulSet:
+			tpl = &obj.Spec.Template
+		default:
+			continue
+		}
+		count++
+
+		require.Contains(t, tpl.Spec.Volumes, corev1.Volume{
+			Name: "shared-storage",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: manifests.SharedStorageName("test"),
+				},
+			},
+		}, o.GetName())
+		require.Contains(t, tpl.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "shared-storage",
+			MountPath: "/tmp/loki-shared",
+		}, o.GetName())
+		require.Empty(t, tpl.Spec.Containers[0].Env, o.GetName())
+	}
+	require.Equal(t, 6, count)
+
+	require.NotNil(t, claim)
+	require.Equal(t, manifests.SharedStorageName("test"), claim.Name)
+	require.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, claim.Spec.AccessModes)
+	require.Equal(t, "nfs", *claim.Spec.StorageClassName)
+	require.Equal(t, size, claim.Spec.Resources.Requests[corev1.ResourceStorage])
+}
+
+func TestNewSharedStoragePersistentVolumeClaim_ReadWriteOnce(t *testing.T) {
+	opts := manifests.Options{
+		Name: "test",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Storage: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{
+					AccessMode: lokiv1beta1.ObjectStorageFilesystemReadWriteOnce,
+				},
+			},
+		},
+	}
+
+	claim := manifests.NewSharedStoragePersistentVolumeClaim(opts)
+	require.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, claim.Spec.AccessModes)
+	require.Nil(t, claim.Spec.StorageClassName)
+}
+
+func hasGCSCredentials(spec *corev1.PodSpec) bool {
+	var volume, mount, env bool
+	for _, v := range spec.Volumes {
+		if v.Name == "storage-secret" && v.Secret != nil && v.Secret.SecretName == "gcs.secret" {
+			volume = true
+		}
+	}
+
+	c := spec.Containers[0]
+	for _, m := range c.VolumeMounts {
+		if m.Name == "storage-secret" {
+			mount = true
+		}
+	}
+
+	for _, e := range c.Env {
+		if e.Name == "GOOGLE_APPLICATION_CREDENTIALS" && e.Value == "/etc/storage/secrets/"+manifests.GCSFileName {
+			env = true
+		}
+	}
+
+	return volume && mount && env
+}
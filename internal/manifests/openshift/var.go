@@ -31,6 +31,9 @@ var (
 	// cert-signing service to inject the service CA into the annotated
 	// configmap.
 	InjectCABundleKey = "service.beta.openshift.io/inject-cabundle"
+	// ServiceCABundleKey is the configmap key the cert-signing
+	// service injects the service CA into.
+	ServiceCABundleKey = "service-ca.crt"
 )
 
 func clusterRoleName(opts Options) string {
@@ -50,7 +53,13 @@ func serviceAccountName(opts Options) string {
 }
 
 func serviceCABundleName(opts Options) string {
-	return fmt.Sprintf("%s-ca-bundle", opts.BuildOpts.GatewayName)
+	return ServiceCABundleName(opts.BuildOpts.GatewayName)
+}
+
+// ServiceCABundleName returns the name of the configmap holding
+// the service CA injected by the cert-signing service for the gateway.
+func ServiceCABundleName(gatewayName string) string {
+	return fmt.Sprintf("%s-ca-bundle", gatewayName)
 }
 
 func serviceAccountAnnotations(opts Options) map[string]string {
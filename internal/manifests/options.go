@@ -13,12 +13,14 @@ type Options struct {
 	Namespace         string
 	Image             string
 	GatewayImage      string
+	MemcachedImage    string
 	GatewayBaseDomain string
 	ConfigSHA1        string
 
 	Flags FeatureFlags
 
 	Stack                lokiv1beta1.LokiStackSpec
+	SizeProfile          *SizeProfile
 	ResourceRequirements internal.ComponentResources
 
 	ObjectStorage ObjectStorage
@@ -26,15 +28,59 @@ type Options struct {
 	OpenShiftOptions openshift.Options
 	TenantSecrets    []*TenantSecrets
 	TenantConfigMap  map[string]openshift.TenantData
+
+	AlertingRules  []lokiv1beta1.AlertingRule
+	RecordingRules []lokiv1beta1.RecordingRule
 }
 
 // ObjectStorage for storage config.
 type ObjectStorage struct {
-	Endpoint        string
-	Region          string
-	Buckets         string
-	AccessKeyID     string
-	AccessKeySecret string
+	SharedStore lokiv1beta1.ObjectStorageSecretType
+	// SecretSHA1 is the hash of the object storage secret contents
+	// used to roll out the components on credentials rotation.
+	SecretSHA1 string
+	// CABundleSHA1 is the hash of the CA bundle verifying the object
+	// storage endpoint used to roll out the components on changes.
+	CABundleSHA1 string
+
+	Azure *AzureStorageConfig
+	GCS   *GCSStorageConfig
+	S3    *S3StorageConfig
+	Swift *SwiftStorageConfig
+}
+
+// AzureStorageConfig for Azure storage config
+type AzureStorageConfig struct {
+	Env       string
+	Container string
+}
+
+// GCSStorageConfig for GCS storage config
+type GCSStorageConfig struct {
+	Bucket string
+}
+
+// S3StorageConfig for S3 storage config
+type S3StorageConfig struct {
+	Endpoint string
+	Region   string
+	Buckets  string
+}
+
+// SwiftStorageConfig for Swift storage config
+type SwiftStorageConfig struct {
+	AuthURL           string
+	UserDomainName    string
+	UserDomainID      string
+	UserID            string
+	DomainID          string
+	DomainName        string
+	ProjectID         string
+	ProjectName       string
+	ProjectDomainID   string
+	ProjectDomainName string
+	Region            string
+	Container         string
 }
 
 // FeatureFlags contains flags that activate various features
@@ -44,6 +90,7 @@ type FeatureFlags struct {
 	EnableTLSServiceMonitorConfig   bool
 	EnableGateway                   bool
 	EnableGatewayRoute              bool
+	EnableObjectStorageProbe        bool
 }
 
 // TenantSecrets for clientID, clientSecret and issuerCAPath for tenant's authentication.
@@ -0,0 +1,85 @@
+package manifests
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildPodDisruptionBudgets builds the pod disruption budgets for all components
+func BuildPodDisruptionBudgets(opts Options) []client.Object {
+	tpl := opts.Stack.Template
+
+	objs := []client.Object{
+		NewIngesterPodDisruptionBudget(opts),
+		newStatelessPodDisruptionBudget(LabelDistributorComponent, DistributorName(opts.Name), opts.Name, minComponentReplicas(tpl.Distributor)),
+		newStatelessPodDisruptionBudget(LabelQuerierComponent, QuerierName(opts.Name), opts.Name, minComponentReplicas(tpl.Querier)),
+		newStatelessPodDisruptionBudget(LabelQueryFrontendComponent, QueryFrontendName(opts.Name), opts.Name, minComponentReplicas(tpl.QueryFrontend)),
+		newStatelessPodDisruptionBudget(LabelCompactorComponent, CompactorName(opts.Name), opts.Name, tpl.Compactor.Replicas),
+		newStatelessPodDisruptionBudget(LabelIndexGatewayComponent, IndexGatewayName(opts.Name), opts.Name, tpl.IndexGateway.Replicas),
+	}
+
+	if rulerEnabled(opts.Stack) {
+		objs = append(objs, newStatelessPodDisruptionBudget(LabelRulerComponent, RulerName(opts.Name), opts.Name, tpl.Ruler.Replicas))
+	}
+
+	if opts.Flags.EnableGateway {
+		replicas := int32(1)
+		if tpl.Gateway != nil {
+			replicas = minComponentReplicas(tpl.Gateway)
+		}
+		objs = append(objs, newStatelessPodDisruptionBudget(LabelGatewayComponent, GatewayName(opts.Name), opts.Name, replicas))
+	}
+
+	return objs
+}
+
+// NewIngesterPodDisruptionBudget creates a pod disruption budget for the ingesters.
+// A log stream write succeeds as long as a quorum of floor(RF/2)+1 ingesters
+// accepts it, thus up to (RF-1)/2 ingesters may be disrupted at once. At least
+// one ingester may be disrupted to keep nodes drainable for low replication
+// factors, as the write ahead log replays the writes of a single evicted ingester.
+func NewIngesterPodDisruptionBudget(opts Options) *policyv1.PodDisruptionBudget {
+	maxUnavailable := (opts.Stack.ReplicationFactor - 1) / 2
+	if maxUnavailable < 1 {
+		maxUnavailable = 1
+	}
+
+	l := ComponentLabels(LabelIngesterComponent, opts.Name)
+	return newPodDisruptionBudget(IngesterName(opts.Name), l, maxUnavailable)
+}
+
+// newStatelessPodDisruptionBudget creates a pod disruption budget keeping
+// at least half of the (minimum) replicas of a stateless component available.
+func newStatelessPodDisruptionBudget(component, name, stackName string, replicas int32) *policyv1.PodDisruptionBudget {
+	maxUnavailable := replicas / 2
+	if maxUnavailable < 1 {
+		maxUnavailable = 1
+	}
+
+	l := ComponentLabels(component, stackName)
+	return newPodDisruptionBudget(name, l, maxUnavailable)
+}
+
+func newPodDisruptionBudget(name string, l labels.Set, maxUnavailable int32) *policyv1.PodDisruptionBudget {
+	mu := intstr.FromInt(int(maxUnavailable))
+
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: policyv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: l,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &mu,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: l,
+			},
+		},
+	}
+}
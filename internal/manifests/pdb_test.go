@@ -0,0 +1,55 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+)
+
+func TestBuildPodDisruptionBudgets_MaxUnavailable(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Flags: FeatureFlags{
+			EnableGateway: true,
+		},
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size:              lokiv1beta1.SizeOneXMedium,
+			ReplicationFactor: 3,
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{Replicas: 4},
+			},
+		},
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	got := map[string]int{}
+	for _, o := range BuildPodDisruptionBudgets(opts) {
+		pdb := o.(*policyv1.PodDisruptionBudget)
+		require.Equal(t, map[string]string(pdb.Labels), pdb.Spec.Selector.MatchLabels)
+		got[pdb.Labels["loki.grafana.com/component"]] = pdb.Spec.MaxUnavailable.IntValue()
+	}
+
+	require.Equal(t, map[string]int{
+		LabelIngesterComponent:      1,
+		LabelDistributorComponent:   1,
+		LabelQuerierComponent:       2,
+		LabelQueryFrontendComponent: 1,
+		LabelCompactorComponent:     1,
+		LabelIndexGatewayComponent:  1,
+		LabelGatewayComponent:       1,
+	}, got)
+}
+
+func TestNewIngesterPodDisruptionBudget_DerivesFromReplicationFactor(t *testing.T) {
+	for rf, want := range map[int32]int{1: 1, 2: 1, 3: 1, 4: 1, 5: 2} {
+		opts := Options{
+			Name:  "abcd",
+			Stack: lokiv1beta1.LokiStackSpec{ReplicationFactor: rf},
+		}
+		pdb := NewIngesterPodDisruptionBudget(opts)
+		require.Equal(t, want, pdb.Spec.MaxUnavailable.IntValue(), "replication factor %d", rf)
+	}
+}
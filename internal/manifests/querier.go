@@ -10,7 +10,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,6 +22,10 @@ func BuildQuerier(opts Options) ([]client.Object, error) {
 		}
 	}
 
+	if err := configureObjectStorage(&deployment.Spec.Template, opts); err != nil {
+		return nil, err
+	}
+
 	return []client.Object{
 		deployment,
 		NewQuerierGRPCService(opts),
@@ -40,7 +43,7 @@ func NewQuerierDeployment(opts Options) *appsv1.Deployment {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -58,6 +61,7 @@ func NewQuerierDeployment(opts Options) *appsv1.Deployment {
 					"-target=querier",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -117,9 +121,10 @@ func NewQuerierDeployment(opts Options) *appsv1.Deployment {
 		},
 	}
 
+	podSpec.TopologySpreadConstraints = defaultTopologySpreadConstraints(ComponentLabels(LabelQuerierComponent, opts.Name))
+
 	if opts.Stack.Template != nil && opts.Stack.Template.Querier != nil {
-		podSpec.Tolerations = opts.Stack.Template.Querier.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.Querier.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.Querier)
 	}
 
 	l := ComponentLabels(LabelQuerierComponent, opts.Name)
@@ -135,7 +140,7 @@ func NewQuerierDeployment(opts Options) *appsv1.Deployment {
 			Labels: l,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(opts.Stack.Template.Querier.Replicas),
+			Replicas: deploymentReplicas(opts.Stack.Template.Querier),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels.Merge(l, GossipLabels()),
 			},
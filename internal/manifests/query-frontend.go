@@ -10,7 +10,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,6 +22,10 @@ func BuildQueryFrontend(opts Options) ([]client.Object, error) {
 		}
 	}
 
+	if err := configureObjectStorage(&deployment.Spec.Template, opts); err != nil {
+		return nil, err
+	}
+
 	return []client.Object{
 		deployment,
 		NewQueryFrontendGRPCService(opts),
@@ -40,7 +43,7 @@ func NewQueryFrontendDeployment(opts Options) *appsv1.Deployment {
 					ConfigMap: &corev1.ConfigMapVolumeSource{
 						DefaultMode: &defaultConfigMapMode,
 						LocalObjectReference: corev1.LocalObjectReference{
-							Name: lokiConfigMapName(opts.Name),
+							Name: LokiConfigMapName(opts.Name),
 						},
 					},
 				},
@@ -64,6 +67,7 @@ func NewQueryFrontendDeployment(opts Options) *appsv1.Deployment {
 					"-target=query-frontend",
 					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
 					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -124,8 +128,7 @@ func NewQueryFrontendDeployment(opts Options) *appsv1.Deployment {
 	}
 
 	if opts.Stack.Template != nil && opts.Stack.Template.QueryFrontend != nil {
-		podSpec.Tolerations = opts.Stack.Template.QueryFrontend.Tolerations
-		podSpec.NodeSelector = opts.Stack.Template.QueryFrontend.NodeSelector
+		configureScheduling(&podSpec, opts.Stack.Template.QueryFrontend)
 	}
 
 	l := ComponentLabels(LabelQueryFrontendComponent, opts.Name)
@@ -141,7 +144,7 @@ func NewQueryFrontendDeployment(opts Options) *appsv1.Deployment {
 			Labels: l,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(opts.Stack.Template.QueryFrontend.Replicas),
+			Replicas: deploymentReplicas(opts.Stack.Template.QueryFrontend),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: labels.Merge(l, GossipLabels()),
 			},
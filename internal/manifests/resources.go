@@ -0,0 +1,128 @@
+package manifests
+
+import (
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// componentResources returns the default resource requirements of all
+// components with the per component overrides of the stack applied.
+func componentResources(defaults internal.ComponentResources, spec lokiv1beta1.LokiStackSpec) internal.ComponentResources {
+	res := internal.ComponentResources{
+		Compactor:     copyResourceRequirements(defaults.Compactor),
+		Ingester:      copyResourceRequirements(defaults.Ingester),
+		IndexGateway:  copyResourceRequirements(defaults.IndexGateway),
+		Ruler:         copyResourceRequirements(defaults.Ruler),
+		WALStorage:    copyResourceRequirements(defaults.WALStorage),
+		Querier:       *defaults.Querier.DeepCopy(),
+		Distributor:   *defaults.Distributor.DeepCopy(),
+		QueryFrontend: *defaults.QueryFrontend.DeepCopy(),
+		Gateway:       *defaults.Gateway.DeepCopy(),
+	}
+
+	t := spec.Template
+	if t == nil {
+		return res
+	}
+
+	applyStorageOverrides(&res.Compactor, t.Compactor)
+	applyStorageOverrides(&res.Ingester, t.Ingester)
+	applyStorageOverrides(&res.IndexGateway, t.IndexGateway)
+	applyStorageOverrides(&res.Ruler, t.Ruler)
+	applyOverrides(&res.Querier, t.Querier)
+	applyOverrides(&res.Distributor, t.Distributor)
+	applyOverrides(&res.QueryFrontend, t.QueryFrontend)
+	applyOverrides(&res.Gateway, t.Gateway)
+
+	return res
+}
+
+// ValidateResources validates that the requests of each component do not
+// exceed its limits after merging the overrides of the stack. It expects
+// the options to be conformed to the build specifications by
+// ApplyDefaultSettings.
+func ValidateResources(opts Options) error {
+	res := opts.ResourceRequirements
+
+	components := []struct {
+		name string
+		req  corev1.ResourceList
+		lim  corev1.ResourceList
+	}{
+		{LabelCompactorComponent, res.Compactor.Requests, res.Compactor.Limits},
+		{LabelIngesterComponent, res.Ingester.Requests, res.Ingester.Limits},
+		{LabelIndexGatewayComponent, res.IndexGateway.Requests, res.IndexGateway.Limits},
+		{LabelRulerComponent, res.Ruler.Requests, res.Ruler.Limits},
+		{LabelQuerierComponent, res.Querier.Requests, res.Querier.Limits},
+		{LabelDistributorComponent, res.Distributor.Requests, res.Distributor.Limits},
+		{LabelQueryFrontendComponent, res.QueryFrontend.Requests, res.QueryFrontend.Limits},
+		{LabelGatewayComponent, res.Gateway.Requests, res.Gateway.Limits},
+	}
+
+	for _, c := range components {
+		for name, req := range c.req {
+			lim, ok := c.lim[name]
+			if ok && req.Cmp(lim) > 0 {
+				return kverrors.New("resource request exceeds limit",
+					"component", c.name,
+					"resource", name,
+					"request", req.String(),
+					"limit", lim.String(),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyStorageOverrides(r *internal.ResourceRequirements, c *lokiv1beta1.LokiComponentSpec) {
+	if c == nil {
+		return
+	}
+
+	if c.Resources != nil {
+		r.Requests = mergeResourceList(r.Requests, c.Resources.Requests)
+		r.Limits = mergeResourceList(r.Limits, c.Resources.Limits)
+	}
+
+	if c.StorageSize != nil {
+		r.PVCSize = c.StorageSize.DeepCopy()
+	}
+}
+
+func applyOverrides(r *corev1.ResourceRequirements, c *lokiv1beta1.LokiComponentSpec) {
+	if c == nil || c.Resources == nil {
+		return
+	}
+
+	r.Requests = mergeResourceList(r.Requests, c.Resources.Requests)
+	r.Limits = mergeResourceList(r.Limits, c.Resources.Limits)
+}
+
+// mergeResourceList overrides each resource in dst with the one in src.
+func mergeResourceList(dst, src corev1.ResourceList) corev1.ResourceList {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = corev1.ResourceList{}
+	}
+
+	for name, q := range src {
+		dst[name] = q.DeepCopy()
+	}
+
+	return dst
+}
+
+func copyResourceRequirements(r internal.ResourceRequirements) internal.ResourceRequirements {
+	return internal.ResourceRequirements{
+		Limits:   r.Limits.DeepCopy(),
+		Requests: r.Requests.DeepCopy(),
+		PVCSize:  r.PVCSize.DeepCopy(),
+	}
+}
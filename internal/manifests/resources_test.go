@@ -0,0 +1,167 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyDefaultSettings_MergesComponentResourceOverrides(t *testing.T) {
+	size := lokiv1beta1.SizeOneXSmall
+	defaults := internal.ResourceRequirementsTable[size]
+	storageSize := resource.MustParse("50Gi")
+
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: size,
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("10Gi"),
+						},
+					},
+				},
+				Compactor: &lokiv1beta1.LokiComponentSpec{
+					StorageSize: &storageSize,
+				},
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("8"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("10"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	res := opts.ResourceRequirements
+
+	// Overridden requests replace the defaults per resource
+	require.Equal(t, resource.MustParse("10Gi"), res.Ingester.Requests[corev1.ResourceMemory])
+	require.Equal(t, defaults.Ingester.Requests[corev1.ResourceCPU], res.Ingester.Requests[corev1.ResourceCPU])
+	require.Equal(t, defaults.Ingester.PVCSize, res.Ingester.PVCSize)
+
+	require.Equal(t, storageSize, res.Compactor.PVCSize)
+	require.Equal(t, defaults.Compactor.Requests, res.Compactor.Requests)
+
+	require.Equal(t, resource.MustParse("8"), res.Querier.Requests[corev1.ResourceCPU])
+	require.Equal(t, resource.MustParse("10"), res.Querier.Limits[corev1.ResourceCPU])
+	require.Equal(t, defaults.Querier.Requests[corev1.ResourceMemory], res.Querier.Requests[corev1.ResourceMemory])
+
+	// Size defaults must stay untouched
+	require.NotEqual(t, resource.MustParse("10Gi"), internal.ResourceRequirementsTable[size].Ingester.Requests[corev1.ResourceMemory])
+	require.Nil(t, internal.ResourceRequirementsTable[size].Querier.Limits)
+
+	// Overrides flow into derived config values
+	cfg := ConfigOptions(opts)
+	require.Equal(t, "5368709120", cfg.WriteAheadLog.ReplayMemoryCeiling())
+	require.Equal(t, int32(8)/opts.Stack.Template.QueryFrontend.Replicas, cfg.QueryParallelism.Value())
+}
+
+func TestApplyDefaultSettings_ResourceOverridesApplyToWorkloads(t *testing.T) {
+	storageSize := resource.MustParse("50Gi")
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("12Gi"),
+						},
+					},
+					StorageSize: &storageSize,
+				},
+			},
+		},
+	}
+
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	sts := NewIngesterStatefulSet(opts)
+	require.Equal(t, resource.MustParse("12Gi"), sts.Spec.Template.Spec.Containers[0].Resources.Limits[corev1.ResourceMemory])
+	require.Equal(t, storageSize, sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+}
+
+func TestValidateResources(t *testing.T) {
+	table := []struct {
+		desc     string
+		template *lokiv1beta1.LokiTemplateSpec
+		wantErr  bool
+	}{
+		{
+			desc: "defaults",
+		},
+		{
+			desc: "request within limit",
+			template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					},
+				},
+			},
+		},
+		{
+			desc: "request above limit",
+			template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "size profile request above limit override",
+			template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Resources: &corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1m")},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := Options{
+				Name:      "abcd",
+				Namespace: "efgh",
+				Stack: lokiv1beta1.LokiStackSpec{
+					Size:     lokiv1beta1.SizeOneXSmall,
+					Template: tc.template,
+				},
+			}
+			require.NoError(t, ApplyDefaultSettings(&opts))
+
+			err := ValidateResources(opts)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package manifests
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// schemaIndexPeriod is the index period of the schema_config
+	// in loki-config.yaml. Retention periods must be a multiple of it.
+	schemaIndexPeriod = 24 * time.Hour
+
+	retentionDeleteWorkerCount           uint = 150
+	retentionDeleteWorkerCountExtraSmall uint = 10
+)
+
+// ValidateRetention validates the global and per tenant retention
+// periods against the schema index period and the stream selectors
+// for well-formedness.
+func ValidateRetention(spec lokiv1beta1.LokiStackSpec) error {
+	if spec.Limits == nil {
+		return nil
+	}
+
+	if spec.Limits.Global != nil {
+		if err := validateRetentionLimit(spec.Limits.Global.Retention); err != nil {
+			return kverrors.Wrap(err, "invalid global retention")
+		}
+	}
+
+	for tenant, l := range spec.Limits.Tenants {
+		if err := validateRetentionLimit(l.Retention); err != nil {
+			return kverrors.Wrap(err, "invalid tenant retention", "tenant", tenant)
+		}
+	}
+
+	return nil
+}
+
+func validateRetentionLimit(r *lokiv1beta1.RetentionLimitSpec) error {
+	if r == nil {
+		return nil
+	}
+
+	if err := validateRetentionPeriod(r.Period); err != nil {
+		return err
+	}
+
+	for _, s := range r.Streams {
+		if err := validateRetentionPeriod(s.Period); err != nil {
+			return kverrors.Wrap(err, "invalid stream retention", "selector", s.Selector)
+		}
+
+		sel := strings.TrimSpace(s.Selector)
+		if !strings.HasPrefix(sel, "{") || !strings.HasSuffix(sel, "}") {
+			return kverrors.New("stream selector must be enclosed in curly braces", "selector", s.Selector)
+		}
+
+		if strings.Contains(sel, "'") {
+			return kverrors.New("stream selector must not contain single quotes", "selector", s.Selector)
+		}
+	}
+
+	return nil
+}
+
+func validateRetentionPeriod(p lokiv1beta1.PrometheusDuration) error {
+	d, err := model.ParseDuration(string(p))
+	if err != nil {
+		return kverrors.Wrap(err, "failed to parse retention period", "period", p)
+	}
+
+	if time.Duration(d) < schemaIndexPeriod {
+		return kverrors.New("retention period must be at least the schema index period",
+			"period", p,
+			"index_period", schemaIndexPeriod,
+		)
+	}
+
+	if time.Duration(d)%schemaIndexPeriod != 0 {
+		return kverrors.New("retention period must be a multiple of the schema index period",
+			"period", p,
+			"index_period", schemaIndexPeriod,
+		)
+	}
+
+	return nil
+}
+
+func retentionEnabled(spec lokiv1beta1.LokiStackSpec) bool {
+	if spec.Limits == nil {
+		return false
+	}
+
+	if spec.Limits.Global != nil && spec.Limits.Global.Retention != nil {
+		return true
+	}
+
+	for _, l := range spec.Limits.Tenants {
+		if l.Retention != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func retentionConfig(spec lokiv1beta1.LokiStackSpec) config.RetentionOptions {
+	if !retentionEnabled(spec) {
+		return config.RetentionOptions{}
+	}
+
+	workers := retentionDeleteWorkerCount
+	if spec.Size == lokiv1beta1.SizeOneXExtraSmall {
+		workers = retentionDeleteWorkerCountExtraSmall
+	}
+
+	return config.RetentionOptions{
+		Enabled:           true,
+		DeleteWorkerCount: workers,
+	}
+}
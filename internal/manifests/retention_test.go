@@ -0,0 +1,94 @@
+package manifests_test
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRetention(t *testing.T) {
+	table := []struct {
+		desc    string
+		spec    *lokiv1beta1.RetentionLimitSpec
+		wantErr bool
+	}{
+		{
+			desc: "no retention",
+		},
+		{
+			desc: "valid period and streams",
+			spec: &lokiv1beta1.RetentionLimitSpec{
+				Period: "7d",
+				Streams: []*lokiv1beta1.RetentionStreamSpec{
+					{Period: "48h", Priority: 1, Selector: `{namespace="dev"}`},
+				},
+			},
+		},
+		{
+			desc:    "invalid duration",
+			spec:    &lokiv1beta1.RetentionLimitSpec{Period: "1x"},
+			wantErr: true,
+		},
+		{
+			desc:    "period lower than index period",
+			spec:    &lokiv1beta1.RetentionLimitSpec{Period: "12h"},
+			wantErr: true,
+		},
+		{
+			desc:    "period not a multiple of index period",
+			spec:    &lokiv1beta1.RetentionLimitSpec{Period: "36h"},
+			wantErr: true,
+		},
+		{
+			desc: "stream period not a multiple of index period",
+			spec: &lokiv1beta1.RetentionLimitSpec{
+				Period: "24h",
+				Streams: []*lokiv1beta1.RetentionStreamSpec{
+					{Period: "30h", Selector: `{namespace="dev"}`},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "stream selector without braces",
+			spec: &lokiv1beta1.RetentionLimitSpec{
+				Period: "24h",
+				Streams: []*lokiv1beta1.RetentionStreamSpec{
+					{Period: "24h", Selector: `namespace="dev"`},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			global := lokiv1beta1.LokiStackSpec{
+				Limits: &lokiv1beta1.LimitsSpec{
+					Global: &lokiv1beta1.LimitsTemplateSpec{Retention: tc.spec},
+				},
+			}
+			tenant := lokiv1beta1.LokiStackSpec{
+				Limits: &lokiv1beta1.LimitsSpec{
+					Tenants: map[string]lokiv1beta1.LimitsTemplateSpec{
+						"tenant-a": {Retention: tc.spec},
+					},
+				},
+			}
+
+			for _, spec := range []lokiv1beta1.LokiStackSpec{global, tenant} {
+				err := manifests.ValidateRetention(spec)
+				if tc.wantErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+		})
+	}
+}
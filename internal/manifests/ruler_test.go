@@ -0,0 +1,108 @@
+package manifests_test
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewRulerStatefulSet_HasTemplateConfigHashAnnotation(t *testing.T) {
+	ss := manifests.NewRulerStatefulSet(manifests.Options{
+		Name:       "abcd",
+		Namespace:  "efgh",
+		ConfigSHA1: "deadbeef",
+		Stack: lokiv1beta1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	expected := "loki.openshift.io/config-hash"
+	annotations := ss.Spec.Template.Annotations
+	require.Contains(t, annotations, expected)
+	require.Equal(t, annotations[expected], "deadbeef")
+}
+
+func TestNewRulerStatefulSet_SelectorMatchesLabels(t *testing.T) {
+	// You must set the .spec.selector field of a StatefulSet to match the labels of
+	// its .spec.template.metadata.labels. Prior to Kubernetes 1.8, the
+	// .spec.selector field was defaulted when omitted. In 1.8 and later versions,
+	// failing to specify a matching Pod Selector will result in a validation error
+	// during StatefulSet creation.
+	// See https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#pod-selector
+	ss := manifests.NewRulerStatefulSet(manifests.Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	l := ss.Spec.Template.GetObjectMeta().GetLabels()
+	for key, value := range ss.Spec.Selector.MatchLabels {
+		require.Contains(t, l, key)
+		require.Equal(t, l[key], value)
+	}
+}
+
+func TestNewRulerStatefulSet_MountsRulesPerTenant(t *testing.T) {
+	ss := manifests.NewRulerStatefulSet(manifests.Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+		AlertingRules: []lokiv1beta1.AlertingRule{
+			{
+				ObjectMeta: metav1ObjectMeta("alerts", "ns1", "uid1"),
+				Spec: lokiv1beta1.AlertingRuleSpec{
+					TenantID: "application",
+				},
+			},
+		},
+		RecordingRules: []lokiv1beta1.RecordingRule{
+			{
+				ObjectMeta: metav1ObjectMeta("records", "ns2", "uid2"),
+				Spec: lokiv1beta1.RecordingRuleSpec{
+					TenantID: "infrastructure",
+				},
+			},
+		},
+	})
+
+	var items []corev1.KeyToPath
+	for _, v := range ss.Spec.Template.Spec.Volumes {
+		if v.ConfigMap != nil && v.ConfigMap.Name == manifests.RulesConfigMapName("abcd") {
+			items = v.ConfigMap.Items
+		}
+	}
+
+	expected := []corev1.KeyToPath{
+		{
+			Key:  "ns1-alerts-uid1.yaml",
+			Path: "application/ns1-alerts-uid1.yaml",
+		},
+		{
+			Key:  "ns2-records-uid2.yaml",
+			Path: "infrastructure/ns2-records-uid2.yaml",
+		},
+	}
+	require.Equal(t, expected, items)
+}
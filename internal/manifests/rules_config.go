@@ -0,0 +1,77 @@
+package manifests
+
+import (
+	"fmt"
+	"path"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/rules"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RulesConfigMap returns a ConfigMap resource that contains
+// all loki alerting and recording rules as YAML data.
+func RulesConfigMap(opts Options) (*corev1.ConfigMap, error) {
+	data := make(map[string]string)
+
+	for _, r := range opts.AlertingRules {
+		c, err := rules.MarshalAlertingRule(r)
+		if err != nil {
+			return nil, err
+		}
+		data[rulesFileName(r.Namespace, r.Name, r.UID)] = c
+	}
+
+	for _, r := range opts.RecordingRules {
+		c, err := rules.MarshalRecordingRule(r)
+		if err != nil {
+			return nil, err
+		}
+		data[rulesFileName(r.Namespace, r.Name, r.UID)] = c
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   RulesConfigMapName(opts.Name),
+			Labels: commonLabels(opts.Name),
+		},
+		Data: data,
+	}, nil
+}
+
+// rulesConfigMapItems maps each rules file to a per-tenant directory
+// as expected by the ruler local storage.
+func rulesConfigMapItems(opts Options) []corev1.KeyToPath {
+	var items []corev1.KeyToPath
+
+	for _, r := range opts.AlertingRules {
+		items = append(items, rulesKeyToPath(r.Spec.TenantID, rulesFileName(r.Namespace, r.Name, r.UID)))
+	}
+
+	for _, r := range opts.RecordingRules {
+		items = append(items, rulesKeyToPath(r.Spec.TenantID, rulesFileName(r.Namespace, r.Name, r.UID)))
+	}
+
+	return items
+}
+
+func rulesKeyToPath(tenantID, key string) corev1.KeyToPath {
+	return corev1.KeyToPath{
+		Key:  key,
+		Path: path.Join(tenantID, key),
+	}
+}
+
+func rulesFileName(namespace, name string, uid types.UID) string {
+	return fmt.Sprintf("%s-%s-%s.yaml", namespace, name, uid)
+}
+
+func rulerEnabled(spec lokiv1beta1.LokiStackSpec) bool {
+	return spec.Rules != nil && spec.Rules.Enabled
+}
@@ -0,0 +1,97 @@
+package manifests_test
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRulesConfigMap_ReturnsDataEntriesPerRule(t *testing.T) {
+	opts := manifests.Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		AlertingRules: []lokiv1beta1.AlertingRule{
+			{
+				ObjectMeta: metav1ObjectMeta("alerts", "ns1", "uid1"),
+				Spec: lokiv1beta1.AlertingRuleSpec{
+					TenantID: "application",
+					Groups: []*lokiv1beta1.AlertingRuleGroup{
+						{
+							Name:     "first",
+							Interval: "1m",
+							Rules: []*lokiv1beta1.AlertingRuleGroupSpec{
+								{
+									Alert: "HighErrorRate",
+									Expr:  `sum(rate({app="foo"} |= "error" [5m])) > 1`,
+									For:   "10m",
+									Labels: map[string]string{
+										"severity": "page",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		RecordingRules: []lokiv1beta1.RecordingRule{
+			{
+				ObjectMeta: metav1ObjectMeta("records", "ns2", "uid2"),
+				Spec: lokiv1beta1.RecordingRuleSpec{
+					TenantID: "application",
+					Groups: []*lokiv1beta1.RecordingRuleGroup{
+						{
+							Name:     "second",
+							Interval: "1m",
+							Rules: []*lokiv1beta1.RecordingRuleGroupSpec{
+								{
+									Record: "app:requests:rate5m",
+									Expr:   `sum(rate({app="foo"}[5m]))`,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expAlerts := `
+groups:
+  - name: first
+    interval: 1m
+    rules:
+      - alert: HighErrorRate
+        expr: sum(rate({app="foo"} |= "error" [5m])) > 1
+        for: 10m
+        labels:
+          severity: page
+`
+	expRecords := `
+groups:
+  - name: second
+    interval: 1m
+    rules:
+      - record: app:requests:rate5m
+        expr: sum(rate({app="foo"}[5m]))
+`
+
+	cm, err := manifests.RulesConfigMap(opts)
+	require.NoError(t, err)
+	require.Equal(t, manifests.RulesConfigMapName("abcd"), cm.Name)
+	require.Len(t, cm.Data, 2)
+	require.YAMLEq(t, expAlerts, cm.Data["ns1-alerts-uid1.yaml"])
+	require.YAMLEq(t, expRecords, cm.Data["ns2-records-uid2.yaml"])
+}
+
+func metav1ObjectMeta(name, namespace, uid string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		UID:       types.UID(uid),
+	}
+}
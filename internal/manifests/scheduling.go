@@ -0,0 +1,62 @@
+package manifests
+
+import (
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	topologyKeyHostname = "kubernetes.io/hostname"
+	topologyKeyZone     = "topology.kubernetes.io/zone"
+)
+
+// defaultTopologySpreadConstraints spreads the component replicas evenly
+// across nodes and zones. The constraints are best-effort to keep the
+// replicas schedulable on clusters with fewer nodes or zones than replicas.
+func defaultTopologySpreadConstraints(l labels.Set) []corev1.TopologySpreadConstraint {
+	var constraints []corev1.TopologySpreadConstraint
+	for _, key := range []string{topologyKeyHostname, topologyKeyZone} {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       key,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: l,
+			},
+		})
+	}
+	return constraints
+}
+
+// ingesterTopologySpreadConstraints spreads the ingester replicas like
+// defaultTopologySpreadConstraints, but enforces the spread across nodes.
+// Losing a node must not take down more ingesters than the others hold,
+// as replicas on the same node share the fate of the node and thus may
+// hold all copies of a stream. Spreading across zones stays best-effort.
+func ingesterTopologySpreadConstraints(l labels.Set) []corev1.TopologySpreadConstraint {
+	constraints := defaultTopologySpreadConstraints(l)
+	for i := range constraints {
+		if constraints[i].TopologyKey == topologyKeyHostname {
+			constraints[i].WhenUnsatisfiable = corev1.DoNotSchedule
+		}
+	}
+	return constraints
+}
+
+// configureScheduling applies the scheduling requirements of the
+// component spec to the pod spec. Affinity and topology spread
+// constraints override the operator defaults only if specified.
+func configureScheduling(podSpec *corev1.PodSpec, c *lokiv1beta1.LokiComponentSpec) {
+	podSpec.Tolerations = c.Tolerations
+	podSpec.NodeSelector = c.NodeSelector
+
+	if c.Affinity != nil {
+		podSpec.Affinity = c.Affinity
+	}
+
+	if len(c.TopologySpreadConstraints) > 0 {
+		podSpec.TopologySpreadConstraints = c.TopologySpreadConstraints
+	}
+}
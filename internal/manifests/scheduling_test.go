@@ -0,0 +1,129 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildAll_SpreadsComponentsAcrossNodesAndZonesByDefault(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXMedium,
+		},
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	type test struct {
+		component string
+		podSpec   corev1.PodSpec
+	}
+	table := []test{
+		{
+			component: LabelIngesterComponent,
+			podSpec:   NewIngesterStatefulSet(opts).Spec.Template.Spec,
+		},
+		{
+			component: LabelQuerierComponent,
+			podSpec:   NewQuerierDeployment(opts).Spec.Template.Spec,
+		},
+		{
+			component: LabelDistributorComponent,
+			podSpec:   NewDistributorDeployment(opts).Spec.Template.Spec,
+		},
+		{
+			component: LabelGatewayComponent,
+			podSpec:   NewGatewayDeployment(opts, "deadbeef").Spec.Template.Spec,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.component, func(t *testing.T) {
+			t.Parallel()
+
+			constraints := tst.podSpec.TopologySpreadConstraints
+			require.Len(t, constraints, 2)
+			require.Equal(t, topologyKeyHostname, constraints[0].TopologyKey)
+			require.Equal(t, topologyKeyZone, constraints[1].TopologyKey)
+
+			for _, c := range constraints {
+				require.Equal(t, int32(1), c.MaxSkew)
+				require.Equal(t, map[string]string(ComponentLabels(tst.component, opts.Name)), c.LabelSelector.MatchLabels)
+			}
+
+			// Ingesters must not share a node unless there are more ingesters than nodes
+			if tst.component == LabelIngesterComponent {
+				require.Equal(t, corev1.DoNotSchedule, constraints[0].WhenUnsatisfiable)
+			} else {
+				require.Equal(t, corev1.ScheduleAnyway, constraints[0].WhenUnsatisfiable)
+			}
+			require.Equal(t, corev1.ScheduleAnyway, constraints[1].WhenUnsatisfiable)
+		})
+	}
+
+	// Components without default spread constraints
+	require.Empty(t, NewCompactorStatefulSet(opts).Spec.Template.Spec.TopologySpreadConstraints)
+}
+
+func TestBuildAll_ComponentSchedulingOverrides(t *testing.T) {
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "node-role.kubernetes.io/infra",
+								Operator: corev1.NodeSelectorOpExists,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	constraints := []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           2,
+			TopologyKey:       topologyKeyZone,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+		},
+	}
+
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size: lokiv1beta1.SizeOneXSmall,
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Affinity:                  affinity,
+					TopologySpreadConstraints: constraints,
+				},
+				Compactor: &lokiv1beta1.LokiComponentSpec{
+					Affinity: affinity,
+				},
+				Gateway: &lokiv1beta1.LokiComponentSpec{
+					Affinity: affinity,
+				},
+			},
+		},
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	ingester := NewIngesterStatefulSet(opts).Spec.Template.Spec
+	require.Equal(t, affinity, ingester.Affinity)
+	require.Equal(t, constraints, ingester.TopologySpreadConstraints)
+
+	compactor := NewCompactorStatefulSet(opts).Spec.Template.Spec
+	require.Equal(t, affinity, compactor.Affinity)
+
+	// The gateway keeps its default spread constraints if only the affinity is overridden
+	gateway := NewGatewayDeployment(opts, "deadbeef").Spec.Template.Spec
+	require.Equal(t, affinity, gateway.Affinity)
+	require.Len(t, gateway.TopologySpreadConstraints, 2)
+}
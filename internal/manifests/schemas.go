@@ -0,0 +1,128 @@
+package manifests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
+)
+
+const (
+	// schemaDateLayout is the layout of the storage schema effective dates.
+	schemaDateLayout = "2006-01-02"
+
+	indexStoreBoltDBShipper = "boltdb-shipper"
+	indexStoreTSDB          = "tsdb"
+)
+
+// defaultSchemas is the storage schema of stacks without schemas, i.e. the
+// single schema used before the schemas became configurable.
+var defaultSchemas = []lokiv1beta1.ObjectStorageSchema{
+	{
+		Version:       lokiv1beta1.ObjectStorageSchemaV11,
+		EffectiveDate: "2020-10-01",
+	},
+}
+
+// Schemas returns the storage schemas of the stack or the default
+// schema if none are set.
+func Schemas(spec lokiv1beta1.LokiStackSpec) []lokiv1beta1.ObjectStorageSchema {
+	if len(spec.Storage.Schemas) == 0 {
+		return defaultSchemas
+	}
+	return spec.Storage.Schemas
+}
+
+// AppliedSchemas returns the storage schemas applied to a stack. Stacks deployed
+// before the applied schemas were recorded in their status use the default schema.
+func AppliedSchemas(applied []lokiv1beta1.ObjectStorageSchema, deployed bool) []lokiv1beta1.ObjectStorageSchema {
+	if len(applied) == 0 && deployed {
+		return defaultSchemas
+	}
+	return applied
+}
+
+// ValidateSchemas validates that the storage schemas have well-formed
+// effective dates in strictly ascending order.
+func ValidateSchemas(schemas []lokiv1beta1.ObjectStorageSchema) error {
+	var prev time.Time
+	for i, s := range schemas {
+		date, err := time.Parse(schemaDateLayout, string(s.EffectiveDate))
+		if err != nil {
+			return kverrors.Wrap(err, fmt.Sprintf("invalid effective date %s", s.EffectiveDate), "version", s.Version)
+		}
+
+		if i > 0 && !date.After(prev) {
+			return kverrors.New(fmt.Sprintf("schema effective from %s must be ordered after the previous schemas", s.EffectiveDate),
+				"version", s.Version)
+		}
+		prev = date
+	}
+
+	return nil
+}
+
+// ValidateSchemaUpdate validates that the storage schemas in effect at the
+// given time, i.e. with an effective date up to its UTC date, are the same
+// as the applied ones. Changing, adding or removing schemas in effect makes
+// the logs written with them unreadable. No applied schemas are assumed for
+// new stacks, thus any schemas are valid, see AppliedSchemas for deployed ones.
+func ValidateSchemaUpdate(schemas, applied []lokiv1beta1.ObjectStorageSchema, now time.Time) error {
+	if len(applied) == 0 {
+		return nil
+	}
+
+	desired, current := schemasInEffect(schemas, now), schemasInEffect(applied, now)
+
+	for i, c := range current {
+		if i >= len(desired) {
+			return kverrors.New(fmt.Sprintf("schema effective from %s must not be removed", c.EffectiveDate))
+		}
+		if desired[i] != c {
+			return kverrors.New(fmt.Sprintf("schema effective from %s must not be changed", c.EffectiveDate),
+				"version", c.Version)
+		}
+	}
+
+	if len(desired) > len(current) {
+		d := desired[len(current)]
+		return kverrors.New(fmt.Sprintf("new schema effective from %s must have an effective date after the current date", d.EffectiveDate),
+			"version", d.Version)
+	}
+
+	return nil
+}
+
+func schemasInEffect(schemas []lokiv1beta1.ObjectStorageSchema, now time.Time) []lokiv1beta1.ObjectStorageSchema {
+	today := now.UTC().Format(schemaDateLayout)
+
+	var in []lokiv1beta1.ObjectStorageSchema
+	for _, s := range schemas {
+		// Dates in the layout order lexically as in time
+		if string(s.EffectiveDate) <= today {
+			in = append(in, s)
+		}
+	}
+	return in
+}
+
+func schemaConfig(spec lokiv1beta1.LokiStackSpec) config.Schemas {
+	var cfg config.Schemas
+	for _, s := range Schemas(spec) {
+		cfg = append(cfg, config.Schema{
+			From:       string(s.EffectiveDate),
+			Version:    string(s.Version),
+			IndexStore: indexStore(s.Version),
+		})
+	}
+	return cfg
+}
+
+func indexStore(v lokiv1beta1.ObjectStorageSchemaVersion) string {
+	if v == lokiv1beta1.ObjectStorageSchemaV13 {
+		return indexStoreTSDB
+	}
+	return indexStoreBoltDBShipper
+}
@@ -0,0 +1,137 @@
+package manifests
+
+import (
+	"testing"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	schemaV11 = lokiv1beta1.ObjectStorageSchema{Version: lokiv1beta1.ObjectStorageSchemaV11, EffectiveDate: "2020-10-01"}
+	schemaV12 = lokiv1beta1.ObjectStorageSchema{Version: lokiv1beta1.ObjectStorageSchemaV12, EffectiveDate: "2022-06-01"}
+	schemaV13 = lokiv1beta1.ObjectStorageSchema{Version: lokiv1beta1.ObjectStorageSchemaV13, EffectiveDate: "2024-04-01"}
+)
+
+func TestValidateSchemas(t *testing.T) {
+	table := []struct {
+		name    string
+		schemas []lokiv1beta1.ObjectStorageSchema
+		wantErr bool
+	}{
+		{
+			name: "no schemas",
+		},
+		{
+			name:    "ordered schemas",
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12, schemaV13},
+		},
+		{
+			name:    "unordered schemas",
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV12, schemaV11},
+			wantErr: true,
+		},
+		{
+			name: "same effective date",
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				schemaV11,
+				{Version: lokiv1beta1.ObjectStorageSchemaV12, EffectiveDate: schemaV11.EffectiveDate},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid effective date",
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				{Version: lokiv1beta1.ObjectStorageSchemaV11, EffectiveDate: "2020-13-01"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			err := ValidateSchemas(tst.schemas)
+			if tst.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateSchemaUpdate(t *testing.T) {
+	now := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	table := []struct {
+		name    string
+		applied []lokiv1beta1.ObjectStorageSchema
+		schemas []lokiv1beta1.ObjectStorageSchema
+		wantErr bool
+	}{
+		{
+			name:    "new stack",
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12},
+		},
+		{
+			name:    "add future schema",
+			applied: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12},
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12, schemaV13},
+		},
+		{
+			name:    "remove future schema",
+			applied: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12, schemaV13},
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12},
+		},
+		{
+			name:    "change schema in effect",
+			applied: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12},
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				schemaV11,
+				{Version: lokiv1beta1.ObjectStorageSchemaV13, EffectiveDate: schemaV12.EffectiveDate},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "remove schema in effect",
+			applied: []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV12},
+			schemas: []lokiv1beta1.ObjectStorageSchema{schemaV11},
+			wantErr: true,
+		},
+		{
+			name:    "add schema effective today",
+			applied: []lokiv1beta1.ObjectStorageSchema{schemaV11},
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				schemaV11,
+				{Version: lokiv1beta1.ObjectStorageSchemaV12, EffectiveDate: "2023-01-15"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			err := ValidateSchemaUpdate(tst.schemas, tst.applied, now)
+			if tst.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchemaConfig(t *testing.T) {
+	spec := lokiv1beta1.LokiStackSpec{}
+	require.Equal(t, config.Schemas{
+		{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"},
+	}, schemaConfig(spec))
+
+	spec.Storage.Schemas = []lokiv1beta1.ObjectStorageSchema{schemaV11, schemaV13}
+	require.Equal(t, config.Schemas{
+		{From: "2020-10-01", Version: "v11", IndexStore: "boltdb-shipper"},
+		{From: "2024-04-01", Version: "v13", IndexStore: "tsdb"},
+	}, schemaConfig(spec))
+}
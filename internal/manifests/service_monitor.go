@@ -13,7 +13,7 @@ import (
 
 // BuildServiceMonitors builds the service monitors
 func BuildServiceMonitors(opts Options) []client.Object {
-	return []client.Object{
+	objs := []client.Object{
 		NewDistributorServiceMonitor(opts),
 		NewIngesterServiceMonitor(opts),
 		NewQuerierServiceMonitor(opts),
@@ -22,6 +22,12 @@ func BuildServiceMonitors(opts Options) []client.Object {
 		NewIndexGatewayServiceMonitor(opts),
 		NewGatewayServiceMonitor(opts),
 	}
+
+	if rulerEnabled(opts.Stack) {
+		objs = append(objs, NewRulerServiceMonitor(opts))
+	}
+
+	return objs
 }
 
 // NewDistributorServiceMonitor creates a k8s service monitor for the distributor component
@@ -109,6 +115,17 @@ func NewGatewayServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
 	return sm
 }
 
+// NewRulerServiceMonitor creates a k8s service monitor for the ruler component
+func NewRulerServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
+	l := ComponentLabels(LabelRulerComponent, opts.Name)
+
+	serviceMonitorName := serviceMonitorName(RulerName(opts.Name))
+	serviceName := serviceNameRulerHTTP(opts.Name)
+	lokiEndpoint := serviceMonitorEndpoint(lokiHTTPPortName, serviceName, opts.Namespace, opts.Flags.EnableTLSServiceMonitorConfig)
+
+	return newServiceMonitor(opts.Namespace, serviceMonitorName, l, lokiEndpoint)
+}
+
 func newServiceMonitor(namespace, serviceMonitorName string, labels labels.Set, endpoint monitoringv1.Endpoint) *monitoringv1.ServiceMonitor {
 	return &monitoringv1.ServiceMonitor{
 		TypeMeta: metav1.TypeMeta{
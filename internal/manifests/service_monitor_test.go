@@ -55,6 +55,9 @@ func TestServiceMonitorMatchLabels(t *testing.T) {
 				IndexGateway: &lokiv1beta1.LokiComponentSpec{
 					Replicas: 1,
 				},
+				Ruler: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 1,
+				},
 			},
 		},
 	}
@@ -88,9 +91,14 @@ func TestServiceMonitorMatchLabels(t *testing.T) {
 			Service:        NewIndexGatewayHTTPService(opt),
 			ServiceMonitor: NewIndexGatewayServiceMonitor(opt),
 		},
+		{
+			Service:        NewRulerHTTPService(opt),
+			ServiceMonitor: NewRulerServiceMonitor(opt),
+		},
 	}
 
 	for _, tst := range table {
+		tst := tst
 		testName := fmt.Sprintf("%s_%s", tst.Service.GetName(), tst.ServiceMonitor.GetName())
 		t.Run(testName, func(t *testing.T) {
 			t.Parallel()
@@ -0,0 +1,96 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests/internal"
+	"sigs.k8s.io/yaml"
+)
+
+// SizeProfile defines a custom LokiStack size with the same structure as the
+// size tables, i.e. the default spec, the resource requirements per component
+// and optionally the managed memcached caches. The managed memcached caches
+// of the LokiStack size are used if omitted.
+type SizeProfile struct {
+	Stack     lokiv1beta1.LokiStackSpec   `json:"stack"`
+	Resources internal.ComponentResources `json:"resources"`
+	Memcached *internal.MemcachedSize     `json:"memcached,omitempty"`
+}
+
+// ParseSizeProfile parses and validates a size profile from its YAML representation.
+func ParseSizeProfile(data string) (*SizeProfile, error) {
+	var p SizeProfile
+	if err := yaml.UnmarshalStrict([]byte(data), &p); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse size profile")
+	}
+
+	if err := ValidateSizeProfile(p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// ValidateSizeProfile validates that a size profile defines the replicas, the limits
+// and the persistent volume claim sizes of all components, as the size tables do.
+func ValidateSizeProfile(p SizeProfile) error {
+	spec := p.Stack
+	if spec.ReplicationFactor < 1 {
+		return kverrors.New("replication factor must be at least 1")
+	}
+
+	if spec.Limits == nil || spec.Limits.Global == nil ||
+		spec.Limits.Global.IngestionLimits == nil || spec.Limits.Global.QueryLimits == nil {
+		return kverrors.New("global ingestion and query limits are required")
+	}
+
+	t := spec.Template
+	if t == nil {
+		return kverrors.New("replicas of all components are required")
+	}
+
+	components := []struct {
+		name string
+		spec *lokiv1beta1.LokiComponentSpec
+	}{
+		{name: LabelCompactorComponent, spec: t.Compactor},
+		{name: LabelDistributorComponent, spec: t.Distributor},
+		{name: LabelIngesterComponent, spec: t.Ingester},
+		{name: LabelQuerierComponent, spec: t.Querier},
+		{name: LabelQueryFrontendComponent, spec: t.QueryFrontend},
+		{name: LabelGatewayComponent, spec: t.Gateway},
+		{name: LabelIndexGatewayComponent, spec: t.IndexGateway},
+		{name: LabelRulerComponent, spec: t.Ruler},
+	}
+	for _, c := range components {
+		if c.spec == nil || c.spec.Replicas < 1 {
+			return kverrors.New(fmt.Sprintf("replicas of the %s are required", c.name), "component", c.name)
+		}
+	}
+
+	claims := []struct {
+		name string
+		res  internal.ResourceRequirements
+	}{
+		{name: LabelCompactorComponent, res: p.Resources.Compactor},
+		{name: LabelIngesterComponent, res: p.Resources.Ingester},
+		{name: LabelIndexGatewayComponent, res: p.Resources.IndexGateway},
+		{name: LabelRulerComponent, res: p.Resources.Ruler},
+		{name: "wal", res: p.Resources.WALStorage},
+	}
+	for _, c := range claims {
+		if c.res.PVCSize.IsZero() {
+			return kverrors.New(fmt.Sprintf("persistent volume claim size of the %s is required", c.name), "component", c.name)
+		}
+	}
+
+	if m := p.Memcached; m != nil {
+		if m.Replicas < 1 || m.ChunksMemoryMB < 1 || m.IndexQueriesMemoryMB < 1 || m.ResultsMemoryMB < 1 {
+			return kverrors.New("memcached replicas and memory of all caches are required")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package manifests
+
+import (
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const customSizeProfile = `
+stack:
+  replicationFactor: 2
+  limits:
+    global:
+      ingestion:
+        ingestionRate: 30
+        ingestionBurstSize: 60
+        maxGlobalStreamsPerTenant: 25000
+      queries:
+        maxEntriesLimitPerQuery: 5000
+        maxChunksPerQuery: 2000000
+        maxQuerySeries: 500
+  template:
+    compactor:
+      replicas: 1
+    distributor:
+      replicas: 5
+    ingester:
+      replicas: 7
+    querier:
+      replicas: 5
+    queryFrontend:
+      replicas: 2
+    gateway:
+      replicas: 2
+    indexGateway:
+      replicas: 2
+    ruler:
+      replicas: 2
+resources:
+  compactor:
+    limits:
+      cpu: "2"
+      memory: 4Gi
+    pvcSize: 10Gi
+  ingester:
+    limits:
+      cpu: "6"
+      memory: 30Gi
+    pvcSize: 50Gi
+  indexGateway:
+    pvcSize: 50Gi
+  ruler:
+    pvcSize: 10Gi
+  walStorage:
+    pvcSize: 200Gi
+memcached:
+  replicas: 4
+  chunksMemoryMB: 4096
+  indexQueriesMemoryMB: 1024
+  resultsMemoryMB: 1024
+`
+
+func TestParseSizeProfile(t *testing.T) {
+	p, err := ParseSizeProfile(customSizeProfile)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), p.Stack.ReplicationFactor)
+	require.Equal(t, int32(7), p.Stack.Template.Ingester.Replicas)
+	require.Equal(t, resource.MustParse("50Gi"), p.Resources.Ingester.PVCSize)
+	require.Equal(t, int32(4), p.Memcached.Replicas)
+}
+
+func TestParseSizeProfile_WhenInvalid_ReturnError(t *testing.T) {
+	table := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "unknown field",
+			data: customSizeProfile + "unknown: true\n",
+		},
+		{
+			name: "missing component replicas",
+			data: `
+stack:
+  replicationFactor: 1
+  limits:
+    global:
+      ingestion: {}
+      queries: {}
+  template:
+    compactor:
+      replicas: 1
+`,
+		},
+		{
+			name: "missing replication factor",
+			data: `
+resources:
+  walStorage:
+    pvcSize: 10Gi
+`,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			_, err := ParseSizeProfile(tst.data)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestApplyDefaultSettings_WithSizeProfile(t *testing.T) {
+	p, err := ParseSizeProfile(customSizeProfile)
+	require.NoError(t, err)
+
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size:        lokiv1beta1.SizeOneXSmall,
+			SizeProfile: "custom",
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Querier: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 3,
+				},
+			},
+		},
+		SizeProfile: p,
+	}
+
+	err = ApplyDefaultSettings(&opts)
+	require.NoError(t, err)
+
+	// Require profile defaults with user overrides
+	require.Equal(t, int32(2), opts.Stack.ReplicationFactor)
+	require.Equal(t, int32(7), opts.Stack.Template.Ingester.Replicas)
+	require.Equal(t, int32(3), opts.Stack.Template.Querier.Replicas)
+	require.Equal(t, int32(30), opts.Stack.Limits.Global.IngestionLimits.IngestionRate)
+
+	// Require profile resources
+	require.Equal(t, resource.MustParse("30Gi"), opts.ResourceRequirements.Ingester.Limits[corev1.ResourceMemory])
+	require.Equal(t, resource.MustParse("50Gi"), opts.ResourceRequirements.Ingester.PVCSize)
+	require.Equal(t, resource.MustParse("200Gi"), opts.ResourceRequirements.WALStorage.PVCSize)
+}
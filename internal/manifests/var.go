@@ -2,6 +2,8 @@ package manifests
 
 import (
 	"fmt"
+	"net"
+	"strconv"
 
 	"github.com/ViaQ/loki-operator/internal/manifests/openshift"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -31,7 +33,20 @@ const (
 	EnvRelatedImageGateway = "RELATED_IMAGE_GATEWAY"
 
 	// DefaultContainerImage declares the default fallback for loki image.
-	DefaultContainerImage = "docker.io/grafana/loki:2.2.1"
+	DefaultContainerImage = "docker.io/grafana/loki:2.9.4"
+
+	// EnvRelatedImageMemcached is the environment variable to fetch the memcached image pullspec.
+	EnvRelatedImageMemcached = "RELATED_IMAGE_MEMCACHED"
+
+	// DefaultMemcachedImage declares the default image for the managed memcached caches.
+	DefaultMemcachedImage = "docker.io/library/memcached:1.6.12-alpine"
+
+	// EnvOperatorNamespace is the environment variable to fetch the namespace of the operator.
+	EnvOperatorNamespace = "OPERATOR_NAMESPACE"
+
+	// SizeProfilesConfigMapName is the name of the ConfigMap in the operator namespace
+	// defining the custom size profiles by name.
+	SizeProfilesConfigMapName = "loki-operator-size-profiles"
 
 	// DefaultLokiStackGatewayImage declares the default image for lokiStack-gateway.
 	DefaultLokiStackGatewayImage = "quay.io/observatorium/api:latest"
@@ -41,6 +56,13 @@ const (
 	// BearerTokenFile declares the path for bearer token file for service monitors.
 	BearerTokenFile string = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 
+	// AnnotationIngesterDraining marks an ingester pod that is flushed
+	// before its statefulset is scaled down, set to "drained" once flushed.
+	AnnotationIngesterDraining string = "loki.grafana.com/draining"
+
+	// labelNamespace is the label for the namespace of the stack owning a cluster-scoped object.
+	labelNamespace string = "loki.grafana.com/namespace"
+
 	// labelJobComponent is a ServiceMonitor.Spec.JobLabel.
 	labelJobComponent string = "loki.grafana.com/component"
 
@@ -58,6 +80,10 @@ const (
 	LabelIndexGatewayComponent string = "index-gateway"
 	// LabelGatewayComponent is the label value for the lokiStack-gateway component
 	LabelGatewayComponent string = "lokistack-gateway"
+	// LabelRulerComponent is the label value for the lokiStack-ruler component
+	LabelRulerComponent string = "ruler"
+	// LabelMemcachedComponent is the label value for the managed memcached caches
+	LabelMemcachedComponent string = "memcached"
 )
 
 var (
@@ -94,6 +120,20 @@ func ComponentLabels(component, stackName string) labels.Set {
 	})
 }
 
+// ManagedLabels returns the labels shared by all objects generated for a LokiStack.
+func ManagedLabels(stackName string) labels.Set {
+	return commonLabels(stackName)
+}
+
+// ClusterScopedLabels returns the labels of the cluster-scoped objects generated
+// for a LokiStack. As stacks of the same name in different namespaces share the
+// managed labels, they include the namespace of the stack.
+func ClusterScopedLabels(stackName, namespace string) labels.Set {
+	return labels.Merge(ManagedLabels(stackName), map[string]string{
+		labelNamespace: namespace,
+	})
+}
+
 // GossipLabels is the list of labels that should be assigned to components using the gossip ring
 func GossipLabels() map[string]string {
 	return map[string]string{
@@ -101,6 +141,13 @@ func GossipLabels() map[string]string {
 	}
 }
 
+// ZoneLabels is the list of labels that should be assigned to ingesters of a single zone
+func ZoneLabels(zone string) map[string]string {
+	return map[string]string{
+		"loki.grafana.com/zone": zone,
+	}
+}
+
 // CompactorName is the name of the compactor statefulset
 func CompactorName(stackName string) string {
 	return fmt.Sprintf("loki-compactor-%s", stackName)
@@ -116,6 +163,11 @@ func IngesterName(stackName string) string {
 	return fmt.Sprintf("loki-ingester-%s", stackName)
 }
 
+// IngesterZoneName is the name of the ingester statefulset for a single zone
+func IngesterZoneName(stackName, zone string) string {
+	return fmt.Sprintf("%s-%s", IngesterName(stackName), zone)
+}
+
 // QuerierName is the name of the querier deployment
 func QuerierName(stackName string) string {
 	return fmt.Sprintf("loki-querier-%s", stackName)
@@ -136,6 +188,27 @@ func GatewayName(stackName string) string {
 	return fmt.Sprintf("lokistack-gateway-%s", stackName)
 }
 
+// RulerName is the name of the ruler statefulset
+func RulerName(stackName string) string {
+	return fmt.Sprintf("loki-ruler-%s", stackName)
+}
+
+// MemcachedName is the name of the managed memcached statefulset of a cache
+func MemcachedName(stackName, cache string) string {
+	return fmt.Sprintf("loki-memcached-%s-%s", cache, stackName)
+}
+
+// SharedStorageName is the name of the persistent volume claim shared by all
+// components for the filesystem storage type
+func SharedStorageName(stackName string) string {
+	return fmt.Sprintf("loki-shared-storage-%s", stackName)
+}
+
+// RulesConfigMapName is the name of the alerting and recording rules configmap
+func RulesConfigMapName(stackName string) string {
+	return fmt.Sprintf("loki-rules-%s", stackName)
+}
+
 func serviceNameQuerierHTTP(stackName string) string {
 	return fmt.Sprintf("loki-querier-http-%s", stackName)
 }
@@ -144,6 +217,10 @@ func serviceNameQuerierGRPC(stackName string) string {
 	return fmt.Sprintf("loki-querier-grpc-%s", stackName)
 }
 
+func serviceNameMemcached(stackName, cache string) string {
+	return fmt.Sprintf("loki-memcached-%s-%s", cache, stackName)
+}
+
 func serviceNameIngesterGRPC(stackName string) string {
 	return fmt.Sprintf("loki-ingester-grpc-%s", stackName)
 }
@@ -184,6 +261,14 @@ func serviceNameIndexGatewayGRPC(stackName string) string {
 	return fmt.Sprintf("loki-index-gateway-grpc-%s", stackName)
 }
 
+func serviceNameRulerHTTP(stackName string) string {
+	return fmt.Sprintf("loki-ruler-http-%s", stackName)
+}
+
+func serviceNameRulerGRPC(stackName string) string {
+	return fmt.Sprintf("loki-ruler-grpc-%s", stackName)
+}
+
 func serviceNameGatewayHTTP(stackName string) string {
 	return fmt.Sprintf("lokistack-gateway-http-%s", stackName)
 }
@@ -196,6 +281,16 @@ func signingServiceSecretName(serviceName string) string {
 	return fmt.Sprintf("%s-metrics", serviceName)
 }
 
+// HTTPAddress returns the address of the Loki HTTP endpoint on the given host.
+func HTTPAddress(host string) string {
+	return net.JoinHostPort(host, strconv.Itoa(httpPort))
+}
+
+// RingHTTPAddress returns the address of the HTTP endpoint serving the ingester ring status.
+func RingHTTPAddress(stackName, namespace string) string {
+	return HTTPAddress(fqdn(serviceNameDistributorHTTP(stackName), namespace))
+}
+
 func fqdn(serviceName, namespace string) string {
 	return fmt.Sprintf("%s.%s.svc.cluster.local", serviceName, namespace)
 }
@@ -0,0 +1,125 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	envAvailabilityZone = "INSTANCE_AVAILABILITY_ZONE"
+
+	// maxStatefulSetNameLength is the maximum length of a statefulset name
+	// keeping the controller-revision-hash label of its pods within the
+	// label value limit of 63 characters.
+	maxStatefulSetNameLength = 52
+)
+
+func zoneAwarenessEnabled(spec lokiv1beta1.LokiStackSpec) bool {
+	return spec.Replication != nil && len(spec.Replication.Zones) > 0
+}
+
+// ValidateZone validates that the zone value can be used in the names of
+// the ingester statefulset of the zone, its pods and the zone labels.
+func ValidateZone(stackName, zone string) error {
+	if len(validation.IsDNS1123Label(zone)) > 0 {
+		return kverrors.New("zone must be a lowercase RFC 1123 label", "zone", zone)
+	}
+
+	if name := IngesterZoneName(stackName, zone); len(name) > maxStatefulSetNameLength {
+		return kverrors.New("zone is too long for the ingester statefulset name",
+			"zone", zone,
+			"name", name,
+			"max_length", maxStatefulSetNameLength,
+		)
+	}
+
+	return nil
+}
+
+// ZoneReplicas distributes the total number of ingester replicas evenly
+// across the zones. The first zones receive the remainder, if any.
+func ZoneReplicas(total int32, zones, index int) int32 {
+	replicas := total / int32(zones)
+	if int32(index) < total%int32(zones) {
+		replicas++
+	}
+	return replicas
+}
+
+// newIngesterZoneStatefulSets creates one ingester statefulset per zone
+// pinned to the nodes of the zone.
+func newIngesterZoneStatefulSets(opts Options) []*appsv1.StatefulSet {
+	zones := opts.Stack.Replication.Zones
+
+	var sets []*appsv1.StatefulSet
+	for i, zone := range zones {
+		sts := NewIngesterStatefulSet(opts)
+		configureIngesterZone(sts, opts.Name, zone)
+		sts.Spec.Replicas = pointer.Int32Ptr(ZoneReplicas(opts.Stack.Template.Ingester.Replicas, len(zones), i))
+		sets = append(sets, sts)
+	}
+	return sets
+}
+
+func configureIngesterZone(sts *appsv1.StatefulSet, stackName string, zone lokiv1beta1.ZoneSpec) {
+	zl := ZoneLabels(zone.Value)
+
+	sts.Name = IngesterZoneName(stackName, zone.Value)
+	sts.Labels = labels.Merge(sts.Labels, zl)
+	sts.Spec.Selector.MatchLabels = labels.Merge(sts.Spec.Selector.MatchLabels, zl)
+	sts.Spec.Template.Name = fmt.Sprintf("%s-%s", sts.Spec.Template.Name, zone.Value)
+	sts.Spec.Template.Labels = labels.Merge(sts.Spec.Template.Labels, zl)
+
+	podSpec := &sts.Spec.Template.Spec
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, corev1.EnvVar{
+			Name:  envAvailabilityZone,
+			Value: zone.Value,
+		})
+	}
+
+	topologyKey := zone.TopologyKey
+	if topologyKey == "" {
+		topologyKey = topologyKeyZone
+	}
+	requireNodeLabel(podSpec, topologyKey, zone.Value)
+}
+
+// requireNodeLabel restricts scheduling to nodes carrying the given label
+// in addition to any required node affinity already present.
+func requireNodeLabel(podSpec *corev1.PodSpec, key, value string) {
+	// The affinity may be shared with the LokiStack spec, thus never modify it in place.
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	} else {
+		podSpec.Affinity = podSpec.Affinity.DeepCopy()
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	na := podSpec.Affinity.NodeAffinity
+	if na.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		na.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	ns := na.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(ns.NodeSelectorTerms) == 0 {
+		ns.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+
+	req := corev1.NodeSelectorRequirement{
+		Key:      key,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{value},
+	}
+	// Node selector terms are ORed, thus the requirement is added to each of them.
+	for i := range ns.NodeSelectorTerms {
+		ns.NodeSelectorTerms[i].MatchExpressions = append(ns.NodeSelectorTerms[i].MatchExpressions, req)
+	}
+}
@@ -0,0 +1,132 @@
+package manifests
+
+import (
+	"strings"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestZoneReplicas(t *testing.T) {
+	require.Equal(t, []int32{3, 2, 2}, []int32{
+		ZoneReplicas(7, 3, 0),
+		ZoneReplicas(7, 3, 1),
+		ZoneReplicas(7, 3, 2),
+	})
+	require.Equal(t, []int32{1, 1}, []int32{
+		ZoneReplicas(2, 2, 0),
+		ZoneReplicas(2, 2, 1),
+	})
+}
+
+func TestBuildIngester_WithZones_RendersStatefulSetPerZone(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1beta1.LokiStackSpec{
+			Size:              lokiv1beta1.SizeOneXSmall,
+			ReplicationFactor: 2,
+			Replication: &lokiv1beta1.ReplicationSpec{
+				Zones: []lokiv1beta1.ZoneSpec{
+					{Value: "zone-a"},
+					{TopologyKey: "example.com/rack", Value: "rack-b"},
+				},
+			},
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{
+					Replicas: 3,
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{
+										MatchExpressions: []corev1.NodeSelectorRequirement{
+											{Key: "node-role", Operator: corev1.NodeSelectorOpExists},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	objs, err := BuildIngester(opts)
+	require.NoError(t, err)
+
+	var sets []*appsv1.StatefulSet
+	for _, o := range objs {
+		if sts, ok := o.(*appsv1.StatefulSet); ok {
+			sets = append(sets, sts)
+		}
+	}
+	require.Len(t, sets, 2)
+
+	type want struct {
+		name     string
+		zone     string
+		key      string
+		replicas int32
+	}
+	for i, w := range []want{
+		{name: "loki-ingester-abcd-zone-a", zone: "zone-a", key: topologyKeyZone, replicas: 2},
+		{name: "loki-ingester-abcd-rack-b", zone: "rack-b", key: "example.com/rack", replicas: 1},
+	} {
+		sts := sets[i]
+		require.Equal(t, w.name, sts.Name)
+		require.Equal(t, w.replicas, *sts.Spec.Replicas)
+		require.Equal(t, w.zone, sts.Spec.Selector.MatchLabels["loki.grafana.com/zone"])
+		require.Equal(t, w.zone, sts.Spec.Template.Labels["loki.grafana.com/zone"])
+		require.Contains(t, sts.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: envAvailabilityZone, Value: w.zone})
+
+		terms := sts.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		require.Len(t, terms, 1)
+		require.Equal(t, []corev1.NodeSelectorRequirement{
+			{Key: "node-role", Operator: corev1.NodeSelectorOpExists},
+			{Key: w.key, Operator: corev1.NodeSelectorOpIn, Values: []string{w.zone}},
+		}, terms[0].MatchExpressions)
+	}
+
+	// The affinity of the LokiStack spec is left untouched
+	userTerms := opts.Stack.Template.Ingester.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, userTerms[0].MatchExpressions, 1)
+}
+
+func TestValidateReplication_WithZones(t *testing.T) {
+	spec := func(rf, replicas int32, zones ...string) lokiv1beta1.LokiStackSpec {
+		s := lokiv1beta1.LokiStackSpec{
+			ReplicationFactor: rf,
+			Replication:       &lokiv1beta1.ReplicationSpec{},
+			Template: &lokiv1beta1.LokiTemplateSpec{
+				Ingester: &lokiv1beta1.LokiComponentSpec{Replicas: replicas},
+			},
+		}
+		for _, z := range zones {
+			s.Replication.Zones = append(s.Replication.Zones, lokiv1beta1.ZoneSpec{Value: z})
+		}
+		return s
+	}
+
+	require.NoError(t, ValidateReplication("my-stack", spec(2, 3, "a", "b", "c")))
+	require.Error(t, ValidateReplication("my-stack", spec(3, 3, "a", "b")))
+	require.Error(t, ValidateReplication("my-stack", spec(2, 2, "a", "b", "c")))
+	require.Error(t, ValidateReplication("my-stack", spec(1, 2, "a", "a")))
+	require.Error(t, ValidateReplication("my-stack", spec(1, 2, "a", "Zone_B")))
+}
+
+func TestValidateZone(t *testing.T) {
+	require.NoError(t, ValidateZone("my-stack", "eu-central-1a"))
+	require.Error(t, ValidateZone("my-stack", "eu.central"))
+	require.Error(t, ValidateZone("my-stack", "Zone-A"))
+	require.Error(t, ValidateZone("my-stack", ""))
+
+	// loki-ingester-my-stack- is 23 characters long
+	require.NoError(t, ValidateZone("my-stack", strings.Repeat("a", 29)))
+	require.Error(t, ValidateZone("my-stack", strings.Repeat("a", 30)))
+}
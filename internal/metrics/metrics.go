@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"reflect"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -51,6 +52,11 @@ var (
 		},
 		[]string{"size", "stack_id"},
 	)
+
+	// stackProfiles tracks the size profile last collected per stack
+	// to delete its series on reset or when the profile changes.
+	stackProfiles = map[string]string{}
+	profilesMu    sync.Mutex
 )
 
 // RegisterMetricCollectors registers the prometheus collectors with the k8 default metrics
@@ -67,12 +73,40 @@ func RegisterMetricCollectors() {
 	}
 }
 
-// Collect takes metrics based on the spec
-func Collect(spec *lokiv1beta1.LokiStackSpec, stackName string) {
+// Collect takes metrics based on the spec. Stacks using a size profile are
+// reported with the profile name as size and compared against the profile
+// defaults, as their size is ignored.
+func Collect(spec *lokiv1beta1.LokiStackSpec, stackName string, profile *manifests.SizeProfile) {
+	size := string(spec.Size)
 	defaultSpec := manifests.DefaultLokiStackSpec(spec.Size)
-	sizes := []lokiv1beta1.LokiStackSizeType{lokiv1beta1.SizeOneXSmall, lokiv1beta1.SizeOneXMedium}
+	if spec.SizeProfile != "" && profile != nil {
+		size = spec.SizeProfile
+		defaultSpec = profile.Stack.DeepCopy()
+	}
 
-	for _, size := range sizes {
+	sizes := []string{
+		string(lokiv1beta1.SizeOneXSmall),
+		string(lokiv1beta1.SizeOneXMedium),
+		string(lokiv1beta1.SizeOneXLarge),
+		string(lokiv1beta1.SizeTwoXMedium),
+	}
+	if spec.SizeProfile != "" {
+		sizes = append(sizes, spec.SizeProfile)
+	}
+
+	// Delete the series of a size profile not used anymore.
+	profilesMu.Lock()
+	if previous, ok := stackProfiles[stackName]; ok && previous != spec.SizeProfile {
+		deleteSeries(previous, stackName)
+	}
+	if spec.SizeProfile != "" {
+		stackProfiles[stackName] = spec.SizeProfile
+	} else {
+		delete(stackProfiles, stackName)
+	}
+	profilesMu.Unlock()
+
+	for _, label := range sizes {
 		var (
 			globalRate                float64 = 0
 			tenantRate                float64 = 0
@@ -81,7 +115,7 @@ func Collect(spec *lokiv1beta1.LokiStackSpec, stackName string) {
 			isUsingCustomGlobalLimits         = false
 		)
 
-		if spec.Size == size {
+		if label == size {
 			isUsingSize = true
 
 			if !reflect.DeepEqual(spec.Limits.Global, defaultSpec.Limits.Global) {
@@ -98,39 +132,75 @@ func Collect(spec *lokiv1beta1.LokiStackSpec, stackName string) {
 			}
 		}
 
-		setDeploymentMetric(size, stackName, isUsingSize)
-		setUserDefinedLimitsMetric(size, stackName, labelGlobal, isUsingCustomGlobalLimits)
-		setUserDefinedLimitsMetric(size, stackName, labelTenant, isUsingTenantLimits)
-		setGlobalStreamLimitMetric(size, stackName, globalRate)
-		setAverageTenantStreamLimitMetric(size, stackName, tenantRate)
+		setDeploymentMetric(label, stackName, isUsingSize)
+		setUserDefinedLimitsMetric(label, stackName, labelGlobal, isUsingCustomGlobalLimits)
+		setUserDefinedLimitsMetric(label, stackName, labelTenant, isUsingTenantLimits)
+		setGlobalStreamLimitMetric(label, stackName, globalRate)
+		setAverageTenantStreamLimitMetric(label, stackName, tenantRate)
+	}
+}
+
+// Reset deletes all metrics series collected for the stack
+func Reset(stackName string) {
+	sizes := []lokiv1beta1.LokiStackSizeType{lokiv1beta1.SizeOneXSmall, lokiv1beta1.SizeOneXMedium, lokiv1beta1.SizeOneXLarge, lokiv1beta1.SizeTwoXMedium}
+
+	for _, size := range sizes {
+		deleteSeries(string(size), stackName)
+	}
+
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	if profile, ok := stackProfiles[stackName]; ok {
+		deleteSeries(profile, stackName)
+		delete(stackProfiles, stackName)
+	}
+}
+
+func deleteSeries(size, stackName string) {
+	labels := prometheus.Labels{
+		"size":     size,
+		"stack_id": stackName,
+	}
+
+	deploymentMetric.Delete(labels)
+	globalStreamLimitMetric.Delete(labels)
+	averageTenantStreamLimitMetric.Delete(labels)
+
+	for _, limitType := range []UserDefinedLimitsType{labelGlobal, labelTenant} {
+		userDefinedLimitsMetric.Delete(prometheus.Labels{
+			"size":     size,
+			"stack_id": stackName,
+			"type":     string(limitType),
+		})
 	}
 }
 
-func setDeploymentMetric(size lokiv1beta1.LokiStackSizeType, identifier string, active bool) {
+func setDeploymentMetric(size, identifier string, active bool) {
 	deploymentMetric.With(prometheus.Labels{
-		"size":     string(size),
+		"size":     size,
 		"stack_id": identifier,
 	}).Set(boolValue(active))
 }
 
-func setUserDefinedLimitsMetric(size lokiv1beta1.LokiStackSizeType, identifier string, limitType UserDefinedLimitsType, active bool) {
+func setUserDefinedLimitsMetric(size, identifier string, limitType UserDefinedLimitsType, active bool) {
 	userDefinedLimitsMetric.With(prometheus.Labels{
-		"size":     string(size),
+		"size":     size,
 		"stack_id": identifier,
 		"type":     string(limitType),
 	}).Set(boolValue(active))
 }
 
-func setGlobalStreamLimitMetric(size lokiv1beta1.LokiStackSizeType, identifier string, rate float64) {
+func setGlobalStreamLimitMetric(size, identifier string, rate float64) {
 	globalStreamLimitMetric.With(prometheus.Labels{
-		"size":     string(size),
+		"size":     size,
 		"stack_id": identifier,
 	}).Set(rate)
 }
 
-func setAverageTenantStreamLimitMetric(size lokiv1beta1.LokiStackSizeType, identifier string, rate float64) {
+func setAverageTenantStreamLimitMetric(size, identifier string, rate float64) {
 	averageTenantStreamLimitMetric.With(prometheus.Labels{
-		"size":     string(size),
+		"size":     size,
 		"stack_id": identifier,
 	}).Set(rate)
 }
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+)
+
+func TestCollect_WhenSizeProfile_ReportProfileAsSize(t *testing.T) {
+	profile := &manifests.SizeProfile{Stack: *manifests.DefaultLokiStackSpec(lokiv1beta1.SizeOneXMedium)}
+	profile.Stack.Template.Ingester.Replicas = 4
+
+	spec := profile.Stack.DeepCopy()
+	spec.Size = lokiv1beta1.SizeOneXSmall
+	spec.SizeProfile = "custom"
+
+	Collect(spec, "my-stack", profile)
+	defer Reset("my-stack")
+
+	labels := func(size string) prometheus.Labels {
+		return prometheus.Labels{"size": size, "stack_id": "my-stack"}
+	}
+	require.Equal(t, float64(1), testutil.ToFloat64(deploymentMetric.With(labels("custom"))))
+	require.Equal(t, float64(0), testutil.ToFloat64(deploymentMetric.With(labels("1x.small"))))
+
+	// Limits compared against the profile defaults
+	global := prometheus.Labels{"size": "custom", "stack_id": "my-stack", "type": string(labelGlobal)}
+	require.Equal(t, float64(0), testutil.ToFloat64(userDefinedLimitsMetric.With(global)))
+}
+
+func TestReset_DeleteSizeProfileSeries(t *testing.T) {
+	profile := &manifests.SizeProfile{Stack: *manifests.DefaultLokiStackSpec(lokiv1beta1.SizeOneXMedium)}
+
+	spec := profile.Stack.DeepCopy()
+	spec.SizeProfile = "custom"
+
+	Collect(spec, "other-stack", profile)
+	require.Equal(t, 5, testutil.CollectAndCount(deploymentMetric))
+
+	Reset("other-stack")
+	require.Zero(t, testutil.CollectAndCount(deploymentMetric))
+}
@@ -46,6 +46,11 @@ func SetComponentsStatus(ctx context.Context, k k8s.Client, req ctrl.Request) er
 		return kverrors.Wrap(err, "failed lookup LokiStack component pods status", "name", manifests.LabelQueryFrontendComponent)
 	}
 
+	s.Status.Components.IndexGateway, err = appendPodStatus(ctx, k, manifests.LabelIndexGatewayComponent, s.Name, s.Namespace)
+	if err != nil {
+		return kverrors.Wrap(err, "failed lookup LokiStack component pods status", "name", manifests.LabelIndexGatewayComponent)
+	}
+
 	s.Status.Components.Ingester, err = appendPodStatus(ctx, k, manifests.LabelIngesterComponent, s.Name, s.Namespace)
 	if err != nil {
 		return kverrors.Wrap(err, "failed lookup LokiStack component pods status", "name", manifests.LabelIngesterComponent)
@@ -55,6 +60,17 @@ func SetComponentsStatus(ctx context.Context, k k8s.Client, req ctrl.Request) er
 	if err != nil {
 		return kverrors.Wrap(err, "failed lookup LokiStack component pods status", "name", manifests.LabelGatewayComponent)
 	}
+
+	s.Status.Components.Ruler, err = appendPodStatus(ctx, k, manifests.LabelRulerComponent, s.Name, s.Namespace)
+	if err != nil {
+		return kverrors.Wrap(err, "failed lookup LokiStack component pods status", "name", manifests.LabelRulerComponent)
+	}
+
+	s.Status.Zones, err = zonesStatus(ctx, k, &s)
+	if err != nil {
+		return kverrors.Wrap(err, "failed lookup LokiStack ingester zones status")
+	}
+
 	return k.Status().Update(ctx, &s, &client.UpdateOptions{})
 }
 
@@ -9,11 +9,13 @@ import (
 	"github.com/ViaQ/loki-operator/internal/status"
 	"github.com/stretchr/testify/require"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -151,6 +153,7 @@ func TestSetComponentsStatus_WhenPodListExisting_SetPodStatusMap(t *testing.T) {
 	sw.UpdateStub = func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
 		stack := obj.(*lokiv1beta1.LokiStack)
 		require.Equal(t, expected, stack.Status.Components.Compactor)
+		require.Equal(t, expected, stack.Status.Components.Ruler)
 		return nil
 	}
 
@@ -160,3 +163,79 @@ func TestSetComponentsStatus_WhenPodListExisting_SetPodStatusMap(t *testing.T) {
 	require.NotZero(t, k.StatusCallCount())
 	require.NotZero(t, sw.UpdateCallCount())
 }
+
+func TestSetComponentsStatus_WhenZonesEnabled_SetZonesStatus(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	s := lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Replication: &lokiv1beta1.ReplicationSpec{
+				Zones: []lokiv1beta1.ZoneSpec{
+					{Value: "zone-a"},
+					{Value: "zone-b"},
+					{Value: "zone-c"},
+				},
+			},
+		},
+	}
+
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, &s)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+	}
+
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := l.(*appsv1.StatefulSetList); !ok {
+			return nil
+		}
+		sts := appsv1.StatefulSetList{
+			Items: []appsv1.StatefulSet{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "loki-ingester-my-stack-zone-a"},
+					Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32Ptr(2)},
+					Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "loki-ingester-my-stack-zone-b"},
+					Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32Ptr(2)},
+					Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+				},
+			},
+		}
+		k.SetClientObjectList(l, &sts)
+		return nil
+	}
+
+	expected := []lokiv1beta1.ZoneStatus{
+		{Name: "zone-a", Replicas: 2, ReadyReplicas: 2, Ready: true},
+		{Name: "zone-b", Replicas: 2, ReadyReplicas: 1, Ready: false},
+		{Name: "zone-c"},
+	}
+
+	sw.UpdateStub = func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+		stack := obj.(*lokiv1beta1.LokiStack)
+		require.Equal(t, expected, stack.Status.Zones)
+		return nil
+	}
+
+	err := status.SetComponentsStatus(context.TODO(), k, r)
+	require.NoError(t, err)
+	require.NotZero(t, sw.UpdateCallCount())
+}
@@ -0,0 +1,156 @@
+package status
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// componentHealth describes the health of a LokiStack component
+// derived from the status of its workload and pods.
+type componentHealth int
+
+const (
+	healthReady componentHealth = iota
+	healthPending
+	healthFailed
+)
+
+// failedWaitingReasons are container waiting reasons that will not resolve without intervention.
+var failedWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+type componentSpec struct {
+	name string
+	// optional components are only evaluated when their workload exists.
+	optional bool
+}
+
+var lokiStackComponents = []componentSpec{
+	{name: manifests.LabelCompactorComponent},
+	{name: manifests.LabelDistributorComponent},
+	{name: manifests.LabelIngesterComponent},
+	{name: manifests.LabelQuerierComponent},
+	{name: manifests.LabelQueryFrontendComponent},
+	{name: manifests.LabelIndexGatewayComponent},
+	{name: manifests.LabelGatewayComponent, optional: true},
+	{name: manifests.LabelRulerComponent, optional: true},
+	{name: manifests.LabelMemcachedComponent, optional: true},
+}
+
+// stackHealth returns the worst health of all LokiStack components.
+func stackHealth(ctx context.Context, k k8s.Client, stack, ns string) (componentHealth, error) {
+	health := healthReady
+	for _, c := range lokiStackComponents {
+		h, err := workloadHealth(ctx, k, c, stack, ns)
+		if err != nil {
+			return healthReady, err
+		}
+
+		if h > health {
+			health = h
+		}
+	}
+	return health, nil
+}
+
+func workloadHealth(ctx context.Context, k k8s.Client, c componentSpec, stack, ns string) (componentHealth, error) {
+	opts := []client.ListOption{
+		client.MatchingLabels(manifests.ComponentLabels(c.name, stack)),
+		client.InNamespace(ns),
+	}
+
+	var deployments appsv1.DeploymentList
+	if err := k.List(ctx, &deployments, opts...); err != nil {
+		return healthReady, kverrors.Wrap(err, "failed to list deployments for LokiStack component", "name", stack, "component", c.name)
+	}
+
+	var statefulSets appsv1.StatefulSetList
+	if err := k.List(ctx, &statefulSets, opts...); err != nil {
+		return healthReady, kverrors.Wrap(err, "failed to list statefulsets for LokiStack component", "name", stack, "component", c.name)
+	}
+
+	if len(deployments.Items)+len(statefulSets.Items) == 0 {
+		if c.optional {
+			return healthReady, nil
+		}
+		return healthPending, nil
+	}
+
+	var pods corev1.PodList
+	if err := k.List(ctx, &pods, opts...); err != nil {
+		return healthReady, kverrors.Wrap(err, "failed to list pods for LokiStack component", "name", stack, "component", c.name)
+	}
+
+	if podsFailed(pods.Items) {
+		return healthFailed, nil
+	}
+
+	for _, d := range deployments.Items {
+		if !deploymentReady(d) {
+			return healthPending, nil
+		}
+	}
+
+	for _, s := range statefulSets.Items {
+		if !statefulSetReady(s) {
+			return healthPending, nil
+		}
+	}
+
+	return healthReady, nil
+}
+
+func podsFailed(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodUnknown {
+			return true
+		}
+
+		statuses := append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && failedWaitingReasons[cs.State.Waiting.Reason] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func deploymentReady(d appsv1.Deployment) bool {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+
+	st := d.Status
+	return st.ObservedGeneration >= d.Generation &&
+		st.UpdatedReplicas >= desired &&
+		st.ReadyReplicas >= desired &&
+		st.Replicas == st.UpdatedReplicas
+}
+
+func statefulSetReady(s appsv1.StatefulSet) bool {
+	desired := int32(1)
+	if s.Spec.Replicas != nil {
+		desired = *s.Spec.Replicas
+	}
+
+	st := s.Status
+	rolledOut := st.UpdateRevision == "" || st.CurrentRevision == st.UpdateRevision
+	return st.ObservedGeneration >= s.Generation &&
+		rolledOut &&
+		st.ReadyReplicas >= desired
+}
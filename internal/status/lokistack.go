@@ -108,6 +108,19 @@ func SetFailedCondition(ctx context.Context, k k8s.Client, req ctrl.Request) err
 // SetPendingCondition updates or appends the condition Pending to the lokistack status conditions.
 // In addition it resets all other Status conditions to false.
 func SetPendingCondition(ctx context.Context, k k8s.Client, req ctrl.Request) error {
+	return setPendingCondition(ctx, k, req,
+		"Some LokiStack components pending on dependendies",
+		lokiv1beta1.ReasonPendingComponents,
+	)
+}
+
+// SetScalingDownCondition updates or appends the condition Pending to the lokistack status conditions
+// reporting the progress of an ingester scale-down. In addition it resets all other Status conditions to false.
+func SetScalingDownCondition(ctx context.Context, k k8s.Client, req ctrl.Request, msg string) error {
+	return setPendingCondition(ctx, k, req, msg, lokiv1beta1.ReasonScalingDownIngesters)
+}
+
+func setPendingCondition(ctx context.Context, k k8s.Client, req ctrl.Request, msg string, reason lokiv1beta1.LokiStackConditionReason) error {
 	var s lokiv1beta1.LokiStack
 	if err := k.Get(ctx, req.NamespacedName, &s); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -116,18 +129,18 @@ func SetPendingCondition(ctx context.Context, k k8s.Client, req ctrl.Request) er
 		return kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
 	}
 
-	for _, cond := range s.Status.Conditions {
-		if cond.Type == string(lokiv1beta1.ConditionPending) && cond.Status == metav1.ConditionTrue {
-			return nil
-		}
-	}
-
 	pending := metav1.Condition{
 		Type:               string(lokiv1beta1.ConditionPending),
 		Status:             metav1.ConditionTrue,
 		LastTransitionTime: metav1.Now(),
-		Message:            "Some LokiStack components pending on dependendies",
-		Reason:             string(lokiv1beta1.ReasonPendingComponents),
+		Message:            msg,
+		Reason:             string(reason),
+	}
+
+	for _, cond := range s.Status.Conditions {
+		if cond.Type == string(lokiv1beta1.ConditionPending) && cond.Status == metav1.ConditionTrue && samePending(cond, pending) {
+			return nil
+		}
 	}
 
 	index := -1
@@ -151,6 +164,16 @@ func SetPendingCondition(ctx context.Context, k k8s.Client, req ctrl.Request) er
 	return k.Status().Update(ctx, &s, &client.UpdateOptions{})
 }
 
+// samePending returns true if the existing pending condition reports the same
+// as the new one. Only an ingester scale-down reports its individual progress.
+func samePending(existing, pending metav1.Condition) bool {
+	scalingDown := string(lokiv1beta1.ReasonScalingDownIngesters)
+	if existing.Reason == scalingDown || pending.Reason == scalingDown {
+		return existing.Reason == pending.Reason && existing.Message == pending.Message
+	}
+	return true
+}
+
 // SetDegradedCondition appends the condition Degraded to the lokistack status conditions.
 func SetDegradedCondition(ctx context.Context, k k8s.Client, req ctrl.Request, msg string, reason lokiv1beta1.LokiStackConditionReason) error {
 	var s lokiv1beta1.LokiStack
@@ -2,19 +2,26 @@ package status
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
 	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
 	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Refresh executes an aggregate update of the LokiStack Status struct, i.e.
 // - It recreates the Status.Components pod status map per component.
-// - It sets the appropriate Status.Condition to true that matches the pod status maps.
+// - It sets the appropriate Status.Condition to true that matches the component workloads health
+// or the progress of an ingester scale-down.
 func Refresh(ctx context.Context, k k8s.Client, req ctrl.Request) error {
 	if err := SetComponentsStatus(ctx, k, req); err != nil {
 		return err
@@ -28,34 +35,95 @@ func Refresh(ctx context.Context, k k8s.Client, req ctrl.Request) error {
 		return kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
 	}
 
-	cs := s.Status.Components
+	return setHealthCondition(ctx, k, req, &s)
+}
+
+// RefreshComponents updates the Status.Components pod status map and the ingester
+// zones on workload status changes. The condition matching the component workloads
+// health is updated only while the stack is not degraded, as the Degraded condition
+// is reported and cleared by the reconciliation of the stack only.
+func RefreshComponents(ctx context.Context, k k8s.Client, req ctrl.Request) error {
+	if err := SetComponentsStatus(ctx, k, req); err != nil {
+		return err
+	}
+
+	var s lokiv1beta1.LokiStack
+	if err := k.Get(ctx, req.NamespacedName, &s); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
+	}
 
-	// Check for failed pods first
-	failed := len(cs.Compactor[corev1.PodFailed]) +
-		len(cs.Distributor[corev1.PodFailed]) +
-		len(cs.Ingester[corev1.PodFailed]) +
-		len(cs.Querier[corev1.PodFailed]) +
-		len(cs.QueryFrontend[corev1.PodFailed])
+	if meta.IsStatusConditionTrue(s.Status.Conditions, string(lokiv1beta1.ConditionDegraded)) {
+		return nil
+	}
 
-	unknown := len(cs.Compactor[corev1.PodUnknown]) +
-		len(cs.Distributor[corev1.PodUnknown]) +
-		len(cs.Ingester[corev1.PodUnknown]) +
-		len(cs.Querier[corev1.PodUnknown]) +
-		len(cs.QueryFrontend[corev1.PodUnknown])
+	return setHealthCondition(ctx, k, req, &s)
+}
 
-	if failed != 0 || unknown != 0 {
-		return SetFailedCondition(ctx, k, req)
+func setHealthCondition(ctx context.Context, k k8s.Client, req ctrl.Request, s *lokiv1beta1.LokiStack) error {
+	health, err := stackHealth(ctx, k, s.Name, s.Namespace)
+	if err != nil {
+		return err
 	}
 
-	// Check for pending pods
-	pending := len(cs.Compactor[corev1.PodPending]) +
-		len(cs.Distributor[corev1.PodPending]) +
-		len(cs.Ingester[corev1.PodPending]) +
-		len(cs.Querier[corev1.PodPending]) +
-		len(cs.QueryFrontend[corev1.PodPending])
+	draining, err := drainingIngesters(ctx, k, s.Name, s.Namespace)
+	if err != nil {
+		return err
+	}
 
-	if pending != 0 {
+	switch {
+	case health == healthFailed:
+		return SetFailedCondition(ctx, k, req)
+	case len(draining) > 0:
+		msg := fmt.Sprintf("Flushing ingesters before scale-down: %s", strings.Join(draining, ", "))
+		return SetScalingDownCondition(ctx, k, req, msg)
+	case health == healthPending:
 		return SetPendingCondition(ctx, k, req)
+	default:
+		return SetReadyCondition(ctx, k, req)
 	}
-	return SetReadyCondition(ctx, k, req)
+}
+
+// SetObservedGeneration records the generation of the lokistack
+// reconciled successfully.
+func SetObservedGeneration(ctx context.Context, k k8s.Client, req ctrl.Request, generation int64) error {
+	var s lokiv1beta1.LokiStack
+	if err := k.Get(ctx, req.NamespacedName, &s); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to lookup lokistack", "name", req.NamespacedName)
+	}
+
+	if s.Status.ObservedGeneration == generation {
+		return nil
+	}
+
+	s.Status.ObservedGeneration = generation
+	return k.Status().Update(ctx, &s, &client.UpdateOptions{})
+}
+
+// drainingIngesters returns the sorted names of the ingester pods
+// marked as draining by an ingester scale-down.
+func drainingIngesters(ctx context.Context, k k8s.Client, stack, ns string) ([]string, error) {
+	var pods corev1.PodList
+	opts := []client.ListOption{
+		client.MatchingLabels(manifests.ComponentLabels(manifests.LabelIngesterComponent, stack)),
+		client.InNamespace(ns),
+	}
+	if err := k.List(ctx, &pods, opts...); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list ingester pods", "name", stack)
+	}
+
+	var names []string
+	for _, pod := range pods.Items {
+		if _, ok := pod.Annotations[manifests.AnnotationIngesterDraining]; ok {
+			names = append(names, pod.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
 }
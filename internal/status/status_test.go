@@ -0,0 +1,339 @@
+package status_test
+
+import (
+	"context"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"github.com/ViaQ/loki-operator/internal/status"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRefresh_SetsConditionFromWorkloadHealth(t *testing.T) {
+	readyDeployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "deployment",
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32Ptr(2),
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			ReadyReplicas:      2,
+		},
+	}
+
+	notReadyDeployment := *readyDeployment.DeepCopy()
+	notReadyDeployment.Status.ReadyReplicas = 1
+
+	notObservedDeployment := *readyDeployment.DeepCopy()
+	notObservedDeployment.Status.ObservedGeneration = 1
+
+	runningPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-a",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+
+	crashingPod := *runningPod.DeepCopy()
+	crashingPod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "loki",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{
+					Reason: "CrashLoopBackOff",
+				},
+			},
+		},
+	}
+
+	type test struct {
+		name        string
+		deployments []appsv1.Deployment
+		pods        []corev1.Pod
+		want        lokiv1beta1.LokiStackConditionType
+	}
+	table := []test{
+		{
+			name:        "all workloads ready",
+			deployments: []appsv1.Deployment{readyDeployment},
+			pods:        []corev1.Pod{runningPod},
+			want:        lokiv1beta1.ConditionReady,
+		},
+		{
+			name:        "ready replicas below desired",
+			deployments: []appsv1.Deployment{notReadyDeployment},
+			pods:        []corev1.Pod{runningPod},
+			want:        lokiv1beta1.ConditionPending,
+		},
+		{
+			name:        "rollout not observed yet",
+			deployments: []appsv1.Deployment{notObservedDeployment},
+			pods:        []corev1.Pod{runningPod},
+			want:        lokiv1beta1.ConditionPending,
+		},
+		{
+			name:        "running pod in crash loop",
+			deployments: []appsv1.Deployment{readyDeployment},
+			pods:        []corev1.Pod{crashingPod},
+			want:        lokiv1beta1.ConditionFailed,
+		},
+		{
+			name: "missing workloads",
+			want: lokiv1beta1.ConditionPending,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			sw := &k8sfakes.FakeStatusWriter{}
+			k := &k8sfakes.FakeClient{}
+
+			k.StatusStub = func() client.StatusWriter { return sw }
+
+			s := lokiv1beta1.LokiStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "my-stack",
+					Namespace:  "some-ns",
+					Generation: 3,
+				},
+			}
+
+			r := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+			}
+
+			k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+				if r.Name == name.Name && r.Namespace == name.Namespace {
+					k.SetClientObject(object, &s)
+					return nil
+				}
+				return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+			}
+
+			k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+				switch l.(type) {
+				case *appsv1.DeploymentList:
+					k.SetClientObjectList(l, &appsv1.DeploymentList{Items: tst.deployments})
+				case *corev1.PodList:
+					k.SetClientObjectList(l, &corev1.PodList{Items: tst.pods})
+				}
+				return nil
+			}
+
+			err := status.Refresh(context.TODO(), k, r)
+			require.NoError(t, err)
+
+			// First update stores the components status, the last one the condition
+			require.Equal(t, 2, sw.UpdateCallCount())
+
+			_, obj, _ := sw.UpdateArgsForCall(0)
+			stack := obj.(*lokiv1beta1.LokiStack)
+			require.Zero(t, stack.Status.ObservedGeneration)
+
+			_, obj, _ = sw.UpdateArgsForCall(1)
+			stack = obj.(*lokiv1beta1.LokiStack)
+			require.Len(t, stack.Status.Conditions, 1)
+			require.Equal(t, string(tst.want), stack.Status.Conditions[0].Type)
+			require.Equal(t, metav1.ConditionTrue, stack.Status.Conditions[0].Status)
+		})
+	}
+}
+
+func TestRefresh_WhenIngestersDraining_SetScalingDownCondition(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	s := lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, &s)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+	}
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "loki-ingester-my-stack-2",
+				Annotations: map[string]string{manifests.AnnotationIngesterDraining: "true"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "loki-ingester-my-stack-1",
+				Annotations: map[string]string{manifests.AnnotationIngesterDraining: "true"},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "loki-ingester-my-stack-0",
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := l.(*corev1.PodList); ok {
+			k.SetClientObjectList(l, &corev1.PodList{Items: pods})
+		}
+		return nil
+	}
+
+	err := status.Refresh(context.TODO(), k, r)
+	require.NoError(t, err)
+	require.Equal(t, 2, sw.UpdateCallCount())
+
+	_, obj, _ := sw.UpdateArgsForCall(1)
+	stack := obj.(*lokiv1beta1.LokiStack)
+	require.Len(t, stack.Status.Conditions, 1)
+
+	cond := stack.Status.Conditions[0]
+	require.Equal(t, string(lokiv1beta1.ConditionPending), cond.Type)
+	require.Equal(t, string(lokiv1beta1.ReasonScalingDownIngesters), cond.Reason)
+	require.Equal(t, "Flushing ingesters before scale-down: loki-ingester-my-stack-1, loki-ingester-my-stack-2", cond.Message)
+}
+
+func TestRefreshComponents_WhenDegraded_KeepConditions(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	s := lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "my-stack",
+			Namespace:  "some-ns",
+			Generation: 3,
+		},
+		Status: lokiv1beta1.LokiStackStatus{
+			ObservedGeneration: 2,
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(lokiv1beta1.ConditionDegraded),
+					Status: metav1.ConditionTrue,
+					Reason: string(lokiv1beta1.ReasonUnsupportedStorageChange),
+				},
+			},
+		},
+	}
+
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object) error {
+		if r.Name == name.Name && r.Namespace == name.Namespace {
+			k.SetClientObject(object, &s)
+			return nil
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, "something wasn't found")
+	}
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "loki-ingester-my-stack-0"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+
+	k.ListStub = func(_ context.Context, l client.ObjectList, _ ...client.ListOption) error {
+		if _, ok := l.(*corev1.PodList); ok {
+			k.SetClientObjectList(l, &corev1.PodList{Items: pods})
+		}
+		return nil
+	}
+
+	err := status.RefreshComponents(context.TODO(), k, r)
+	require.NoError(t, err)
+
+	// Update the components status only
+	require.Equal(t, 1, sw.UpdateCallCount())
+
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	stack := obj.(*lokiv1beta1.LokiStack)
+	require.Equal(t, s.Status.Conditions, stack.Status.Conditions)
+	require.Equal(t, int64(2), stack.Status.ObservedGeneration)
+	require.NotEmpty(t, stack.Status.Components.Ingester)
+}
+
+func TestSetObservedGeneration(t *testing.T) {
+	sw := &k8sfakes.FakeStatusWriter{}
+	k := &k8sfakes.FakeClient{}
+
+	k.StatusStub = func() client.StatusWriter { return sw }
+
+	s := lokiv1beta1.LokiStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "my-stack",
+			Namespace:  "some-ns",
+			Generation: 4,
+		},
+		Status: lokiv1beta1.LokiStackStatus{
+			ObservedGeneration: 2,
+		},
+	}
+
+	r := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+	}
+
+	k.GetStub = func(_ context.Context, _ types.NamespacedName, object client.Object) error {
+		k.SetClientObject(object, &s)
+		return nil
+	}
+
+	err := status.SetObservedGeneration(context.TODO(), k, r, 3)
+	require.NoError(t, err)
+	require.Equal(t, 1, sw.UpdateCallCount())
+
+	_, obj, _ := sw.UpdateArgsForCall(0)
+	stack := obj.(*lokiv1beta1.LokiStack)
+	require.Equal(t, int64(3), stack.Status.ObservedGeneration)
+}
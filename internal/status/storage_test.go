@@ -0,0 +1,92 @@
+package status_test
+
+import (
+	"context"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s/k8sfakes"
+	"github.com/ViaQ/loki-operator/internal/status"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSetStorageSchemas(t *testing.T) {
+	v11 := lokiv1beta1.ObjectStorageSchema{Version: lokiv1beta1.ObjectStorageSchemaV11, EffectiveDate: "2020-10-01"}
+	v13 := lokiv1beta1.ObjectStorageSchema{Version: lokiv1beta1.ObjectStorageSchemaV13, EffectiveDate: "2024-04-01"}
+
+	table := []struct {
+		name       string
+		applied    []lokiv1beta1.ObjectStorageSchema
+		schemas    []lokiv1beta1.ObjectStorageSchema
+		wantUpdate bool
+	}{
+		{
+			name:       "first apply",
+			schemas:    []lokiv1beta1.ObjectStorageSchema{v11},
+			wantUpdate: true,
+		},
+		{
+			name:       "added schema",
+			applied:    []lokiv1beta1.ObjectStorageSchema{v11},
+			schemas:    []lokiv1beta1.ObjectStorageSchema{v11, v13},
+			wantUpdate: true,
+		},
+		{
+			name:    "unchanged schemas",
+			applied: []lokiv1beta1.ObjectStorageSchema{v11, v13},
+			schemas: []lokiv1beta1.ObjectStorageSchema{v11, v13},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			sw := &k8sfakes.FakeStatusWriter{}
+			k := &k8sfakes.FakeClient{}
+
+			k.StatusStub = func() client.StatusWriter { return sw }
+
+			s := lokiv1beta1.LokiStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+				Status: lokiv1beta1.LokiStackStatus{
+					Storage: lokiv1beta1.LokiStackStorageStatus{
+						Schemas: tst.applied,
+					},
+				},
+			}
+
+			r := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+			}
+
+			k.GetStub = func(_ context.Context, _ types.NamespacedName, object client.Object) error {
+				k.SetClientObject(object, &s)
+				return nil
+			}
+
+			err := status.SetStorageSchemas(context.TODO(), k, r, tst.schemas)
+			require.NoError(t, err)
+
+			if !tst.wantUpdate {
+				require.Zero(t, sw.UpdateCallCount())
+				return
+			}
+
+			require.Equal(t, 1, sw.UpdateCallCount())
+			_, obj, _ := sw.UpdateArgsForCall(0)
+			stack := obj.(*lokiv1beta1.LokiStack)
+			require.Equal(t, tst.schemas, stack.Status.Storage.Schemas)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package status
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/kverrors"
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/external/k8s"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// zonesStatus reports the ingester readiness per replication zone. A zone
+// without an ingester statefulset is reported as not ready.
+func zonesStatus(ctx context.Context, k k8s.Client, s *lokiv1beta1.LokiStack) ([]lokiv1beta1.ZoneStatus, error) {
+	if s.Spec.Replication == nil || len(s.Spec.Replication.Zones) == 0 {
+		return nil, nil
+	}
+
+	var statefulSets appsv1.StatefulSetList
+	opts := []client.ListOption{
+		client.MatchingLabels(manifests.ComponentLabels(manifests.LabelIngesterComponent, s.Name)),
+		client.InNamespace(s.Namespace),
+	}
+	if err := k.List(ctx, &statefulSets, opts...); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list ingester statefulsets", "name", s.Name)
+	}
+
+	byName := map[string]appsv1.StatefulSet{}
+	for _, sts := range statefulSets.Items {
+		byName[sts.Name] = sts
+	}
+
+	var zones []lokiv1beta1.ZoneStatus
+	for _, z := range s.Spec.Replication.Zones {
+		zs := lokiv1beta1.ZoneStatus{Name: z.Value}
+
+		if sts, ok := byName[manifests.IngesterZoneName(s.Name, z.Value)]; ok {
+			if sts.Spec.Replicas != nil {
+				zs.Replicas = *sts.Spec.Replicas
+			}
+			zs.ReadyReplicas = sts.Status.ReadyReplicas
+			zs.Ready = statefulSetReady(sts)
+		}
+
+		zones = append(zones, zs)
+	}
+
+	return zones, nil
+}
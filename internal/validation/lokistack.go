@@ -0,0 +1,400 @@
+package validation
+
+import (
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/ViaQ/loki-operator/internal/manifests"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateLokiStack validates the LokiStack spec for supported sizes,
+// replication, limits and tenants configuration.
+func ValidateLokiStack(stack *lokiv1beta1.LokiStack) field.ErrorList {
+	var errs field.ErrorList
+
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateSize(stack.Spec, specPath)...)
+	errs = append(errs, validateReplicationFactor(stack.Spec, specPath)...)
+	errs = append(errs, validateReplicationZones(stack.Name, stack.Spec, specPath)...)
+	errs = append(errs, validateStorage(stack.Spec.Storage, specPath.Child("storage"))...)
+	errs = append(errs, validateSchemas(stack.Spec.Storage.Schemas, specPath.Child("storage", "schemas"))...)
+	errs = append(errs, validateAutoscaling(stack.Spec.Template, specPath.Child("template"))...)
+	errs = append(errs, validateCaching(stack.Spec.Caching, specPath.Child("caching"))...)
+	errs = append(errs, validateLimits(stack.Spec.Limits, specPath.Child("limits"))...)
+	errs = append(errs, validateTenants(stack, specPath.Child("tenants"))...)
+
+	return errs
+}
+
+// ValidateLokiStackUpdate validates the changes of a LokiStack spec that
+// depend on the previous spec, i.e. that storage schemas in effect at the
+// given time and the access mode of the shared storage remain unchanged.
+func ValidateLokiStackUpdate(old, stack *lokiv1beta1.LokiStack, now time.Time) field.ErrorList {
+	var errs field.ErrorList
+
+	specPath := field.NewPath("spec")
+	schemas := manifests.Schemas(stack.Spec)
+
+	if err := manifests.ValidateSchemaUpdate(schemas, manifests.Schemas(old.Spec), now); err != nil {
+		errs = append(errs, field.Invalid(specPath.Child("storage", "schemas"), schemas, err.Error()))
+	}
+
+	errs = append(errs, validateFilesystemUpdate(old.Spec.Storage, stack.Spec.Storage, specPath.Child("storage", "filesystem"))...)
+
+	return errs
+}
+
+// validateFilesystemUpdate forbids changing the access mode of the shared
+// storage claim as it is immutable once the claim is created.
+func validateFilesystemUpdate(old, s lokiv1beta1.ObjectStorageSpec, p *field.Path) field.ErrorList {
+	if old.Type != lokiv1beta1.ObjectStorageSecretFilesystem || s.Type != lokiv1beta1.ObjectStorageSecretFilesystem {
+		return nil
+	}
+
+	oldMode, mode := filesystemAccessMode(old.Filesystem), filesystemAccessMode(s.Filesystem)
+	if oldMode != mode {
+		return field.ErrorList{
+			field.Forbidden(p.Child("accessMode"), "access mode of the shared storage cannot be changed"),
+		}
+	}
+
+	return nil
+}
+
+func filesystemAccessMode(fs *lokiv1beta1.ObjectStorageFilesystemSpec) lokiv1beta1.ObjectStorageFilesystemAccessMode {
+	if fs == nil || fs.AccessMode == "" {
+		return lokiv1beta1.ObjectStorageFilesystemReadWriteMany
+	}
+	return fs.AccessMode
+}
+
+func validateSize(spec lokiv1beta1.LokiStackSpec, p *field.Path) field.ErrorList {
+	if !manifests.IsSupportedSize(spec.Size) {
+		return field.ErrorList{
+			field.NotSupported(p.Child("size"), spec.Size, []string{
+				string(lokiv1beta1.SizeOneXExtraSmall),
+				string(lokiv1beta1.SizeOneXSmall),
+				string(lokiv1beta1.SizeOneXMedium),
+				string(lokiv1beta1.SizeOneXLarge),
+				string(lokiv1beta1.SizeTwoXMedium),
+			}),
+		}
+	}
+
+	return nil
+}
+
+func validateReplicationFactor(spec lokiv1beta1.LokiStackSpec, p *field.Path) field.ErrorList {
+	replicas, ok := ingesterReplicas(spec)
+	if !ok {
+		return nil
+	}
+
+	if spec.ReplicationFactor > replicas {
+		return field.ErrorList{
+			field.Invalid(p.Child("replicationFactor"), spec.ReplicationFactor,
+				"replication factor must not exceed the number of ingester replicas"),
+		}
+	}
+
+	return nil
+}
+
+func validateReplicationZones(stackName string, spec lokiv1beta1.LokiStackSpec, p *field.Path) field.ErrorList {
+	if spec.Replication == nil || len(spec.Replication.Zones) == 0 {
+		return nil
+	}
+
+	var errs field.ErrorList
+
+	zonesPath := p.Child("replication", "zones")
+	zones := spec.Replication.Zones
+	seen := map[string]bool{}
+	for i, z := range zones {
+		if seen[z.Value] {
+			errs = append(errs, field.Duplicate(zonesPath.Index(i).Child("value"), z.Value))
+		}
+		seen[z.Value] = true
+
+		if err := manifests.ValidateZone(stackName, z.Value); err != nil {
+			errs = append(errs, field.Invalid(zonesPath.Index(i).Child("value"), z.Value, err.Error()))
+		}
+	}
+
+	if spec.ReplicationFactor > int32(len(zones)) {
+		errs = append(errs, field.Invalid(p.Child("replicationFactor"), spec.ReplicationFactor,
+			"replication factor must not exceed the number of replication zones"))
+	}
+
+	replicas, ok := ingesterReplicas(spec)
+	if !ok {
+		return errs
+	}
+
+	if replicas < int32(len(zones)) {
+		errs = append(errs, field.Invalid(zonesPath, len(zones),
+			"number of replication zones must not exceed the number of ingester replicas"))
+	}
+
+	return errs
+}
+
+// ingesterReplicas returns the number of ingester replicas of the spec or
+// false if it is known only when reconciling, i.e. for unsupported sizes
+// and size profiles without explicit ingester replicas.
+func ingesterReplicas(spec lokiv1beta1.LokiStackSpec) (int32, bool) {
+	if spec.Template != nil && spec.Template.Ingester != nil && spec.Template.Ingester.Replicas > 0 {
+		return spec.Template.Ingester.Replicas, true
+	}
+
+	if spec.SizeProfile != "" || !manifests.IsSupportedSize(spec.Size) {
+		// Unsupported sizes are reported by validateSize
+		return 0, false
+	}
+
+	return manifests.DefaultLokiStackSpec(spec.Size).Template.Ingester.Replicas, true
+}
+
+func validateStorage(s lokiv1beta1.ObjectStorageSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if s.Filesystem != nil && s.Type != lokiv1beta1.ObjectStorageSecretFilesystem {
+		errs = append(errs, field.Forbidden(p.Child("filesystem"), "filesystem storage applies only to the filesystem type"))
+	}
+
+	if s.Prefix != "" && s.Type == lokiv1beta1.ObjectStorageSecretFilesystem {
+		errs = append(errs, field.Forbidden(p.Child("prefix"), "prefix does not apply to the filesystem type"))
+	}
+
+	if s.TLS != nil && s.Type == lokiv1beta1.ObjectStorageSecretFilesystem {
+		errs = append(errs, field.Forbidden(p.Child("tls"), "tls does not apply to the filesystem type"))
+	}
+
+	if s.Type == "" || s.Type == lokiv1beta1.ObjectStorageSecretS3 {
+		return append(errs, validateSSE(s.SSE, p.Child("sse"))...)
+	}
+
+	if s.SSE != nil {
+		errs = append(errs, field.Forbidden(p.Child("sse"), "server-side encryption applies only to the s3 type"))
+	}
+	if s.AddressingStyle == lokiv1beta1.ObjectStorageAddressingVirtualHosted {
+		errs = append(errs, field.Forbidden(p.Child("addressingStyle"), "virtual-hosted addressing applies only to the s3 type"))
+	}
+	if s.Insecure {
+		errs = append(errs, field.Forbidden(p.Child("insecure"), "insecure applies only to the s3 type"))
+	}
+
+	return errs
+}
+
+func validateSSE(sse *lokiv1beta1.ObjectStorageSSESpec, p *field.Path) field.ErrorList {
+	if sse == nil {
+		return nil
+	}
+
+	switch {
+	case sse.Type == lokiv1beta1.ObjectStorageSSEKMS && sse.KMSKeyID == "":
+		return field.ErrorList{
+			field.Required(p.Child("kmsKeyID"), "KMS key ID is required for SSE-KMS"),
+		}
+	case sse.Type == lokiv1beta1.ObjectStorageSSES3 && sse.KMSKeyID != "":
+		return field.ErrorList{
+			field.Forbidden(p.Child("kmsKeyID"), "KMS key ID applies only to SSE-KMS"),
+		}
+	}
+
+	return nil
+}
+
+func validateSchemas(schemas []lokiv1beta1.ObjectStorageSchema, p *field.Path) field.ErrorList {
+	if err := manifests.ValidateSchemas(schemas); err != nil {
+		return field.ErrorList{
+			field.Invalid(p, schemas, err.Error()),
+		}
+	}
+
+	return nil
+}
+
+func validateAutoscaling(tpl *lokiv1beta1.LokiTemplateSpec, p *field.Path) field.ErrorList {
+	if tpl == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+
+	components := []struct {
+		name        string
+		spec        *lokiv1beta1.LokiComponentSpec
+		autoscaling bool
+	}{
+		{name: "compactor", spec: tpl.Compactor},
+		{name: "distributor", spec: tpl.Distributor, autoscaling: true},
+		{name: "ingester", spec: tpl.Ingester},
+		{name: "querier", spec: tpl.Querier, autoscaling: true},
+		{name: "queryFrontend", spec: tpl.QueryFrontend, autoscaling: true},
+		{name: "gateway", spec: tpl.Gateway, autoscaling: true},
+		{name: "indexGateway", spec: tpl.IndexGateway},
+		{name: "ruler", spec: tpl.Ruler},
+	}
+
+	for _, c := range components {
+		if c.spec == nil || c.spec.Autoscaling == nil {
+			continue
+		}
+
+		asPath := p.Child(c.name, "autoscaling")
+		if !c.autoscaling {
+			errs = append(errs, field.Forbidden(asPath, "autoscaling is supported only for the distributor, querier, query frontend and gateway"))
+			continue
+		}
+
+		as := c.spec.Autoscaling
+		if as.MaxReplicas < as.MinReplicas {
+			errs = append(errs, field.Invalid(asPath.Child("maxReplicas"), as.MaxReplicas,
+				"max replicas must not be less than min replicas"))
+		}
+	}
+
+	return errs
+}
+
+func validateCaching(c *lokiv1beta1.CachingSpec, p *field.Path) field.ErrorList {
+	if c == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+
+	caches := []struct {
+		name string
+		spec *lokiv1beta1.ExternalCacheSpec
+	}{
+		{name: "chunks", spec: c.Chunks},
+		{name: "indexQueries", spec: c.IndexQueries},
+		{name: "results", spec: c.Results},
+	}
+
+	var external int
+	for _, cache := range caches {
+		if cache.spec == nil {
+			continue
+		}
+
+		external++
+		if c.Mode != lokiv1beta1.CachingModeExternal {
+			errs = append(errs, field.Forbidden(p.Child(cache.name), "external caches apply only to the External mode"))
+			continue
+		}
+
+		if cache.spec.Endpoint == "" {
+			errs = append(errs, field.Required(p.Child(cache.name, "endpoint"), "endpoint of the external cache is required"))
+		}
+	}
+
+	if c.Mode == lokiv1beta1.CachingModeExternal && external == 0 {
+		errs = append(errs, field.Required(p, "at least one external cache is required for the External mode"))
+	}
+
+	return errs
+}
+
+func validateLimits(limits *lokiv1beta1.LimitsSpec, p *field.Path) field.ErrorList {
+	if limits == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+
+	if limits.Global != nil {
+		errs = append(errs, validateLimitsTemplate(limits.Global, p.Child("global"))...)
+	}
+
+	for tenant, l := range limits.Tenants {
+		l := l
+		errs = append(errs, validateLimitsTemplate(&l, p.Child("tenants").Key(tenant))...)
+	}
+
+	return errs
+}
+
+func validateLimitsTemplate(l *lokiv1beta1.LimitsTemplateSpec, p *field.Path) field.ErrorList {
+	type limit struct {
+		path  *field.Path
+		value int32
+	}
+
+	var values []limit
+	if i := l.IngestionLimits; i != nil {
+		ip := p.Child("ingestion")
+		values = append(values,
+			limit{ip.Child("ingestionRate"), i.IngestionRate},
+			limit{ip.Child("ingestionBurstSize"), i.IngestionBurstSize},
+			limit{ip.Child("maxLabelNameLength"), i.MaxLabelNameLength},
+			limit{ip.Child("maxLabelValueLength"), i.MaxLabelValueLength},
+			limit{ip.Child("maxLabelNamesPerSeries"), i.MaxLabelNamesPerSeries},
+			limit{ip.Child("maxGlobalStreamsPerTenant"), i.MaxGlobalStreamsPerTenant},
+			limit{ip.Child("maxLineSize"), i.MaxLineSize},
+		)
+	}
+
+	if q := l.QueryLimits; q != nil {
+		qp := p.Child("queries")
+		values = append(values,
+			limit{qp.Child("maxEntriesLimitPerQuery"), q.MaxEntriesLimitPerQuery},
+			limit{qp.Child("maxChunksPerQuery"), q.MaxChunksPerQuery},
+			limit{qp.Child("maxQuerySeries"), q.MaxQuerySeries},
+		)
+	}
+
+	var errs field.ErrorList
+	for _, v := range values {
+		if v.value < 0 {
+			errs = append(errs, field.Invalid(v.path, v.value, "must not be negative"))
+		}
+	}
+
+	return errs
+}
+
+func validateTenants(stack *lokiv1beta1.LokiStack, p *field.Path) field.ErrorList {
+	tenants := stack.Spec.Tenants
+	if tenants == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+
+	if err := ValidateModes(*stack); err != nil {
+		errs = append(errs, field.Invalid(p.Child("mode"), tenants.Mode, err.Error()))
+	}
+
+	names := map[string]bool{}
+	for i, a := range tenants.Authentication {
+		if names[a.TenantName] {
+			errs = append(errs, field.Duplicate(p.Child("authentication").Index(i).Child("tenantName"), a.TenantName))
+		}
+		names[a.TenantName] = true
+	}
+
+	if tenants.Authorization == nil {
+		return errs
+	}
+
+	roles := map[string]bool{}
+	for _, r := range tenants.Authorization.Roles {
+		roles[r.Name] = true
+	}
+
+	for i, rb := range tenants.Authorization.RoleBindings {
+		for j, r := range rb.Roles {
+			if !roles[r] {
+				errs = append(errs, field.NotFound(p.Child("authorization", "roleBindings").Index(i).Child("roles").Index(j), r))
+			}
+		}
+	}
+
+	return errs
+}
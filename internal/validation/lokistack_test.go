@@ -0,0 +1,450 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateLokiStack(t *testing.T) {
+	type test struct {
+		name     string
+		spec     lokiv1beta1.LokiStackSpec
+		wantErrs []string
+	}
+	table := []test{
+		{
+			name: "valid spec",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+			},
+		},
+		{
+			name: "unsupported size",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              "3x.huge",
+				ReplicationFactor: 1,
+			},
+			wantErrs: []string{"spec.size"},
+		},
+		{
+			name: "replication factor exceeds default ingester replicas",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 2,
+			},
+			wantErrs: []string{"spec.replicationFactor"},
+		},
+		{
+			name: "replication factor within template ingester replicas",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 3,
+				Template: &lokiv1beta1.LokiTemplateSpec{
+					Ingester: &lokiv1beta1.LokiComponentSpec{Replicas: 3},
+				},
+			},
+		},
+		{
+			name: "larger size",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeTwoXMedium,
+				ReplicationFactor: 3,
+			},
+		},
+		{
+			name: "replication factor with size profile",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				SizeProfile:       "custom",
+				ReplicationFactor: 3,
+				Replication: &lokiv1beta1.ReplicationSpec{
+					Zones: []lokiv1beta1.ZoneSpec{{Value: "a"}, {Value: "b"}, {Value: "c"}},
+				},
+			},
+		},
+		{
+			name: "unordered storage schemas",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Schemas: []lokiv1beta1.ObjectStorageSchema{
+						{Version: lokiv1beta1.ObjectStorageSchemaV12, EffectiveDate: "2022-06-01"},
+						{Version: lokiv1beta1.ObjectStorageSchemaV11, EffectiveDate: "2020-10-01"},
+					},
+				},
+			},
+			wantErrs: []string{"spec.storage.schemas"},
+		},
+		{
+			name: "filesystem storage",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type:       lokiv1beta1.ObjectStorageSecretFilesystem,
+					Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{StorageClassName: "nfs"},
+				},
+			},
+		},
+		{
+			name: "filesystem storage with object storage type",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type:       lokiv1beta1.ObjectStorageSecretS3,
+					Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{StorageClassName: "nfs"},
+				},
+			},
+			wantErrs: []string{"spec.storage.filesystem"},
+		},
+		{
+			name: "prefix with filesystem storage",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type:   lokiv1beta1.ObjectStorageSecretFilesystem,
+					Prefix: "team-a",
+				},
+			},
+			wantErrs: []string{"spec.storage.prefix"},
+		},
+		{
+			name: "s3 storage options",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type:            lokiv1beta1.ObjectStorageSecretS3,
+					TLS:             &lokiv1beta1.ObjectStorageTLSSpec{CA: "storage-ca"},
+					SSE:             &lokiv1beta1.ObjectStorageSSESpec{Type: lokiv1beta1.ObjectStorageSSEKMS, KMSKeyID: "key"},
+					AddressingStyle: lokiv1beta1.ObjectStorageAddressingVirtualHosted,
+					Insecure:        true,
+				},
+			},
+		},
+		{
+			name: "sse-kms without key",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					SSE: &lokiv1beta1.ObjectStorageSSESpec{Type: lokiv1beta1.ObjectStorageSSEKMS},
+				},
+			},
+			wantErrs: []string{"spec.storage.sse.kmsKeyID"},
+		},
+		{
+			name: "sse-s3 with key",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					SSE: &lokiv1beta1.ObjectStorageSSESpec{Type: lokiv1beta1.ObjectStorageSSES3, KMSKeyID: "key"},
+				},
+			},
+			wantErrs: []string{"spec.storage.sse.kmsKeyID"},
+		},
+		{
+			name: "s3 storage options with other type",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type:            lokiv1beta1.ObjectStorageSecretGCS,
+					SSE:             &lokiv1beta1.ObjectStorageSSESpec{Type: lokiv1beta1.ObjectStorageSSES3},
+					AddressingStyle: lokiv1beta1.ObjectStorageAddressingVirtualHosted,
+					Insecure:        true,
+				},
+			},
+			wantErrs: []string{
+				"spec.storage.sse",
+				"spec.storage.addressingStyle",
+				"spec.storage.insecure",
+			},
+		},
+		{
+			name: "tls with filesystem storage",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Storage: lokiv1beta1.ObjectStorageSpec{
+					Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+					TLS:  &lokiv1beta1.ObjectStorageTLSSpec{CA: "storage-ca"},
+				},
+			},
+			wantErrs: []string{"spec.storage.tls"},
+		},
+		{
+			name: "replication zones",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+				Replication: &lokiv1beta1.ReplicationSpec{
+					Zones: []lokiv1beta1.ZoneSpec{{Value: "a"}, {Value: "b"}},
+				},
+			},
+		},
+		{
+			name: "invalid replication zones",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+				Replication: &lokiv1beta1.ReplicationSpec{
+					Zones: []lokiv1beta1.ZoneSpec{{Value: "a"}, {Value: "a"}, {Value: "b"}},
+				},
+			},
+			wantErrs: []string{
+				"spec.replication.zones[1].value",
+				"spec.replication.zones",
+			},
+		},
+		{
+			name: "replication zones not usable in names",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+				Replication: &lokiv1beta1.ReplicationSpec{
+					Zones: []lokiv1beta1.ZoneSpec{
+						{Value: "Zone_A"},
+						{Value: "eu-central-1b-with-a-very-long-zone-value"},
+					},
+				},
+			},
+			wantErrs: []string{
+				"spec.replication.zones[0].value",
+				"spec.replication.zones[1].value",
+			},
+		},
+		{
+			name: "replication factor exceeds replication zones",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXSmall,
+				ReplicationFactor: 2,
+				Replication: &lokiv1beta1.ReplicationSpec{
+					Zones: []lokiv1beta1.ZoneSpec{{Value: "a"}},
+				},
+			},
+			wantErrs: []string{"spec.replicationFactor"},
+		},
+		{
+			name: "invalid autoscaling",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Template: &lokiv1beta1.LokiTemplateSpec{
+					Querier: &lokiv1beta1.LokiComponentSpec{
+						Autoscaling: &lokiv1beta1.AutoscalingSpec{MinReplicas: 3, MaxReplicas: 2},
+					},
+					Ingester: &lokiv1beta1.LokiComponentSpec{
+						Autoscaling: &lokiv1beta1.AutoscalingSpec{MinReplicas: 1, MaxReplicas: 2},
+					},
+				},
+			},
+			wantErrs: []string{
+				"spec.template.ingester.autoscaling",
+				"spec.template.querier.autoscaling.maxReplicas",
+			},
+		},
+		{
+			name: "invalid caching",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Caching: &lokiv1beta1.CachingSpec{
+					Mode:   lokiv1beta1.CachingModeManaged,
+					Chunks: &lokiv1beta1.ExternalCacheSpec{Endpoint: "memcached:11211"},
+				},
+			},
+			wantErrs: []string{"spec.caching.chunks"},
+		},
+		{
+			name: "external caching without caches",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Caching: &lokiv1beta1.CachingSpec{
+					Mode: lokiv1beta1.CachingModeExternal,
+				},
+			},
+			wantErrs: []string{"spec.caching"},
+		},
+		{
+			name: "negative limits",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Limits: &lokiv1beta1.LimitsSpec{
+					Global: &lokiv1beta1.LimitsTemplateSpec{
+						IngestionLimits: &lokiv1beta1.IngestionLimitSpec{
+							IngestionRate: -1,
+						},
+					},
+					Tenants: map[string]lokiv1beta1.LimitsTemplateSpec{
+						"application": {
+							QueryLimits: &lokiv1beta1.QueryLimitSpec{
+								MaxQuerySeries: -10,
+							},
+						},
+					},
+				},
+			},
+			wantErrs: []string{
+				"spec.limits.global.ingestion.ingestionRate",
+				"spec.limits.tenants[application].queries.maxQuerySeries",
+			},
+		},
+		{
+			name: "duplicate tenant names and unknown roles",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Tenants: &lokiv1beta1.TenantsSpec{
+					Mode: lokiv1beta1.Static,
+					Authentication: []lokiv1beta1.AuthenticationSpec{
+						{
+							TenantName: "tenant-a",
+							TenantID:   "1234",
+							OIDC: &lokiv1beta1.OIDCSpec{
+								IssuerURL:   "some-url",
+								RedirectURL: "some-other-url",
+							},
+						},
+						{
+							TenantName: "tenant-a",
+							TenantID:   "5678",
+							OIDC: &lokiv1beta1.OIDCSpec{
+								IssuerURL:   "some-url",
+								RedirectURL: "some-other-url",
+							},
+						},
+					},
+					Authorization: &lokiv1beta1.AuthorizationSpec{
+						Roles: []lokiv1beta1.RoleSpec{
+							{
+								Name:        "some-name",
+								Resources:   []string{"metrics"},
+								Tenants:     []string{"tenant-a"},
+								Permissions: []lokiv1beta1.PermissionType{"read"},
+							},
+						},
+						RoleBindings: []lokiv1beta1.RoleBindingsSpec{
+							{
+								Name: "some-name",
+								Subjects: []lokiv1beta1.Subject{
+									{
+										Name: "sub-1",
+										Kind: "user",
+									},
+								},
+								Roles: []string{"some-name", "missing-role"},
+							},
+						},
+					},
+				},
+			},
+			wantErrs: []string{
+				"spec.tenants.authentication[1].tenantName",
+				"spec.tenants.authorization.roleBindings[0].roles[1]",
+			},
+		},
+		{
+			name: "invalid tenants mode combination",
+			spec: lokiv1beta1.LokiStackSpec{
+				Size:              lokiv1beta1.SizeOneXExtraSmall,
+				ReplicationFactor: 1,
+				Tenants: &lokiv1beta1.TenantsSpec{
+					Mode: lokiv1beta1.Static,
+				},
+			},
+			wantErrs: []string{"spec.tenants.mode"},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			stack := &lokiv1beta1.LokiStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+				Spec: tst.spec,
+			}
+
+			errs := ValidateLokiStack(stack)
+			require.ElementsMatch(t, tst.wantErrs, errorFields(errs))
+		})
+	}
+}
+
+func TestValidateLokiStackUpdate_FilesystemAccessMode(t *testing.T) {
+	type test struct {
+		name     string
+		old      lokiv1beta1.ObjectStorageSpec
+		spec     lokiv1beta1.ObjectStorageSpec
+		wantErrs []string
+	}
+	table := []test{
+		{
+			name: "default access mode unchanged",
+			old:  lokiv1beta1.ObjectStorageSpec{Type: lokiv1beta1.ObjectStorageSecretFilesystem},
+			spec: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{
+					AccessMode: lokiv1beta1.ObjectStorageFilesystemReadWriteMany,
+				},
+			},
+		},
+		{
+			name: "access mode changed",
+			old:  lokiv1beta1.ObjectStorageSpec{Type: lokiv1beta1.ObjectStorageSecretFilesystem},
+			spec: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{
+					AccessMode: lokiv1beta1.ObjectStorageFilesystemReadWriteOnce,
+				},
+			},
+			wantErrs: []string{"spec.storage.filesystem.accessMode"},
+		},
+		{
+			name: "switched to filesystem storage",
+			old:  lokiv1beta1.ObjectStorageSpec{Type: lokiv1beta1.ObjectStorageSecretS3},
+			spec: lokiv1beta1.ObjectStorageSpec{
+				Type: lokiv1beta1.ObjectStorageSecretFilesystem,
+				Filesystem: &lokiv1beta1.ObjectStorageFilesystemSpec{
+					AccessMode: lokiv1beta1.ObjectStorageFilesystemReadWriteOnce,
+				},
+			},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+
+			old := &lokiv1beta1.LokiStack{Spec: lokiv1beta1.LokiStackSpec{Storage: tst.old}}
+			stack := &lokiv1beta1.LokiStack{Spec: lokiv1beta1.LokiStackSpec{Storage: tst.spec}}
+
+			errs := ValidateLokiStackUpdate(old, stack, time.Now())
+			require.ElementsMatch(t, tst.wantErrs, errorFields(errs))
+		})
+	}
+}
+
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// LokiStackWebhookPath is the path the LokiStack validating webhook is served on.
+const LokiStackWebhookPath = "/validate-loki-openshift-io-v1beta1-lokistack"
+
+// +kubebuilder:webhook:path=/validate-loki-openshift-io-v1beta1-lokistack,mutating=false,failurePolicy=fail,sideEffects=None,groups=loki.openshift.io,resources=lokistacks,verbs=create;update,versions=v1beta1,name=vlokistack.loki.openshift.io,admissionReviewVersions={v1,v1beta1}
+
+// LokiStackValidator validates LokiStack custom resources on admission.
+type LokiStackValidator struct {
+	decoder *admission.Decoder
+}
+
+// Handle rejects LokiStack create and update requests with an invalid spec
+// and update requests changing storage schemas already in effect. Updates
+// leaving the spec unchanged, e.g. adding or removing the finalizer, and
+// updates of stacks being deleted are always allowed, so that stacks created
+// before a validation rule existed can still be reconciled and deleted.
+func (v *LokiStackValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	var stack lokiv1beta1.LokiStack
+	if err := v.decoder.Decode(req, &stack); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old lokiv1beta1.LokiStack
+	if req.Operation == admissionv1.Update {
+		if err := v.decoder.DecodeRaw(req.OldObject, &old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if stack.DeletionTimestamp != nil || equality.Semantic.DeepEqual(old.Spec, stack.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	errs := ValidateLokiStack(&stack)
+
+	if req.Operation == admissionv1.Update {
+		errs = append(errs, ValidateLokiStackUpdate(&old, &stack, time.Now())...)
+	}
+
+	if len(errs) == 0 {
+		return admission.Allowed("")
+	}
+
+	gk := schema.GroupKind{
+		Group: lokiv1beta1.GroupVersion.Group,
+		Kind:  "LokiStack",
+	}
+	statusErr := apierrors.NewInvalid(gk, stack.Name, errs)
+
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &statusErr.ErrStatus,
+		},
+	}
+}
+
+// InjectDecoder injects the admission request decoder.
+func (v *LokiStackValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
@@ -0,0 +1,242 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	lokiv1beta1 "github.com/ViaQ/loki-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestLokiStackValidator_Handle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, lokiv1beta1.AddToScheme(scheme))
+
+	decoder, err := admission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	v := &LokiStackValidator{}
+	require.NoError(t, v.InjectDecoder(decoder))
+
+	type test struct {
+		name    string
+		size    lokiv1beta1.LokiStackSizeType
+		allowed bool
+	}
+	table := []test{
+		{
+			name:    "valid stack is allowed",
+			size:    lokiv1beta1.SizeOneXSmall,
+			allowed: true,
+		},
+		{
+			name:    "invalid stack is denied",
+			size:    "3x.huge",
+			allowed: false,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			stack := lokiv1beta1.LokiStack{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: lokiv1beta1.GroupVersion.String(),
+					Kind:       "LokiStack",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-stack",
+					Namespace: "some-ns",
+				},
+				Spec: lokiv1beta1.LokiStackSpec{
+					Size:              tst.size,
+					ReplicationFactor: 1,
+				},
+			}
+
+			raw, err := json.Marshal(stack)
+			require.NoError(t, err)
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			}
+
+			res := v.Handle(context.TODO(), req)
+			require.Equal(t, tst.allowed, res.Allowed)
+			if !tst.allowed {
+				require.Contains(t, res.Result.Message, "spec.size")
+			}
+		})
+	}
+}
+
+func TestLokiStackValidator_Handle_WhenSchemaInEffectChanged_Deny(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, lokiv1beta1.AddToScheme(scheme))
+
+	decoder, err := admission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	v := &LokiStackValidator{}
+	require.NoError(t, v.InjectDecoder(decoder))
+
+	old := lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: lokiv1beta1.GroupVersion.String(),
+			Kind:       "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:              lokiv1beta1.SizeOneXSmall,
+			ReplicationFactor: 1,
+		},
+	}
+
+	type test struct {
+		name    string
+		schemas []lokiv1beta1.ObjectStorageSchema
+		allowed bool
+	}
+	table := []test{
+		{
+			name:    "unchanged default schema is allowed",
+			allowed: true,
+		},
+		{
+			name: "future schema is allowed",
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				{Version: lokiv1beta1.ObjectStorageSchemaV11, EffectiveDate: "2020-10-01"},
+				{Version: lokiv1beta1.ObjectStorageSchemaV13, EffectiveDate: "9999-01-01"},
+			},
+			allowed: true,
+		},
+		{
+			name: "changed schema in effect is denied",
+			schemas: []lokiv1beta1.ObjectStorageSchema{
+				{Version: lokiv1beta1.ObjectStorageSchemaV12, EffectiveDate: "2020-10-01"},
+			},
+			allowed: false,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			stack := *old.DeepCopy()
+			stack.Spec.Storage.Schemas = tst.schemas
+
+			oldRaw, err := json.Marshal(old)
+			require.NoError(t, err)
+
+			raw, err := json.Marshal(stack)
+			require.NoError(t, err)
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			}
+
+			res := v.Handle(context.TODO(), req)
+			require.Equal(t, tst.allowed, res.Allowed)
+			if !tst.allowed {
+				require.Contains(t, res.Result.Message, "spec.storage.schemas")
+			}
+		})
+	}
+}
+
+func TestLokiStackValidator_Handle_WhenSpecUnchangedOrDeleting_Allow(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, lokiv1beta1.AddToScheme(scheme))
+
+	decoder, err := admission.NewDecoder(scheme)
+	require.NoError(t, err)
+
+	v := &LokiStackValidator{}
+	require.NoError(t, v.InjectDecoder(decoder))
+
+	// Stack created before the size validation existed
+	old := lokiv1beta1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: lokiv1beta1.GroupVersion.String(),
+			Kind:       "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "my-stack",
+			Namespace:  "some-ns",
+			Finalizers: []string{"loki.openshift.io/finalizer"},
+		},
+		Spec: lokiv1beta1.LokiStackSpec{
+			Size:              "3x.huge",
+			ReplicationFactor: 1,
+		},
+	}
+
+	now := metav1.Now()
+
+	type test struct {
+		name    string
+		update  func(*lokiv1beta1.LokiStack)
+		allowed bool
+	}
+	table := []test{
+		{
+			name: "finalizer removal is allowed",
+			update: func(s *lokiv1beta1.LokiStack) {
+				s.Finalizers = nil
+			},
+			allowed: true,
+		},
+		{
+			name: "update of deleted stack is allowed",
+			update: func(s *lokiv1beta1.LokiStack) {
+				s.DeletionTimestamp = &now
+				s.Spec.ReplicationFactor = 2
+			},
+			allowed: true,
+		},
+		{
+			name: "spec change is denied",
+			update: func(s *lokiv1beta1.LokiStack) {
+				s.Spec.ReplicationFactor = 2
+			},
+			allowed: false,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			stack := *old.DeepCopy()
+			tst.update(&stack)
+
+			oldRaw, err := json.Marshal(old)
+			require.NoError(t, err)
+
+			raw, err := json.Marshal(stack)
+			require.NoError(t, err)
+
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			}
+
+			res := v.Handle(context.TODO(), req)
+			require.Equal(t, tst.allowed, res.Allowed)
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/ViaQ/loki-operator/controllers"
 	"github.com/ViaQ/loki-operator/internal/manifests"
 	"github.com/ViaQ/loki-operator/internal/metrics"
+	"github.com/ViaQ/loki-operator/internal/validation"
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -40,6 +41,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -62,6 +64,8 @@ func main() {
 		enableTLSServiceMonitors bool
 		enableGateway            bool
 		enableGatewayRoute       bool
+		enableWebhook            bool
+		enableStorageProbe       bool
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -78,6 +82,10 @@ func main() {
 		"Enables the manifest creation for the entire lokistack-gateway.")
 	flag.BoolVar(&enableGatewayRoute, "with-lokistack-gateway-route", false,
 		"Enables the usage of Route for the lokistack-gateway instead of Ingress (OCP Only!)")
+	flag.BoolVar(&enableWebhook, "with-validating-webhook", false,
+		"Enables the validating admission webhook for LokiStack resources.")
+	flag.BoolVar(&enableStorageProbe, "with-object-storage-probe", false,
+		"Enables the periodic connectivity check of the LokiStack object storage buckets.")
 	flag.Parse()
 
 	log.Init("loki-operator")
@@ -114,19 +122,34 @@ func main() {
 		EnableTLSServiceMonitorConfig:   enableTLSServiceMonitors,
 		EnableGateway:                   enableGateway,
 		EnableGatewayRoute:              enableGatewayRoute,
+		EnableObjectStorageProbe:        enableStorageProbe,
 	}
 
 	if err = (&controllers.LokiStackReconciler{
-		Client: mgr.GetClient(),
-		Log:    log.WithName("controllers").WithName("LokiStack"),
-		Scheme: mgr.GetScheme(),
-		Flags:  featureFlags,
+		Client:   mgr.GetClient(),
+		Log:      log.WithName("controllers").WithName("LokiStack"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("loki-operator"),
+		Flags:    featureFlags,
 	}).SetupWithManager(mgr); err != nil {
 		log.Error(err, "unable to create controller", "controller", "LokiStack")
 		os.Exit(1)
 	}
+	if err = (&controllers.LokiStackStatusReconciler{
+		Client: mgr.GetClient(),
+		Log:    log.WithName("controllers").WithName("LokiStackStatus"),
+	}).SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create controller", "controller", "LokiStackStatus")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if enableWebhook {
+		mgr.GetWebhookServer().Register(validation.LokiStackWebhookPath, &webhook.Admission{
+			Handler: &validation.LokiStackValidator{},
+		})
+	}
+
 	if err = mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
 		log.Error(err, "unable to set up health check")
 		os.Exit(1)